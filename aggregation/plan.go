@@ -2,9 +2,16 @@ package aggregation
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
 )
 
 type FetchParameters[Req any] struct {
+	// Context is the context of the execution. Fetchers pass it on to the
+	// calls they make, so that they stop when the execution is cancelled.
+	Context       context.Context
 	Request       Req
 	NextPageToken any
 }
@@ -14,7 +21,6 @@ type FetchResult[P any] struct {
 	NextPageToken any
 }
 
-// TODO: Cancellation + cleanup + drain
 func NewRootPlan[Req any, P any](fetcher func(FetchParameters[Req]) (FetchResult[P], error)) *RootPlan[Req, P] {
 	return &RootPlan[Req, P]{fetcher: fetcher}
 }
@@ -29,13 +35,20 @@ func (p *RootPlan[Req, P]) Execute(ctx context.Context, params Req) <-chan Execu
 	go func() {
 		defer close(ch)
 		for {
-			result, err := p.fetcher(FetchParameters[Req]{Request: params, NextPageToken: npt})
+			if err := ctx.Err(); err != nil {
+				send(ctx, ch, ExecutionResult[P]{Err: err})
+				return
+			}
+
+			result, err := p.fetcher(FetchParameters[Req]{Context: ctx, Request: params, NextPageToken: npt})
 			if err != nil {
-				ch <- ExecutionResult[P]{Err: err}
+				send(ctx, ch, ExecutionResult[P]{Err: err})
 				return
 			}
 
-			ch <- ExecutionResult[P]{Items: result.Items}
+			if !send(ctx, ch, ExecutionResult[P]{Items: result.Items}) {
+				return
+			}
 
 			if result.NextPageToken == nil {
 				return
@@ -46,10 +59,36 @@ func (p *RootPlan[Req, P]) Execute(ctx context.Context, params Req) <-chan Execu
 	return ch
 }
 
+// send sends v on ch unless ctx is done first, in which case the consumer
+// may have stopped reading. It reports whether v was sent.
+func send[T any](ctx context.Context, ch chan<- T, v T) bool {
+	select {
+	case ch <- v:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
 type SubFetcher[Parent any] interface {
-	Fetch(Parent) (any, error)
+	Fetch(context.Context, Parent) (any, error)
 }
 
+// BatchSubFetcher is implemented by SubFetchers that can fetch for a whole
+// page of parents at once, e.g. with one provider call for the keys of every
+// parent. SubPlan prefers FetchBatch over calling Fetch for each parent.
+type BatchSubFetcher[Parent any] interface {
+	SubFetcher[Parent]
+
+	// FetchBatch returns the fetch result of each parent, by index. It may
+	// return ErrBatchUnsupported to have the parents fetched one by one.
+	FetchBatch(context.Context, []Parent) ([]any, error)
+}
+
+// ErrBatchUnsupported is returned by BatchSubFetcher.FetchBatch when the
+// parents must be fetched one by one instead.
+var ErrBatchUnsupported = errors.New("batch fetch not supported")
+
 func NewSubPlan[Req any, Parent any, Result any](
 	root Executer[Req, Parent],
 	fetcher SubFetcher[Parent],
@@ -62,6 +101,18 @@ type SubPlan[Req any, Parent any, Result any] struct {
 	Parent  Executer[Req, Parent]
 	Fetcher SubFetcher[Parent]
 	Builder func(Parent, any) Result
+
+	// Concurrency is the number of fetches running at once, shared by the
+	// fetchers of a ParallelFetcher. Values below 2 fetch one at a time. Not
+	// used by fetchers that fetch the page in one batch.
+	Concurrency int
+}
+
+// WithConcurrency sets the number of fetches running at once and returns the
+// plan.
+func (p *SubPlan[Req, P, R]) WithConcurrency(n int) *SubPlan[Req, P, R] {
+	p.Concurrency = n
+	return p
 }
 
 func (p *SubPlan[Req, P, R]) Execute(ctx context.Context, rootParams Req) <-chan ExecutionResult[R] {
@@ -69,36 +120,200 @@ func (p *SubPlan[Req, P, R]) Execute(ctx context.Context, rootParams Req) <-chan
 	go func() {
 		defer close(ch)
 
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
 		parentCh := p.Parent.Execute(ctx, rootParams)
 
+		// When returning early, stop the parent and wait for it to finish so
+		// that no goroutine is left behind.
+		defer func() {
+			cancel()
+			for range parentCh {
+			}
+		}()
+
 		for parentItems := range parentCh {
 			if parentItems.Err != nil {
-				ch <- ExecutionResult[R]{Err: parentItems.Err}
+				send(ctx, ch, ExecutionResult[R]{Err: parentItems.Err})
 				return
 			}
 
+			fetchResults, err := fetchPage(ctx, p.Fetcher, parentItems.Items, newSemaphore(p.Concurrency))
+			if err != nil {
+				send(ctx, ch, ExecutionResult[R]{Err: err})
+				return
+			}
+
+			// Built in order after all fetches, so that builders need not be
+			// safe for concurrent use.
 			rowResult := make([]R, len(parentItems.Items))
 			for i, parentItem := range parentItems.Items {
-				fetchResult, err := p.Fetcher.Fetch(parentItem)
-				if err != nil {
-					ch <- ExecutionResult[R]{Err: err}
-					return
-				}
-
-				rowResult[i] = p.Builder(parentItem, fetchResult)
+				rowResult[i] = p.Builder(parentItem, fetchResults[i])
 			}
 
-			ch <- ExecutionResult[R]{Items: rowResult}
+			if !send(ctx, ch, ExecutionResult[R]{Items: rowResult}) {
+				return
+			}
 		}
 	}()
 	return ch
 }
 
+// ParallelFetcher runs several fetchers on the same parent at once, e.g. for
+// relations that do not depend on each other. The result is a []any holding
+// the result of each fetcher, in order.
+type ParallelFetcher[Parent any] []SubFetcher[Parent]
+
+func (f ParallelFetcher[Parent]) Fetch(ctx context.Context, parent Parent) (any, error) {
+	results, err := fetchConcurrently(ctx, newSemaphore(len(f)), len(f), func(i int) (any, error) {
+		return f[i].Fetch(ctx, parent)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// fetchPage fetches for a page of parents at once, so that each of the
+// fetchers can batch its page. The fetchers share sem, so that no more
+// fetches run at once than for a single fetcher.
+func (f ParallelFetcher[Parent]) fetchPage(ctx context.Context, parents []Parent, sem semaphore) ([]any, error) {
+	byFetcher, err := fetchConcurrently(ctx, newSemaphore(len(f)), len(f), func(i int) (any, error) {
+		return fetchPage(ctx, f[i], parents, sem)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]any, len(parents))
+	for i := range parents {
+		row := make([]any, len(f))
+		for j := range f {
+			row[j] = byFetcher[j].([]any)[i]
+		}
+		results[i] = row
+	}
+	return results, nil
+}
+
+// pageFetcher is implemented by fetchers that fetch a page in their own way.
+type pageFetcher[Parent any] interface {
+	fetchPage(ctx context.Context, parents []Parent, sem semaphore) ([]any, error)
+}
+
+// fetchPage returns the fetch result of each parent. The page is fetched in
+// one batch if the fetcher supports it, otherwise parent by parent with a
+// slot of sem held by each fetch.
+func fetchPage[Parent any](ctx context.Context, fetcher SubFetcher[Parent], parents []Parent, sem semaphore) ([]any, error) {
+	switch f := fetcher.(type) {
+	case pageFetcher[Parent]:
+		return f.fetchPage(ctx, parents, sem)
+	case BatchSubFetcher[Parent]:
+		if len(parents) == 0 {
+			return nil, nil
+		}
+		results, err := f.FetchBatch(ctx, parents)
+		if err == nil && len(results) != len(parents) {
+			return nil, fmt.Errorf("batch fetch returned %d results for %d parents", len(results), len(parents))
+		}
+		if !errors.Is(err, ErrBatchUnsupported) {
+			return results, err
+		}
+	}
+
+	return fetchConcurrently(ctx, sem, len(parents), func(i int) (any, error) {
+		return fetcher.Fetch(ctx, parents[i])
+	})
+}
+
+// semaphore limits the number of fetches running at once to its capacity.
+type semaphore chan struct{}
+
+func newSemaphore(limit int) semaphore {
+	return make(semaphore, max(limit, 1))
+}
+
+// acquire takes a slot, waiting for one to be released if needed. It returns
+// ctx's error if ctx is done first.
+func (s semaphore) acquire(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	select {
+	case s <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s semaphore) release() {
+	<-s
+}
+
+// fetchConcurrently calls fetch for 0 to n-1, each holding a slot of sem, and
+// returns the results by index. No more calls are started after one fails or
+// ctx is done, and the error of the lowest failed index is returned.
+func fetchConcurrently(ctx context.Context, sem semaphore, n int, fetch func(i int) (any, error)) ([]any, error) {
+	results := make([]any, n)
+	if cap(sem) < 2 || n < 2 {
+		for i := range n {
+			if err := sem.acquire(ctx); err != nil {
+				return nil, err
+			}
+			r, err := fetch(i)
+			sem.release()
+			if err != nil {
+				return nil, err
+			}
+			results[i] = r
+		}
+		return results, nil
+	}
+
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	var failed atomic.Bool
+	var cancelled error
+	for i := range n {
+		if cancelled = sem.acquire(ctx); cancelled != nil {
+			break
+		}
+		if failed.Load() {
+			sem.release()
+			break
+		}
+		wg.Go(func() {
+			defer sem.release()
+			results[i], errs[i] = fetch(i)
+			if errs[i] != nil {
+				failed.Store(true)
+			}
+		})
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	if cancelled != nil {
+		return nil, cancelled
+	}
+	return results, nil
+}
+
 type ExecutionResult[P any] struct {
 	Items []P
 	Err   error
 }
 
 type Executer[Req, P any] interface {
+	// Execute runs the plan and sends its results page by page. The channel
+	// is closed when the plan is done, after an error, or when ctx is done,
+	// possibly without sending ctx's error. A consumer that stops reading
+	// early must cancel ctx to release the plan.
 	Execute(ctx context.Context, params Req) <-chan ExecutionResult[P]
 }
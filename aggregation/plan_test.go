@@ -2,7 +2,11 @@ package aggregation
 
 import (
 	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -11,7 +15,7 @@ type MockFetcher[Parent any, Result any] struct {
 	FetchFunc func(Parent) (any, error)
 }
 
-func (m *MockFetcher[Parent, Result]) Fetch(parent Parent) (any, error) {
+func (m *MockFetcher[Parent, Result]) Fetch(_ context.Context, parent Parent) (any, error) {
 	return m.FetchFunc(parent)
 }
 
@@ -307,3 +311,397 @@ func Test_SubPlan_WithMapResult(t *testing.T) {
 	require.Equal(t, "b", results[0].Items[1]["parent"])
 	require.Equal(t, "b_sub", results[0].Items[1]["sub"])
 }
+
+func Test_SubPlan_Concurrency(t *testing.T) {
+	items := make([]int, 50)
+	for i := range items {
+		items[i] = i
+	}
+	rootFetcher := func(params FetchParameters[string]) (FetchResult[int], error) {
+		return FetchResult[int]{Items: items}, nil
+	}
+
+	var running, maxRunning atomic.Int32
+	subFetcher := &MockFetcher[int, int]{
+		FetchFunc: func(parent int) (any, error) {
+			n := running.Add(1)
+			defer running.Add(-1)
+			for {
+				m := maxRunning.Load()
+				if n <= m || maxRunning.CompareAndSwap(m, n) {
+					break
+				}
+			}
+			// Later items finish first.
+			time.Sleep(time.Duration(50-parent) * 100 * time.Microsecond)
+			return parent * 10, nil
+		},
+	}
+
+	builder := func(parent int, fetchResult any) int {
+		return parent + fetchResult.(int)
+	}
+
+	subPlan := NewSubPlan(NewRootPlan(rootFetcher), subFetcher, builder).WithConcurrency(4)
+
+	var results []ExecutionResult[int]
+	for res := range subPlan.Execute(context.Background(), "request") {
+		results = append(results, res)
+	}
+
+	require.Equal(t, 1, len(results))
+	require.NoError(t, results[0].Err)
+	for i, v := range results[0].Items {
+		require.Equal(t, i*11, v)
+	}
+	require.LessOrEqual(t, maxRunning.Load(), int32(4))
+	require.Greater(t, maxRunning.Load(), int32(1))
+}
+
+func Test_SubPlan_Concurrency_WithError(t *testing.T) {
+	rootFetcher := func(params FetchParameters[string]) (FetchResult[string], error) {
+		return FetchResult[string]{Items: []string{"a", "b", "c", "d"}}, nil
+	}
+
+	errB := errors.New("b failed")
+	subFetcher := &MockFetcher[string, string]{
+		FetchFunc: func(parent string) (any, error) {
+			switch parent {
+			case "b":
+				return nil, errB
+			case "d":
+				return nil, context.Canceled
+			}
+			return parent + "_sub", nil
+		},
+	}
+
+	builder := func(parent string, fetchResult any) string {
+		return parent + "_" + fetchResult.(string)
+	}
+
+	subPlan := NewSubPlan(NewRootPlan(rootFetcher), subFetcher, builder).WithConcurrency(4)
+
+	var results []ExecutionResult[string]
+	for res := range subPlan.Execute(context.Background(), "request") {
+		results = append(results, res)
+	}
+
+	// The error of the first failed item is returned, regardless of which
+	// failed first.
+	require.Equal(t, 1, len(results))
+	require.ErrorIs(t, results[0].Err, errB)
+}
+
+func Test_ParallelFetcher(t *testing.T) {
+	release := make(chan struct{})
+	var started sync.WaitGroup
+	started.Add(2)
+	fetcher := ParallelFetcher[string]{
+		&MockFetcher[string, string]{FetchFunc: func(parent string) (any, error) {
+			started.Done()
+			<-release
+			return parent + "_1", nil
+		}},
+		&MockFetcher[string, string]{FetchFunc: func(parent string) (any, error) {
+			started.Done()
+			<-release
+			return parent + "_2", nil
+		}},
+	}
+
+	// Both fetchers must be running before either can finish.
+	go func() {
+		started.Wait()
+		close(release)
+	}()
+
+	result, err := fetcher.Fetch(context.Background(), "a")
+	require.NoError(t, err)
+	require.Equal(t, []any{"a_1", "a_2"}, result)
+}
+
+type MockBatchFetcher[Parent any] struct {
+	MockFetcher[Parent, any]
+	FetchBatchFunc func([]Parent) ([]any, error)
+}
+
+func (m *MockBatchFetcher[Parent]) FetchBatch(_ context.Context, parents []Parent) ([]any, error) {
+	return m.FetchBatchFunc(parents)
+}
+
+func Test_SubPlan_BatchFetcher(t *testing.T) {
+	rootFetcher := func(params FetchParameters[string]) (FetchResult[string], error) {
+		if params.NextPageToken == nil {
+			return FetchResult[string]{Items: []string{"a", "b"}, NextPageToken: "token"}, nil
+		}
+		return FetchResult[string]{Items: []string{"c"}, NextPageToken: nil}, nil
+	}
+
+	var batches [][]string
+	subFetcher := &MockBatchFetcher[string]{
+		MockFetcher: MockFetcher[string, any]{FetchFunc: func(parent string) (any, error) {
+			t.Fatalf("unexpected Fetch of %s", parent)
+			return nil, nil
+		}},
+		FetchBatchFunc: func(parents []string) ([]any, error) {
+			batches = append(batches, parents)
+			results := make([]any, len(parents))
+			for i, p := range parents {
+				results[i] = p + "_batch"
+			}
+			return results, nil
+		},
+	}
+
+	builder := func(parent string, fetchResult any) string {
+		return parent + "_" + fetchResult.(string)
+	}
+
+	subPlan := NewSubPlan(NewRootPlan(rootFetcher), subFetcher, builder)
+
+	var results []ExecutionResult[string]
+	for res := range subPlan.Execute(context.Background(), "request") {
+		results = append(results, res)
+	}
+
+	require.Equal(t, [][]string{{"a", "b"}, {"c"}}, batches)
+	require.Equal(t, 2, len(results))
+	require.Equal(t, []string{"a_a_batch", "b_b_batch"}, results[0].Items)
+	require.Equal(t, []string{"c_c_batch"}, results[1].Items)
+}
+
+func Test_SubPlan_BatchFetcher_Unsupported(t *testing.T) {
+	rootFetcher := func(params FetchParameters[string]) (FetchResult[string], error) {
+		return FetchResult[string]{Items: []string{"a", "b"}}, nil
+	}
+
+	subFetcher := &MockBatchFetcher[string]{
+		MockFetcher: MockFetcher[string, any]{FetchFunc: func(parent string) (any, error) {
+			return parent + "_sub", nil
+		}},
+		FetchBatchFunc: func(parents []string) ([]any, error) {
+			return nil, ErrBatchUnsupported
+		},
+	}
+
+	builder := func(parent string, fetchResult any) string {
+		return parent + "_" + fetchResult.(string)
+	}
+
+	subPlan := NewSubPlan(NewRootPlan(rootFetcher), subFetcher, builder)
+
+	var results []ExecutionResult[string]
+	for res := range subPlan.Execute(context.Background(), "request") {
+		results = append(results, res)
+	}
+
+	require.Equal(t, 1, len(results))
+	require.NoError(t, results[0].Err)
+	require.Equal(t, []string{"a_a_sub", "b_b_sub"}, results[0].Items)
+}
+
+func Test_SubPlan_ParallelFetcher_Batch(t *testing.T) {
+	rootFetcher := func(params FetchParameters[string]) (FetchResult[string], error) {
+		return FetchResult[string]{Items: []string{"a", "b"}}, nil
+	}
+
+	var batchCalls atomic.Int32
+	fetcher := ParallelFetcher[string]{
+		&MockBatchFetcher[string]{
+			FetchBatchFunc: func(parents []string) ([]any, error) {
+				batchCalls.Add(1)
+				results := make([]any, len(parents))
+				for i, p := range parents {
+					results[i] = p + "_batch"
+				}
+				return results, nil
+			},
+		},
+		&MockFetcher[string, string]{FetchFunc: func(parent string) (any, error) {
+			return parent + "_single", nil
+		}},
+	}
+
+	builder := func(parent string, fetchResult any) []any {
+		return fetchResult.([]any)
+	}
+
+	subPlan := NewSubPlan(NewRootPlan(rootFetcher), fetcher, builder).WithConcurrency(2)
+
+	var results []ExecutionResult[[]any]
+	for res := range subPlan.Execute(context.Background(), "request") {
+		results = append(results, res)
+	}
+
+	require.Equal(t, 1, len(results))
+	require.NoError(t, results[0].Err)
+	require.Equal(t, [][]any{{"a_batch", "a_single"}, {"b_batch", "b_single"}}, results[0].Items)
+	require.Equal(t, int32(1), batchCalls.Load())
+}
+
+func Test_SubPlan_ParallelFetcher_Concurrency(t *testing.T) {
+	items := make([]int, 20)
+	for i := range items {
+		items[i] = i
+	}
+	rootFetcher := func(params FetchParameters[string]) (FetchResult[int], error) {
+		return FetchResult[int]{Items: items}, nil
+	}
+
+	// The fetchers share the limit of the plan.
+	var running, maxRunning atomic.Int32
+	fetch := func(parent int) (any, error) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			m := maxRunning.Load()
+			if n <= m || maxRunning.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		time.Sleep(200 * time.Microsecond)
+		return parent, nil
+	}
+	fetcher := ParallelFetcher[int]{
+		&MockFetcher[int, int]{FetchFunc: fetch},
+		&MockFetcher[int, int]{FetchFunc: fetch},
+		&MockFetcher[int, int]{FetchFunc: fetch},
+	}
+
+	builder := func(parent int, fetchResult any) []any {
+		return fetchResult.([]any)
+	}
+
+	subPlan := NewSubPlan(NewRootPlan(rootFetcher), fetcher, builder).WithConcurrency(3)
+
+	var results []ExecutionResult[[]any]
+	for res := range subPlan.Execute(context.Background(), "request") {
+		results = append(results, res)
+	}
+
+	require.Equal(t, 1, len(results))
+	require.NoError(t, results[0].Err)
+	for i, row := range results[0].Items {
+		require.Equal(t, []any{i, i, i}, row)
+	}
+	require.LessOrEqual(t, maxRunning.Load(), int32(3))
+	require.Greater(t, maxRunning.Load(), int32(1))
+}
+
+func Test_SubPlan_AbandonedExecution(t *testing.T) {
+	var pages atomic.Int32
+	rootDone := make(chan struct{})
+	rootFetcher := func(params FetchParameters[string]) (FetchResult[string], error) {
+		pages.Add(1)
+		// Endless pages.
+		return FetchResult[string]{Items: []string{"a"}, NextPageToken: "token"}, nil
+	}
+
+	subFetcher := &MockFetcher[string, string]{
+		FetchFunc: func(parent string) (any, error) {
+			return parent + "_sub", nil
+		},
+	}
+
+	builder := func(parent string, fetchResult any) string {
+		return parent + "_" + fetchResult.(string)
+	}
+
+	rootPlan := NewRootPlan(rootFetcher)
+	subPlan := NewSubPlan(&closeNotifier[string, string]{Executer: rootPlan, done: rootDone}, subFetcher, builder)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := subPlan.Execute(ctx, "request")
+	<-ch
+	cancel()
+
+	// Both plans stop without the consumer reading any further.
+	select {
+	case <-rootDone:
+	case <-time.After(time.Second):
+		t.Fatal("root plan did not stop")
+	}
+	n := pages.Load()
+	for range ch {
+	}
+	require.Equal(t, n, pages.Load())
+}
+
+func Test_SubPlan_CancelledFetch(t *testing.T) {
+	rootFetcher := func(params FetchParameters[string]) (FetchResult[int], error) {
+		return FetchResult[int]{Items: []int{0, 1, 2, 3, 4, 5, 6, 7}}, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var fetched atomic.Int32
+	subFetcher := &ctxFetcher[int]{
+		fetch: func(ctx context.Context, parent int) (any, error) {
+			fetched.Add(1)
+			if parent == 1 {
+				cancel()
+			}
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+	}
+
+	builder := func(parent int, fetchResult any) int { return parent }
+
+	subPlan := NewSubPlan(NewRootPlan(rootFetcher), subFetcher, builder).WithConcurrency(2)
+
+	var results []ExecutionResult[int]
+	for res := range subPlan.Execute(ctx, "request") {
+		results = append(results, res)
+	}
+
+	// The consumer may or may not receive the error, but no more fetches are
+	// started once cancelled.
+	for _, res := range results {
+		require.ErrorIs(t, res.Err, context.Canceled)
+	}
+	require.LessOrEqual(t, fetched.Load(), int32(2))
+}
+
+func Test_RootPlan_PassesContext(t *testing.T) {
+	type key struct{}
+	ctx := context.WithValue(context.Background(), key{}, "value")
+
+	var got any
+	rootPlan := NewRootPlan(func(params FetchParameters[string]) (FetchResult[string], error) {
+		got = params.Context.Value(key{})
+		return FetchResult[string]{}, nil
+	})
+	for range rootPlan.Execute(ctx, "request") {
+	}
+
+	require.Equal(t, "value", got)
+}
+
+type ctxFetcher[Parent any] struct {
+	fetch func(context.Context, Parent) (any, error)
+}
+
+func (f *ctxFetcher[Parent]) Fetch(ctx context.Context, parent Parent) (any, error) {
+	return f.fetch(ctx, parent)
+}
+
+// closeNotifier closes done when the channel of the wrapped Executer is
+// closed.
+type closeNotifier[Req, P any] struct {
+	Executer[Req, P]
+	done chan struct{}
+}
+
+func (c *closeNotifier[Req, P]) Execute(ctx context.Context, params Req) <-chan ExecutionResult[P] {
+	in := c.Executer.Execute(ctx, params)
+	out := make(chan ExecutionResult[P])
+	go func() {
+		defer close(c.done)
+		defer close(out)
+		for r := range in {
+			out <- r
+		}
+	}()
+	return out
+}
@@ -98,6 +98,8 @@ func applyMapping(addr, indexName string, mapping map[string]any, user, pass str
 	}
 
 	// 400 with resource_already_exists_exception — index exists, update the mapping instead.
+	// Analysis settings cannot be changed on an open index, so they are only
+	// applied when the index is created.
 	if statusCode == http.StatusBadRequest && strings.Contains(string(respBody), "resource_already_exists_exception") {
 		mappingBody, err := json.Marshal(mapping["mappings"])
 		if err != nil {
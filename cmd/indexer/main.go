@@ -93,7 +93,7 @@ func main() {
 	}
 	defer sourceProvider.Close()
 
-	plans := dsl.BuildPlansFromConfig(sourceProvider, resources)
+	plans := dsl.BuildPlansFromConfig(sourceProvider, st, resources)
 
 	idx := core.New(core.Config{
 		Plans:     plans,
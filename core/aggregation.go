@@ -0,0 +1,114 @@
+package core
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+
+	"github.com/theleeeo/indexer/gen/search/v1"
+	"github.com/theleeeo/indexer/resource"
+)
+
+// resolveAggregations validates the requested aggregations against the field
+// capabilities of the given version and fills in their nested paths.
+func resolveAggregations(vc *resource.VersionConfig, aggs []*search.Aggregation) error {
+	if len(aggs) == 0 {
+		return nil
+	}
+
+	caps := make(map[string]*search.FieldCapability)
+	for _, fc := range versionCapabilities(vc) {
+		caps[fc.Field] = fc
+	}
+
+	names := make(map[string]bool, len(aggs))
+	for _, a := range aggs {
+		if a == nil {
+			continue
+		}
+
+		if a.Name == "" {
+			return &InvalidArgumentError{Msg: "aggregation name is required"}
+		}
+		if names[a.Name] {
+			return &InvalidArgumentError{Msg: fmt.Sprintf("aggregation %q is defined more than once", a.Name)}
+		}
+		names[a.Name] = true
+
+		field, kind := aggregationTarget(a)
+		if kind == search.AggregationKind_AGGREGATION_KIND_UNSPECIFIED {
+			return &InvalidArgumentError{Msg: fmt.Sprintf("aggregation %q requires a kind", a.Name)}
+		}
+
+		fc, ok := caps[field]
+		if !ok {
+			return &InvalidArgumentError{Msg: fmt.Sprintf("aggregation %q: unknown field %q", a.Name, field)}
+		}
+		if !slices.Contains(fc.Aggregations, kind) {
+			return &InvalidArgumentError{Msg: fmt.Sprintf("aggregation %q: %s is not supported on %s field %q", a.Name, kind, fc.Type, field)}
+		}
+
+		if err := validateAggregationOptions(a); err != nil {
+			return err
+		}
+
+		if a.NestedPath == "" {
+			a.NestedPath = vc.NestedPath(field)
+		} else if !vc.IsNestedPath(a.NestedPath) {
+			return &InvalidArgumentError{Msg: fmt.Sprintf("aggregation %q: %q is not a nested path", a.Name, a.NestedPath)}
+		}
+	}
+
+	return nil
+}
+
+// calendarIntervals are the intervals ES accepts as a calendar_interval.
+var calendarIntervals = []string{
+	"minute", "1m", "hour", "1h", "day", "1d", "week", "1w",
+	"month", "1M", "quarter", "1q", "year", "1y",
+}
+
+// fixedIntervalPattern matches the intervals ES accepts as a fixed_interval,
+// e.g. "30s" or "12h".
+var fixedIntervalPattern = regexp.MustCompile(`^[1-9][0-9]*(ms|s|m|h|d)$`)
+
+// validateAggregationOptions checks the options of an aggregation's kind.
+func validateAggregationOptions(a *search.Aggregation) error {
+	switch k := a.Kind.(type) {
+	case *search.Aggregation_Range:
+		if len(k.Range.Ranges) == 0 {
+			return &InvalidArgumentError{Msg: fmt.Sprintf("aggregation %q: range requires at least one range", a.Name)}
+		}
+	case *search.Aggregation_DateHistogram:
+		dh := k.DateHistogram
+		switch {
+		case (dh.CalendarInterval == "") == (dh.FixedInterval == ""):
+			return &InvalidArgumentError{Msg: fmt.Sprintf("aggregation %q: date histogram requires exactly one of calendar_interval and fixed_interval", a.Name)}
+		case dh.CalendarInterval != "" && !slices.Contains(calendarIntervals, dh.CalendarInterval):
+			return &InvalidArgumentError{Msg: fmt.Sprintf("aggregation %q: invalid calendar_interval %q", a.Name, dh.CalendarInterval)}
+		case dh.FixedInterval != "" && !fixedIntervalPattern.MatchString(dh.FixedInterval):
+			return &InvalidArgumentError{Msg: fmt.Sprintf("aggregation %q: invalid fixed_interval %q", a.Name, dh.FixedInterval)}
+		}
+	case *search.Aggregation_Metric:
+		if k.Metric.Type == search.MetricType_METRIC_TYPE_UNSPECIFIED {
+			return &InvalidArgumentError{Msg: fmt.Sprintf("aggregation %q: metric requires a metric type", a.Name)}
+		}
+	}
+	return nil
+}
+
+// aggregationTarget returns the field and kind of an aggregation request.
+func aggregationTarget(a *search.Aggregation) (string, search.AggregationKind) {
+	switch k := a.Kind.(type) {
+	case *search.Aggregation_Terms:
+		return k.Terms.GetField(), search.AggregationKind_AGGREGATION_KIND_TERMS
+	case *search.Aggregation_Range:
+		return k.Range.GetField(), search.AggregationKind_AGGREGATION_KIND_RANGE
+	case *search.Aggregation_DateHistogram:
+		return k.DateHistogram.GetField(), search.AggregationKind_AGGREGATION_KIND_DATE_HISTOGRAM
+	case *search.Aggregation_Metric:
+		return k.Metric.GetField(), search.AggregationKind_AGGREGATION_KIND_METRIC
+	default:
+		return "", search.AggregationKind_AGGREGATION_KIND_UNSPECIFIED
+	}
+}
@@ -0,0 +1,138 @@
+package core
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/theleeeo/indexer/gen/search/v1"
+	"github.com/theleeeo/indexer/resource"
+)
+
+func aggregationTestVersion() *resource.VersionConfig {
+	return &resource.VersionConfig{
+		Fields: []resource.FieldConfig{
+			{Name: "status"},
+			{Name: "title", Type: "text"},
+			{Name: "price", Type: "double"},
+			{Name: "created_at", Type: "date"},
+		},
+		Relations: []resource.RelationConfig{
+			{Resource: "c", Fields: []resource.FieldConfig{{Name: "state"}}},
+		},
+	}
+}
+
+func TestResolveAggregations(t *testing.T) {
+	aggs := []*search.Aggregation{
+		{Name: "status", Kind: &search.Aggregation_Terms{Terms: &search.TermsAggregation{Field: "fields.status"}}},
+		{Name: "price", Kind: &search.Aggregation_Range{Range: &search.RangeAggregation{Field: "fields.price", Ranges: []*search.AggregationRange{{Key: "cheap"}}}}},
+		{Name: "created", Kind: &search.Aggregation_DateHistogram{DateHistogram: &search.DateHistogramAggregation{Field: "fields.created_at", CalendarInterval: "month"}}},
+		{Name: "max_price", Kind: &search.Aggregation_Metric{Metric: &search.MetricAggregation{Field: "fields.price", Type: search.MetricType_METRIC_TYPE_MAX}}},
+		{Name: "c_state", Kind: &search.Aggregation_Terms{Terms: &search.TermsAggregation{Field: "c.state"}}},
+	}
+
+	if err := resolveAggregations(aggregationTestVersion(), aggs); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if aggs[0].NestedPath != "" {
+		t.Errorf("root field aggregation should not be nested, got %q", aggs[0].NestedPath)
+	}
+	if aggs[4].NestedPath != "c" {
+		t.Errorf("many-relation aggregation: nested path got %q, want %q", aggs[4].NestedPath, "c")
+	}
+}
+
+func TestResolveAggregations_Invalid(t *testing.T) {
+	terms := func(name, field string) *search.Aggregation {
+		return &search.Aggregation{Name: name, Kind: &search.Aggregation_Terms{Terms: &search.TermsAggregation{Field: field}}}
+	}
+
+	tests := []struct {
+		name    string
+		aggs    []*search.Aggregation
+		wantMsg string
+	}{
+		{
+			name:    "missing name",
+			aggs:    []*search.Aggregation{terms("", "fields.status")},
+			wantMsg: "aggregation name is required",
+		},
+		{
+			name:    "duplicate name",
+			aggs:    []*search.Aggregation{terms("a", "fields.status"), terms("a", "fields.price")},
+			wantMsg: `aggregation "a" is defined more than once`,
+		},
+		{
+			name:    "missing kind",
+			aggs:    []*search.Aggregation{{Name: "a"}},
+			wantMsg: `aggregation "a" requires a kind`,
+		},
+		{
+			name:    "unknown field",
+			aggs:    []*search.Aggregation{terms("a", "fields.missing")},
+			wantMsg: `aggregation "a": unknown field "fields.missing"`,
+		},
+		{
+			name:    "terms on text",
+			aggs:    []*search.Aggregation{terms("a", "fields.title")},
+			wantMsg: `aggregation "a": AGGREGATION_KIND_TERMS is not supported on text field "fields.title"`,
+		},
+		{
+			name: "date histogram on keyword",
+			aggs: []*search.Aggregation{{Name: "a", Kind: &search.Aggregation_DateHistogram{
+				DateHistogram: &search.DateHistogramAggregation{Field: "fields.status", CalendarInterval: "day"},
+			}}},
+			wantMsg: `aggregation "a": AGGREGATION_KIND_DATE_HISTOGRAM is not supported on keyword field "fields.status"`,
+		},
+		{
+			name:    "range without ranges",
+			aggs:    []*search.Aggregation{{Name: "a", Kind: &search.Aggregation_Range{Range: &search.RangeAggregation{Field: "fields.price"}}}},
+			wantMsg: `aggregation "a": range requires at least one range`,
+		},
+		{
+			name: "date histogram with both intervals",
+			aggs: []*search.Aggregation{{Name: "a", Kind: &search.Aggregation_DateHistogram{
+				DateHistogram: &search.DateHistogramAggregation{Field: "fields.created_at", CalendarInterval: "day", FixedInterval: "1h"},
+			}}},
+			wantMsg: `aggregation "a": date histogram requires exactly one of calendar_interval and fixed_interval`,
+		},
+		{
+			name: "invalid calendar interval",
+			aggs: []*search.Aggregation{{Name: "a", Kind: &search.Aggregation_DateHistogram{
+				DateHistogram: &search.DateHistogramAggregation{Field: "fields.created_at", CalendarInterval: "2d"},
+			}}},
+			wantMsg: `aggregation "a": invalid calendar_interval "2d"`,
+		},
+		{
+			name: "invalid fixed interval",
+			aggs: []*search.Aggregation{{Name: "a", Kind: &search.Aggregation_DateHistogram{
+				DateHistogram: &search.DateHistogramAggregation{Field: "fields.created_at", FixedInterval: "1month"},
+			}}},
+			wantMsg: `aggregation "a": invalid fixed_interval "1month"`,
+		},
+		{
+			name:    "metric without type",
+			aggs:    []*search.Aggregation{{Name: "a", Kind: &search.Aggregation_Metric{Metric: &search.MetricAggregation{Field: "fields.price"}}}},
+			wantMsg: `aggregation "a": metric requires a metric type`,
+		},
+		{
+			name:    "unknown nested path",
+			aggs:    []*search.Aggregation{{Name: "a", NestedPath: "fields", Kind: &search.Aggregation_Terms{Terms: &search.TermsAggregation{Field: "c.state"}}}},
+			wantMsg: `aggregation "a": "fields" is not a nested path`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := resolveAggregations(aggregationTestVersion(), tt.aggs)
+			var invalidArg *InvalidArgumentError
+			if !errors.As(err, &invalidArg) {
+				t.Fatalf("expected InvalidArgumentError, got %v", err)
+			}
+			if invalidArg.Msg != tt.wantMsg {
+				t.Fatalf("unexpected message: %q", invalidArg.Msg)
+			}
+		})
+	}
+}
@@ -4,10 +4,12 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"slices"
 
 	"github.com/theleeeo/indexer/es"
 	"github.com/theleeeo/indexer/model"
 	"github.com/theleeeo/indexer/projection"
+	"github.com/theleeeo/indexer/resource"
 )
 
 func (idx *Indexer) Build(ctx context.Context, params BuildArgs) error {
@@ -26,75 +28,219 @@ func (idx *Indexer) Build(ctx context.Context, params BuildArgs) error {
 	return idx.buildByIDs(ctx, logger, plans, params)
 }
 
+// buildChunkSize is the number of resources of a Build job that are fetched
+// and indexed together.
+const buildChunkSize = 100
+
 func (idx *Indexer) buildByIDs(ctx context.Context, logger *slog.Logger, plans []projection.Plan, params BuildArgs) error {
+	// A resource listed twice is only built once.
+	seen := make(map[string]bool, len(params.ResourceIds))
+	ids := slices.DeleteFunc(slices.Clone(params.ResourceIds), func(id string) bool {
+		dup := seen[id]
+		seen[id] = true
+		return dup
+	})
+
 	var failed int
-	for _, id := range params.ResourceIds {
+	for chunk := range slices.Chunk(ids, buildChunkSize) {
 		if ctx.Err() != nil {
 			return ctx.Err()
 		}
-		if err := idx.buildOne(ctx, plans, params.ResourceType, id, params.Metadata); err != nil {
-			logger.Warn("build failed", slog.String("id", id), slog.String("error", err.Error()))
-			failed++
+		if len(chunk) == 1 {
+			failed += idx.buildSingle(ctx, logger, plans, params.ResourceType, chunk[0], params.Metadata)
+			continue
+		}
+
+		n, rejected, err := idx.buildChunk(ctx, logger, plans, params.ResourceType, chunk, params.Metadata)
+		if err == nil {
+			failed += n
+			// Build the documents ES rejected again on their own, so that
+			// their failures are reported one by one.
+			for _, id := range rejected {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				failed += idx.buildSingle(ctx, logger, plans, params.ResourceType, id, params.Metadata)
+			}
+			continue
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		// Build the chunk one resource at a time, so that a resource that
+		// cannot be built does not fail the others.
+		logger.Warn("chunk build failed, building one at a time", slog.Int("count", len(chunk)), slog.String("error", err.Error()))
+		for _, id := range chunk {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			failed += idx.buildSingle(ctx, logger, plans, params.ResourceType, id, params.Metadata)
 		}
 	}
 	if failed > 0 {
-		logger.Warn("build complete with failures", slog.Int("total", len(params.ResourceIds)), slog.Int("failed", failed))
+		logger.Warn("build complete with failures", slog.Int("total", len(ids)), slog.Int("failed", failed))
 	}
 	return nil
 }
 
-func (idx *Indexer) buildOne(ctx context.Context, plans []projection.Plan, resourceType, resourceID string, metadata map[string]string) error {
-	if err := idx.st.RemoveResource(ctx, model.Resource{Type: resourceType, Id: resourceID}); err != nil {
-		return fmt.Errorf("removing relations: %w", err)
+// buildSingle builds a single resource and returns 1 if it failed.
+func (idx *Indexer) buildSingle(ctx context.Context, logger *slog.Logger, plans []projection.Plan, resourceType, id string, metadata map[string]string) int {
+	failed, rejected, err := idx.buildChunk(ctx, logger, plans, resourceType, []string{id}, metadata)
+	if err != nil {
+		logger.Warn("build failed", slog.String("id", id), slog.String("error", err.Error()))
+		return 1
+	}
+	if len(rejected) > 0 {
+		return 1
 	}
+	return failed
+}
 
-	var allRelations []model.Resource
+// buildChunk builds the resources of the IDs with every plan and indexes them
+// in one bulk request. Resources that no longer exist at the source are
+// deleted. It returns the number of resources that failed on their own, the
+// IDs of the resources ES rejected a document of, and an error if the chunk
+// could not be built at all.
+func (idx *Indexer) buildChunk(ctx context.Context, logger *slog.Logger, plans []projection.Plan, resourceType string, ids []string, metadata map[string]string) (int, []string, error) {
+	coercion := idx.resources.Get(resourceType).Coercion
+	hasReverse := len(idx.resources.ReverseRefs(resourceType)) > 0
 
-	for _, plan := range plans {
+	// Everything is fetched before anything is changed, so that a chunk that
+	// fails can be built again one resource at a time.
+	docs := make([][]projection.BuildDoc, len(plans))
+	for i, plan := range plans {
 		if plan.Executer == nil {
 			continue
 		}
 		ch := plan.Execute(ctx, projection.BuildRequest{
 			ResourceType: resourceType,
-			ResourceID:   resourceID,
+			ResourceIDs:  ids,
 			Metadata:     metadata,
 		})
+		for page := range ch {
+			if page.Err != nil {
+				return 0, nil, fmt.Errorf("plan execution for %s v%d: %w", resourceType, plan.Version, page.Err)
+			}
+			docs[i] = append(docs[i], page.Items...)
+		}
+		// A cancelled plan stops without reporting it.
+		if err := ctx.Err(); err != nil {
+			return 0, nil, err
+		}
+	}
 
-		var result projection.BuildDoc
-		for r := range ch {
-			if r.Err != nil {
-				return r.Err
+	var failed int
+	deleted := make(map[string]bool)
+	rejected := make(map[string]bool)
+	for _, planDocs := range docs {
+		for _, doc := range planDocs {
+			id := doc.Root.Id
+			if doc.Doc == nil {
+				// Resource no longer exists at source — delete from all versions.
+				deleted[id] = true
+				continue
+			}
+			if len(doc.Violations) == 0 || rejected[id] {
+				continue
 			}
-			if len(r.Items) > 0 {
-				result = r.Items[0]
-				break
+			if coercion.Fails() {
+				logViolations(logger, id, doc.Violations, resource.CoercionFail)
+				err := &projection.ViolationError{Root: doc.Root, Violations: doc.Violations}
+				logger.Warn("build failed", slog.String("id", id), slog.String("error", err.Error()))
+				rejected[id] = true
+				failed++
+				continue
 			}
+			logViolations(logger, id, doc.Violations, coercion.Policy)
 		}
+	}
 
-		// Resource no longer exists at source — delete from all versions.
-		if result.Doc == nil {
-			return idx.handleDelete(ctx, RebuildPayload{
-				ResourceType: resourceType,
-				ResourceID:   resourceID,
-			})
+	// The previous relations are needed to find the resources that embed a
+	// resource through a reverse relation and are no longer referenced.
+	previousRelations := make(map[string][]model.Resource)
+	cleaned := make(map[string]bool)
+	reverseRoots := make(map[string][]string)
+	for _, id := range ids {
+		if rejected[id] {
+			continue
+		}
+		root := model.Resource{Type: resourceType, Id: id}
+		if hasReverse {
+			before, err := idx.st.GetChildResources(ctx, root)
+			if err != nil {
+				logger.Warn("failed to get relations", slog.String("id", id), slog.String("error", err.Error()))
+				rejected[id] = true
+				failed++
+				continue
+			}
+			previousRelations[id] = before
 		}
 
-		allRelations = append(allRelations, result.Relations...)
+		if deleted[id] {
+			if err := idx.handleDelete(ctx, RebuildPayload{ResourceType: resourceType, ResourceID: id}); err != nil {
+				logger.Warn("failed to delete", slog.String("id", id), slog.String("error", err.Error()))
+				rejected[id] = true
+				failed++
+				continue
+			}
+			mergeRoots(reverseRoots, idx.changedReverseRoots(resourceType, previousRelations[id], nil))
+			continue
+		}
 
-		indexName := es.IndexName(resourceType, plan.Version)
-		if err := idx.es.Upsert(ctx, indexName, resourceID, result.Doc); err != nil {
-			return fmt.Errorf("upsert %s/%s to %s: %w", resourceType, resourceID, indexName, err)
+		if err := idx.st.RemoveResource(ctx, root); err != nil {
+			logger.Warn("failed to remove relations", slog.String("id", id), slog.String("error", err.Error()))
+			rejected[id] = true
+			failed++
+			continue
 		}
+		cleaned[id] = true
 	}
 
-	if err := idx.st.AddChildResources(ctx,
-		model.Resource{Type: resourceType, Id: resourceID},
-		allRelations,
-	); err != nil {
-		return fmt.Errorf("persist relations for %s/%s: %w", resourceType, resourceID, err)
+	resourceRelations := make(map[string][]model.Resource)
+	var items []es.BulkItem
+	for i, plan := range plans {
+		for _, doc := range docs[i] {
+			id := doc.Root.Id
+			if !cleaned[id] {
+				continue
+			}
+			resourceRelations[id] = append(resourceRelations[id], doc.Relations...)
+			items = append(items, es.BulkItem{
+				Index: es.IndexName(resourceType, plan.Version),
+				ID:    id,
+				Doc:   doc.Doc,
+			})
+		}
 	}
 
-	return nil
+	esRejected, err := idx.bulkUpsert(ctx, logger, items)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var rejectedIDs []string
+	for _, id := range ids {
+		if esRejected[id] {
+			rejectedIDs = append(rejectedIDs, id)
+			continue
+		}
+		rels, ok := resourceRelations[id]
+		if !ok {
+			continue
+		}
+		if err := idx.st.AddChildResources(ctx, model.Resource{Type: resourceType, Id: id}, rels); err != nil {
+			logger.Warn("failed to persist relations", slog.String("id", id), slog.String("error", err.Error()))
+			failed++
+			continue
+		}
+		mergeRoots(reverseRoots, idx.changedReverseRoots(resourceType, previousRelations[id], rels))
+	}
+
+	if err := idx.enqueueBuilds(ctx, reverseRoots, metadata); err != nil {
+		return 0, nil, err
+	}
+	return failed, rejectedIDs, nil
 }
 
 func (idx *Indexer) rebuild(ctx context.Context, params FullRebuildArgs) error {
@@ -106,7 +252,11 @@ func (idx *Indexer) rebuild(ctx context.Context, params FullRebuildArgs) error {
 	}
 
 	resourceRelations := make(map[string][]model.Resource)
+	previousRelations := make(map[string][]model.Resource)
 	cleaned := make(map[string]bool)
+	hasReverse := len(idx.resources.ReverseRefs(params.ResourceType)) > 0
+	coercion := idx.resources.Get(params.ResourceType).Coercion
+	rejected := make(map[string]bool)
 
 	var items []es.BulkItem
 	var failed int
@@ -129,7 +279,28 @@ func (idx *Indexer) rebuild(ctx context.Context, params FullRebuildArgs) error {
 			for _, doc := range page.Items {
 				id := doc.Root.Id
 
+				if len(doc.Violations) > 0 {
+					if coercion.Fails() {
+						logViolations(logger, id, doc.Violations, resource.CoercionFail)
+						if !rejected[id] {
+							rejected[id] = true
+							failed++
+						}
+						continue
+					}
+					logViolations(logger, id, doc.Violations, coercion.Policy)
+				}
+
 				if !cleaned[id] {
+					if hasReverse {
+						before, err := idx.st.GetChildResources(ctx, doc.Root)
+						if err != nil {
+							logger.Warn("failed to get relations", slog.String("id", id), slog.String("error", err.Error()))
+							failed++
+							continue
+						}
+						previousRelations[id] = before
+					}
 					if err := idx.st.RemoveResource(ctx, doc.Root); err != nil {
 						logger.Warn("failed to remove relations", slog.String("id", id), slog.String("error", err.Error()))
 						failed++
@@ -147,19 +318,87 @@ func (idx *Indexer) rebuild(ctx context.Context, params FullRebuildArgs) error {
 				})
 			}
 		}
+		// A cancelled plan stops without reporting it.
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 	}
 
-	if err := idx.es.BulkUpsert(ctx, items); err != nil {
-		return fmt.Errorf("bulk upsert: %w", err)
+	// A resource rejected in one version is not indexed in any.
+	items = slices.DeleteFunc(items, func(item es.BulkItem) bool { return rejected[item.ID] })
+
+	esRejected, err := idx.bulkUpsert(ctx, logger, items)
+	if err != nil {
+		return err
 	}
+	failed += len(esRejected)
 
+	reverseRoots := make(map[string][]string)
 	for id, rels := range resourceRelations {
+		// Relations may have been collected from a version before the one
+		// rejecting the resource.
+		if rejected[id] || esRejected[id] {
+			continue
+		}
 		if err := idx.st.AddChildResources(ctx, model.Resource{Type: params.ResourceType, Id: id}, rels); err != nil {
 			logger.Warn("failed to persist relations", slog.String("id", id), slog.String("error", err.Error()))
 			failed++
+			continue
 		}
+		mergeRoots(reverseRoots, idx.changedReverseRoots(params.ResourceType, previousRelations[id], rels))
+	}
+
+	if err := idx.enqueueBuilds(ctx, reverseRoots, params.Metadata); err != nil {
+		return err
 	}
 
 	logger.Info("build complete", slog.Int("total", len(cleaned)), slog.Int("failed", failed))
 	return nil
 }
+
+// bulkUpsert indexes the items in one bulk request and returns the IDs of
+// the resources ES rejected a document of, logging each rejection.
+func (idx *Indexer) bulkUpsert(ctx context.Context, logger *slog.Logger, items []es.BulkItem) (map[string]bool, error) {
+	failures, err := idx.es.BulkUpsert(ctx, items)
+	if err != nil {
+		return nil, fmt.Errorf("bulk upsert: %w", err)
+	}
+
+	rejected := make(map[string]bool, len(failures))
+	for _, f := range failures {
+		logger.Warn("document rejected",
+			slog.String("id", f.ID),
+			slog.String("index", f.Index),
+			slog.String("reason", f.Reason),
+		)
+		rejected[f.ID] = true
+	}
+	return rejected, nil
+}
+
+// mergeRoots adds the IDs of roots to dst, by resource type, skipping those
+// already in it.
+func mergeRoots(dst, roots map[string][]string) {
+	for typ, ids := range roots {
+		for _, id := range ids {
+			if !slices.Contains(dst[typ], id) {
+				dst[typ] = append(dst[typ], id)
+			}
+		}
+	}
+}
+
+// logViolations reports the values of a resource that could not be converted
+// to the types of their fields, and what the policy did with them.
+func logViolations(logger *slog.Logger, id string, violations []projection.Violation, policy string) {
+	for _, v := range violations {
+		logger.Warn("invalid field value",
+			slog.String("id", id),
+			slog.String("field", v.Field),
+			slog.String("field_type", v.Type),
+			slog.Any("value", v.Value),
+			slog.String("reason", v.Reason),
+			slog.String("policy", policy),
+		)
+	}
+}
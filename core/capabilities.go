@@ -1,8 +1,6 @@
 package core
 
 import (
-	"fmt"
-
 	"github.com/theleeeo/indexer/gen/search/v1"
 	"github.com/theleeeo/indexer/resource"
 )
@@ -14,47 +12,146 @@ func (idx *Indexer) GetCapabilities() *search.GetCapabilitiesResponse {
 	resp := &search.GetCapabilitiesResponse{}
 
 	for _, rc := range idx.resources {
-		cap := &search.ResourceCapability{
+		resp.Resources = append(resp.Resources, &search.ResourceCapability{
 			Resource: rc.Resource,
-		}
+			Fields:   versionCapabilities(rc.ReadVersionConfig()),
+		})
+	}
 
-		vc := rc.ReadVersionConfig()
+	return resp
+}
 
-		for _, f := range vc.Fields {
-			cap.Fields = append(cap.Fields, fieldCapability("fields."+f.Name, f))
+// versionCapabilities returns the capabilities of every field in the given
+// version config, root fields first followed by relation fields. Sub-fields
+// follow the field they belong to, and relations of relations follow their
+// parent relation. Object and nested fields are listed by the fields they
+// contain.
+func versionCapabilities(vc *resource.VersionConfig) []*search.FieldCapability {
+	var caps []*search.FieldCapability
+	for _, f := range vc.DocumentFields() {
+		if f.Field.IsObject() {
+			continue
 		}
+		caps = append(caps, fieldCapabilities(f)...)
+	}
+	return caps
+}
 
-		for _, rel := range vc.Relations {
-			for _, f := range rel.Fields {
-				cap.Fields = append(cap.Fields, fieldCapability(fmt.Sprintf("%s.%s", rel.Resource, f.Name), f))
-			}
-		}
+// fieldCapabilities returns the capability of a field followed by those of
+// its sub-fields. Sub-fields are not part of full-text search.
+func fieldCapabilities(f resource.DocumentField) []*search.FieldCapability {
+	fc := typeCapability(f.Path, f.Field.ESType(), f.Field.Query.Searchable())
+	fc.Suggestable = f.Field.Suggest
+	fc.NestedPath = f.NestedPath
 
-		resp.Resources = append(resp.Resources, cap)
+	caps := []*search.FieldCapability{fc}
+	for _, sf := range f.Field.SubFields {
+		sc := typeCapability(f.Path+"."+sf.Name, sf.ESType(), false)
+		sc.NestedPath = f.NestedPath
+		caps = append(caps, sc)
 	}
+	return caps
+}
 
-	return resp
+func typeCapability(path, esType string, searchable bool) *search.FieldCapability {
+	return &search.FieldCapability{
+		Field:        path,
+		Type:         esType,
+		Searchable:   searchable,
+		Sortable:     esType != "text",
+		FilterOps:    filterOpsForType(esType),
+		Aggregations: aggregationsForType(esType),
+	}
 }
 
-func fieldCapability(path string, f resource.FieldConfig) *search.FieldCapability {
-	esType := f.ESType()
-	searchable := f.Query.Search == nil || *f.Query.Search
+// filterOpsForType returns the filter operations supported on a field of the
+// given ES type.
+func filterOpsForType(esType string) []search.FilterOp {
+	switch {
+	case isKeywordType(esType):
+		return []search.FilterOp{
+			search.FilterOp_FILTER_OP_EQ,
+			search.FilterOp_FILTER_OP_IN,
+			search.FilterOp_FILTER_OP_EXISTS,
+			search.FilterOp_FILTER_OP_PREFIX,
+			search.FilterOp_FILTER_OP_WILDCARD,
+			search.FilterOp_FILTER_OP_NOT_EQ,
+			search.FilterOp_FILTER_OP_NOT_IN,
+		}
 
-	fc := &search.FieldCapability{
-		Field:      path,
-		Type:       esType,
-		Searchable: searchable,
-		Sortable:   esType != "text",
-	}
+	case isNumericType(esType), isDateType(esType):
+		return []search.FilterOp{
+			search.FilterOp_FILTER_OP_EQ,
+			search.FilterOp_FILTER_OP_IN,
+			search.FilterOp_FILTER_OP_RANGE,
+			search.FilterOp_FILTER_OP_EXISTS,
+			search.FilterOp_FILTER_OP_NOT_EQ,
+			search.FilterOp_FILTER_OP_NOT_IN,
+		}
 
-	// Term-based filters work on keyword, numeric, date, and boolean types.
-	// They do not work reliably on analyzed text fields.
-	if esType != "text" {
-		fc.FilterOps = []search.FilterOp{
+	case esType == "boolean":
+		return []search.FilterOp{
 			search.FilterOp_FILTER_OP_EQ,
 			search.FilterOp_FILTER_OP_IN,
+			search.FilterOp_FILTER_OP_EXISTS,
+			search.FilterOp_FILTER_OP_NOT_EQ,
+			search.FilterOp_FILTER_OP_NOT_IN,
 		}
+
+	default:
+		// Term-based filters do not work reliably on analyzed text fields,
+		// and other types (geo_point, ...) need dedicated queries. Presence
+		// checks work on everything.
+		return []search.FilterOp{
+			search.FilterOp_FILTER_OP_EXISTS,
+		}
+	}
+}
+
+// aggregationsForType returns the aggregation kinds supported on a field of
+// the given ES type. Analyzed text fields cannot be aggregated on.
+func aggregationsForType(esType string) []search.AggregationKind {
+	switch {
+	case isKeywordType(esType), esType == "boolean":
+		return []search.AggregationKind{
+			search.AggregationKind_AGGREGATION_KIND_TERMS,
+		}
+
+	case isNumericType(esType):
+		return []search.AggregationKind{
+			search.AggregationKind_AGGREGATION_KIND_TERMS,
+			search.AggregationKind_AGGREGATION_KIND_RANGE,
+			search.AggregationKind_AGGREGATION_KIND_METRIC,
+		}
+
+	case isDateType(esType):
+		return []search.AggregationKind{
+			search.AggregationKind_AGGREGATION_KIND_TERMS,
+			search.AggregationKind_AGGREGATION_KIND_DATE_HISTOGRAM,
+			search.AggregationKind_AGGREGATION_KIND_METRIC,
+		}
+
+	default:
+		return nil
+	}
+}
+
+func isKeywordType(esType string) bool {
+	switch esType {
+	case "keyword", "constant_keyword", "wildcard":
+		return true
 	}
+	return false
+}
+
+func isNumericType(esType string) bool {
+	switch esType {
+	case "long", "integer", "short", "byte", "double", "float", "half_float", "scaled_float", "unsigned_long":
+		return true
+	}
+	return false
+}
 
-	return fc
+func isDateType(esType string) bool {
+	return esType == "date" || esType == "date_nanos"
 }
@@ -7,6 +7,29 @@ import (
 	"github.com/theleeeo/indexer/resource"
 )
 
+var (
+	keywordOps = []search.FilterOp{
+		search.FilterOp_FILTER_OP_EQ,
+		search.FilterOp_FILTER_OP_IN,
+		search.FilterOp_FILTER_OP_EXISTS,
+		search.FilterOp_FILTER_OP_PREFIX,
+		search.FilterOp_FILTER_OP_WILDCARD,
+		search.FilterOp_FILTER_OP_NOT_EQ,
+		search.FilterOp_FILTER_OP_NOT_IN,
+	}
+	numericOps = []search.FilterOp{
+		search.FilterOp_FILTER_OP_EQ,
+		search.FilterOp_FILTER_OP_IN,
+		search.FilterOp_FILTER_OP_RANGE,
+		search.FilterOp_FILTER_OP_EXISTS,
+		search.FilterOp_FILTER_OP_NOT_EQ,
+		search.FilterOp_FILTER_OP_NOT_IN,
+	}
+	textOps = []search.FilterOp{
+		search.FilterOp_FILTER_OP_EXISTS,
+	}
+)
+
 func TestGetCapabilities_Empty(t *testing.T) {
 	idx := New(Config{})
 	resp := idx.GetCapabilities()
@@ -49,12 +72,11 @@ func TestGetCapabilities_SingleResource(t *testing.T) {
 
 	// title — text field
 	title := rc.Fields[0]
-	assertField(t, title, "fields.title", "text", true, false, nil)
+	assertField(t, title, "fields.title", "text", true, false, textOps)
 
 	// status — keyword field (default)
 	status := rc.Fields[1]
-	assertField(t, status, "fields.status", "keyword", true, true,
-		[]search.FilterOp{search.FilterOp_FILTER_OP_EQ, search.FilterOp_FILTER_OP_IN})
+	assertField(t, status, "fields.status", "keyword", true, true, keywordOps)
 }
 
 func TestGetCapabilities_WithRelations(t *testing.T) {
@@ -89,11 +111,36 @@ func TestGetCapabilities_WithRelations(t *testing.T) {
 		t.Fatalf("expected 3 fields, got %d", len(rc.Fields))
 	}
 
-	assertField(t, rc.Fields[0], "fields.order_number", "keyword", true, true,
-		[]search.FilterOp{search.FilterOp_FILTER_OP_EQ, search.FilterOp_FILTER_OP_IN})
-	assertField(t, rc.Fields[1], "customer.name", "text", true, false, nil)
-	assertField(t, rc.Fields[2], "customer.tier", "keyword", true, true,
-		[]search.FilterOp{search.FilterOp_FILTER_OP_EQ, search.FilterOp_FILTER_OP_IN})
+	assertField(t, rc.Fields[0], "fields.order_number", "keyword", true, true, keywordOps)
+	assertField(t, rc.Fields[1], "customer.name", "text", true, false, textOps)
+	assertField(t, rc.Fields[2], "customer.tier", "keyword", true, true, keywordOps)
+}
+
+func TestGetCapabilities_RelationAliases(t *testing.T) {
+	cfg := &resource.Config{
+		Resource: "ticket",
+		Versions: []resource.VersionConfig{
+			{
+				Version: 1,
+				Relations: []resource.RelationConfig{
+					{Resource: "user", As: "assignee", Fields: []resource.FieldConfig{{Name: "name"}}},
+					{Resource: "user", As: "reporter", Fields: []resource.FieldConfig{{Name: "name"}}},
+				},
+			},
+		},
+	}
+	cfg.ApplyDefaults()
+	idx := New(Config{
+		Resources: resource.Configs{cfg},
+	})
+
+	rc := idx.GetCapabilities().Resources[0]
+	if len(rc.Fields) != 2 {
+		t.Fatalf("expected 2 fields, got %d", len(rc.Fields))
+	}
+
+	assertField(t, rc.Fields[0], "assignee.name", "keyword", true, true, keywordOps)
+	assertField(t, rc.Fields[1], "reporter.name", "keyword", true, true, keywordOps)
 }
 
 func TestGetCapabilities_SearchDisabled(t *testing.T) {
@@ -115,8 +162,7 @@ func TestGetCapabilities_SearchDisabled(t *testing.T) {
 
 	resp := idx.GetCapabilities()
 	f := resp.Resources[0].Fields[0]
-	assertField(t, f, "fields.code", "keyword", false, true,
-		[]search.FilterOp{search.FilterOp_FILTER_OP_EQ, search.FilterOp_FILTER_OP_IN})
+	assertField(t, f, "fields.code", "keyword", false, true, keywordOps)
 }
 
 func TestGetCapabilities_MultipleResources(t *testing.T) {
@@ -137,8 +183,38 @@ func TestGetCapabilities_MultipleResources(t *testing.T) {
 	}
 
 	bField := resp.Resources[1].Fields[0]
-	assertField(t, bField, "fields.y", "integer", true, true,
-		[]search.FilterOp{search.FilterOp_FILTER_OP_EQ, search.FilterOp_FILTER_OP_IN})
+	assertField(t, bField, "fields.y", "integer", true, true, numericOps)
+}
+
+func TestGetCapabilities_FilterOpsByType(t *testing.T) {
+	cfg := &resource.Config{
+		Resource: "event",
+		Versions: []resource.VersionConfig{
+			{
+				Version: 1,
+				Fields: []resource.FieldConfig{
+					{Name: "at", Type: "date"},
+					{Name: "active", Type: "boolean"},
+					{Name: "location", Type: "geo_point"},
+				},
+			},
+		},
+	}
+	cfg.ApplyDefaults()
+	idx := New(Config{
+		Resources: resource.Configs{cfg},
+	})
+
+	fields := idx.GetCapabilities().Resources[0].Fields
+	assertField(t, fields[0], "fields.at", "date", true, true, numericOps)
+	assertField(t, fields[1], "fields.active", "boolean", true, true, []search.FilterOp{
+		search.FilterOp_FILTER_OP_EQ,
+		search.FilterOp_FILTER_OP_IN,
+		search.FilterOp_FILTER_OP_EXISTS,
+		search.FilterOp_FILTER_OP_NOT_EQ,
+		search.FilterOp_FILTER_OP_NOT_IN,
+	})
+	assertField(t, fields[2], "fields.location", "geo_point", true, true, textOps)
 }
 
 func assertField(t *testing.T, f *search.FieldCapability, wantField, wantType string, wantSearchable, wantSortable bool, wantOps []search.FilterOp) {
@@ -165,3 +241,136 @@ func assertField(t *testing.T, f *search.FieldCapability, wantField, wantType st
 		}
 	}
 }
+
+func TestGetCapabilities_SubFields(t *testing.T) {
+	cfg := &resource.Config{
+		Resource: "product",
+		Versions: []resource.VersionConfig{
+			{
+				Version: 1,
+				Fields: []resource.FieldConfig{
+					{Name: "title", Type: "text", SubFields: []resource.SubFieldConfig{
+						{Name: "keyword"},
+						{Name: "autocomplete", Type: "text", Analyzer: "standard"},
+					}},
+				},
+			},
+		},
+	}
+	cfg.ApplyDefaults()
+	idx := New(Config{
+		Resources: resource.Configs{cfg},
+	})
+
+	fields := idx.GetCapabilities().Resources[0].Fields
+	if len(fields) != 3 {
+		t.Fatalf("expected 3 fields, got %d", len(fields))
+	}
+
+	assertField(t, fields[0], "fields.title", "text", true, false, textOps)
+	assertField(t, fields[1], "fields.title.keyword", "keyword", false, true, keywordOps)
+	assertField(t, fields[2], "fields.title.autocomplete", "text", false, false, textOps)
+}
+
+func TestGetCapabilities_Suggest(t *testing.T) {
+	cfg := &resource.Config{
+		Resource: "product",
+		Versions: []resource.VersionConfig{
+			{
+				Version: 1,
+				Fields: []resource.FieldConfig{
+					{Name: "title", Type: "text", Suggest: true},
+					{Name: "sku"},
+				},
+			},
+		},
+	}
+	cfg.ApplyDefaults()
+	idx := New(Config{
+		Resources: resource.Configs{cfg},
+	})
+
+	fields := idx.GetCapabilities().Resources[0].Fields
+	if len(fields) != 2 {
+		t.Fatalf("expected 2 fields, got %d", len(fields))
+	}
+	if !fields[0].Suggestable {
+		t.Errorf("expected %s to be suggestable", fields[0].Field)
+	}
+	if fields[1].Suggestable {
+		t.Errorf("expected %s not to be suggestable", fields[1].Field)
+	}
+}
+
+func TestGetCapabilities_ComputedFields(t *testing.T) {
+	cfg := &resource.Config{
+		Resource: "customer",
+		Versions: []resource.VersionConfig{
+			{
+				Version: 1,
+				Fields: []resource.FieldConfig{
+					{Name: "full_name", Computed: `concat(first_name, " ", last_name)`},
+					{Name: "order_count", Computed: `count(orders)`},
+					{Name: "signup_month", Computed: `date_trunc("month", created_at)`},
+					// The type of a plain reference is unknown.
+					{Name: "largest_order", Computed: `max(orders.total)`},
+					{Name: "largest_order_double", Type: "double", Computed: `max(orders.total)`},
+				},
+			},
+		},
+	}
+	cfg.ApplyDefaults()
+	idx := New(Config{
+		Resources: resource.Configs{cfg},
+	})
+
+	fields := idx.GetCapabilities().Resources[0].Fields
+	assertField(t, fields[0], "fields.full_name", "keyword", true, true, keywordOps)
+	assertField(t, fields[1], "fields.order_count", "long", true, true, numericOps)
+	assertField(t, fields[2], "fields.signup_month", "date", true, true, numericOps)
+	assertField(t, fields[3], "fields.largest_order", "keyword", true, true, keywordOps)
+	assertField(t, fields[4], "fields.largest_order_double", "double", true, true, numericOps)
+}
+
+func TestGetCapabilities_ObjectFields(t *testing.T) {
+	cfg := &resource.Config{
+		Resource: "order",
+		Versions: []resource.VersionConfig{
+			{
+				Version: 1,
+				Fields: []resource.FieldConfig{
+					{Name: "address", Type: "object", Fields: []resource.FieldConfig{
+						{Name: "city"},
+					}},
+					{Name: "items", Type: "nested", Fields: []resource.FieldConfig{
+						{Name: "sku"},
+						{Name: "quantity", Type: "integer"},
+					}},
+				},
+				Relations: []resource.RelationConfig{
+					{Resource: "shipment", Fields: []resource.FieldConfig{{Name: "carrier"}}},
+				},
+			},
+		},
+	}
+	cfg.ApplyDefaults()
+	idx := New(Config{
+		Resources: resource.Configs{cfg},
+	})
+
+	fields := idx.GetCapabilities().Resources[0].Fields
+	if len(fields) != 4 {
+		t.Fatalf("expected 4 fields, got %d", len(fields))
+	}
+
+	assertField(t, fields[0], "fields.address.city", "keyword", true, true, keywordOps)
+	assertField(t, fields[1], "fields.items.sku", "keyword", true, true, keywordOps)
+	assertField(t, fields[2], "fields.items.quantity", "integer", true, true, numericOps)
+	assertField(t, fields[3], "shipment.carrier", "keyword", true, true, keywordOps)
+
+	for i, want := range []string{"", "fields.items", "fields.items", "shipment"} {
+		if fields[i].NestedPath != want {
+			t.Errorf("nested path of %q: got %q, want %q", fields[i].Field, fields[i].NestedPath, want)
+		}
+	}
+}
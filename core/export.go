@@ -0,0 +1,39 @@
+package core
+
+import (
+	"context"
+	"errors"
+
+	"github.com/theleeeo/indexer/es"
+	"github.com/theleeeo/indexer/gen/search/v1"
+)
+
+// Export streams every document of the given resource that matches the query
+// and filters of the request to fn, in batches. It stops as soon as ctx is
+// cancelled or fn returns an error.
+func (idx *Indexer) Export(ctx context.Context, req *search.ExportRequest, fn func([]*search.SearchHit) error) error {
+	if req.Resource == "" {
+		return errors.New("resource is required")
+	}
+
+	r := idx.resources.Get(req.Resource)
+	if r == nil {
+		return ErrUnknownResource
+	}
+
+	if req.BatchSize < 0 {
+		return &InvalidArgumentError{Msg: "batch_size cannot be negative"}
+	}
+
+	vc := r.ReadVersionConfig()
+
+	if err := prepareFilters(vc, req.Filters, req.FilterGroup); err != nil {
+		return err
+	}
+
+	if err := validateSourceFields(vc, req.Fields); err != nil {
+		return err
+	}
+
+	return idx.es.Export(ctx, req, es.AliasName(r.Resource), vc.GetSearchableFields(), r.Query, fn)
+}
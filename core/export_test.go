@@ -0,0 +1,48 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/theleeeo/indexer/gen/search/v1"
+)
+
+func TestExport_InvalidRequest(t *testing.T) {
+	idx := New(Config{Resources: testResources()})
+
+	tests := []struct {
+		name string
+		req  *search.ExportRequest
+	}{
+		{"negative batch size", &search.ExportRequest{Resource: "product", BatchSize: -1}},
+		{"unknown source field", &search.ExportRequest{Resource: "product", Fields: []string{"fields.missing"}}},
+		{"unsupported filter op", &search.ExportRequest{
+			Resource: "product",
+			Filters:  []*search.Filter{{Field: "fields.title", Op: search.FilterOp_FILTER_OP_EQ, Value: "x"}},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := idx.Export(context.Background(), tt.req, func([]*search.SearchHit) error {
+				t.Fatal("no hits expected")
+				return nil
+			})
+
+			var invalidArg *InvalidArgumentError
+			if !errors.As(err, &invalidArg) {
+				t.Fatalf("expected InvalidArgumentError, got %v", err)
+			}
+		})
+	}
+}
+
+func TestExport_UnknownResource(t *testing.T) {
+	idx := New(Config{Resources: testResources()})
+
+	err := idx.Export(context.Background(), &search.ExportRequest{Resource: "nonexistent"}, nil)
+	if !errors.Is(err, ErrUnknownResource) {
+		t.Fatalf("expected ErrUnknownResource, got %v", err)
+	}
+}
@@ -0,0 +1,79 @@
+package core
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/theleeeo/indexer/es"
+	"github.com/theleeeo/indexer/gen/search/v1"
+)
+
+// MultiSearch executes a search query against the indices of several
+// resources at once and merges their hits.
+func (idx *Indexer) MultiSearch(ctx context.Context, req *search.MultiSearchRequest) (*search.MultiSearchResponse, error) {
+	if len(req.Resources) == 0 {
+		return nil, &InvalidArgumentError{Msg: "at least one resource is required"}
+	}
+
+	if req.Size <= 0 {
+		req.Size = 25
+	}
+
+	if req.Size > 100 {
+		req.Size = 100
+	}
+
+	targets := make([]es.MultiSearchTarget, 0, len(req.Resources))
+	seen := make(map[string]bool, len(req.Resources))
+
+	for _, rr := range req.Resources {
+		if rr == nil || rr.Resource == "" {
+			return nil, &InvalidArgumentError{Msg: "resource is required"}
+		}
+
+		if seen[rr.Resource] {
+			return nil, &InvalidArgumentError{Msg: fmt.Sprintf("resource %q is listed more than once", rr.Resource)}
+		}
+		seen[rr.Resource] = true
+
+		r := idx.resources.Get(rr.Resource)
+		if r == nil {
+			return nil, ErrUnknownResource
+		}
+
+		if rr.Weight < 0 {
+			return nil, &InvalidArgumentError{Msg: fmt.Sprintf("weight of resource %q cannot be negative", rr.Resource)}
+		}
+		weight := rr.Weight
+		if weight == 0 {
+			weight = 1
+		}
+
+		if rr.Quota < 0 {
+			return nil, &InvalidArgumentError{Msg: fmt.Sprintf("quota of resource %q cannot be negative", rr.Resource)}
+		}
+		quota := rr.Quota
+		if quota == 0 || quota > req.Size {
+			quota = req.Size
+		}
+
+		vc := r.ReadVersionConfig()
+
+		if err := prepareFilters(vc, rr.Filters, rr.FilterGroup); err != nil {
+			return nil, err
+		}
+
+		targets = append(targets, es.MultiSearchTarget{
+			Resource:     r.Resource,
+			IndexAlias:   es.AliasName(r.Resource),
+			SearchFields: vc.GetSearchableFields(),
+			Mode:         r.Query,
+			Filters:      rr.Filters,
+			FilterGroup:  rr.FilterGroup,
+			Size:         quota,
+			Weight:       weight,
+		})
+	}
+
+	return idx.es.MultiSearch(ctx, req.Query, targets, req.Size, req.IncludeSource)
+}
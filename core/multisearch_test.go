@@ -0,0 +1,55 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/theleeeo/indexer/gen/search/v1"
+)
+
+func TestMultiSearch_InvalidRequest(t *testing.T) {
+	idx := New(Config{Resources: testResources()})
+
+	tests := []struct {
+		name      string
+		resources []*search.MultiSearchResource
+		msg       string
+	}{
+		{"no resources", nil, "at least one resource is required"},
+		{"missing resource", []*search.MultiSearchResource{nil}, "resource is required"},
+		{"empty resource", []*search.MultiSearchResource{{Resource: ""}}, "resource is required"},
+		{"duplicate resource", []*search.MultiSearchResource{{Resource: "product"}, {Resource: "product"}}, `resource "product" is listed more than once`},
+		{"negative weight", []*search.MultiSearchResource{{Resource: "product", Weight: -1}}, `weight of resource "product" cannot be negative`},
+		{"negative quota", []*search.MultiSearchResource{{Resource: "product", Quota: -1}}, `quota of resource "product" cannot be negative`},
+		{"unsupported filter op", []*search.MultiSearchResource{{
+			Resource: "product",
+			Filters:  []*search.Filter{{Field: "fields.title", Op: search.FilterOp_FILTER_OP_EQ, Value: "x"}},
+		}}, `filter op FILTER_OP_EQ is not supported on text field "fields.title"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := idx.MultiSearch(context.Background(), &search.MultiSearchRequest{Resources: tt.resources})
+
+			var invalidArg *InvalidArgumentError
+			if !errors.As(err, &invalidArg) {
+				t.Fatalf("expected InvalidArgumentError, got %v", err)
+			}
+			if invalidArg.Msg != tt.msg {
+				t.Fatalf("unexpected message: %q", invalidArg.Msg)
+			}
+		})
+	}
+}
+
+func TestMultiSearch_UnknownResource(t *testing.T) {
+	idx := New(Config{Resources: testResources()})
+
+	_, err := idx.MultiSearch(context.Background(), &search.MultiSearchRequest{
+		Resources: []*search.MultiSearchResource{{Resource: "product"}, {Resource: "nonexistent"}},
+	})
+	if !errors.Is(err, ErrUnknownResource) {
+		t.Fatalf("expected ErrUnknownResource, got %v", err)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"slices"
 
 	"github.com/theleeeo/indexer/model"
 )
@@ -49,7 +50,27 @@ func (idx *Indexer) RegisterChange(ctx context.Context, n Notification) error {
 			return fmt.Errorf("getting parents: %w", err)
 		}
 
-		roots = append(roots, parents...)
+		for _, p := range parents {
+			if !slices.Contains(roots, p) {
+				roots = append(roots, p)
+			}
+		}
+	}
+
+	// A root embedding the resource through a reverse relation is only
+	// recorded as its parent once the root has been built since the resource
+	// referenced it. The resource records the roots it references itself, so
+	// they are looked up from this side too.
+	if refs := idx.resources.ReverseRefs(n.ResourceType); len(refs) > 0 {
+		referenced, err := idx.st.GetChildResources(ctx, res)
+		if err != nil {
+			return fmt.Errorf("getting referenced roots: %w", err)
+		}
+		for _, r := range referenced {
+			if isReverseRoot(refs, r) && !slices.Contains(roots, r) {
+				roots = append(roots, r)
+			}
+		}
 	}
 
 	slog.Info("registering change",
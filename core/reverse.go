@@ -0,0 +1,61 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/theleeeo/indexer/model"
+	"github.com/theleeeo/indexer/resource"
+)
+
+// changedReverseRoots returns the resources, grouped by type, that embed a
+// resource through a reverse relation and are affected by its relations
+// changing from before to after. A resource that starts or stops referencing
+// a root is not a child of that root yet, so RegisterChange does not find the
+// root; it has to be rebuilt from here instead.
+func (idx *Indexer) changedReverseRoots(resourceType string, before, after []model.Resource) map[string][]string {
+	refs := idx.resources.ReverseRefs(resourceType)
+	if len(refs) == 0 {
+		return nil
+	}
+
+	roots := make(map[string][]string)
+	add := func(from, without []model.Resource) {
+		for _, r := range from {
+			if !isReverseRoot(refs, r) || slices.Contains(without, r) || slices.Contains(roots[r.Type], r.Id) {
+				continue
+			}
+			roots[r.Type] = append(roots[r.Type], r.Id)
+		}
+	}
+	add(before, after)
+	add(after, before)
+
+	return roots
+}
+
+// isReverseRoot reports whether r is of a type embedding the resource of refs
+// through a reverse relation.
+func isReverseRoot(refs []resource.ReverseRef, r model.Resource) bool {
+	for _, ref := range refs {
+		if ref.Resource == r.Type {
+			return true
+		}
+	}
+	return false
+}
+
+// enqueueBuilds enqueues a build job per resource type for the given IDs.
+func (idx *Indexer) enqueueBuilds(ctx context.Context, roots map[string][]string, metadata map[string]string) error {
+	for resourceType, ids := range roots {
+		if _, err := idx.river.Insert(ctx, BuildArgs{
+			ResourceType: resourceType,
+			ResourceIds:  ids,
+			Metadata:     metadata,
+		}, nil); err != nil {
+			return fmt.Errorf("enqueueing rebuild for %s: %w", resourceType, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package core
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/theleeeo/indexer/model"
+	"github.com/theleeeo/indexer/resource"
+)
+
+func TestChangedReverseRoots(t *testing.T) {
+	idx := New(Config{Resources: resource.Configs{
+		{Resource: "customer", Versions: []resource.VersionConfig{{
+			Relations: []resource.RelationConfig{
+				{Resource: "order", Reverse: &resource.ReverseConfig{Field: "customer_id"}},
+			},
+		}}},
+		{Resource: "order", Versions: []resource.VersionConfig{{
+			Relations: []resource.RelationConfig{
+				{Resource: "product", Key: resource.KeyConfig{Source: "order", Field: "product_id"}},
+			},
+		}}},
+	}})
+
+	before := []model.Resource{{Type: "customer", Id: "c1"}, {Type: "product", Id: "p1"}, {Type: "customer", Id: "c3"}}
+	after := []model.Resource{{Type: "customer", Id: "c2"}, {Type: "product", Id: "p2"}, {Type: "customer", Id: "c3"}}
+
+	got := idx.changedReverseRoots("order", before, after)
+	slices.Sort(got["customer"])
+	want := map[string][]string{"customer": {"c1", "c2"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+
+	if got := idx.changedReverseRoots("product", nil, []model.Resource{{Type: "customer", Id: "c1"}}); got != nil {
+		t.Fatalf("expected no roots for a type without reverse relations, got %v", got)
+	}
+}
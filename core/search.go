@@ -3,9 +3,13 @@ package core
 import (
 	"context"
 	"errors"
+	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/theleeeo/indexer/es"
 	"github.com/theleeeo/indexer/gen/search/v1"
+	"github.com/theleeeo/indexer/resource"
 )
 
 // Search executes a search query against the Elasticsearch index for the given resource.
@@ -22,17 +26,236 @@ func (idx *Indexer) Search(ctx context.Context, req *search.SearchRequest) (*sea
 	if req.PageSize <= 0 {
 		req.PageSize = 25
 	}
+
 	if req.PageSize > 100 {
 		req.PageSize = 100
 	}
+
 	if req.Page < 0 {
 		req.Page = 0
 	}
 
-	res, err := idx.es.Search(ctx, req, es.AliasName(r.Resource), r.ReadVersionConfig().GetSearchableFields())
+	if req.PageToken != "" && req.Page > 0 {
+		return nil, &InvalidArgumentError{Msg: "page cannot be combined with page_token"}
+	}
+
+	vc := r.ReadVersionConfig()
+
+	if err := prepareFilters(vc, req.Filters, req.FilterGroup); err != nil {
+		return nil, err
+	}
+
+	if err := validateSourceFields(vc, req.Fields); err != nil {
+		return nil, err
+	}
+
+	if req.Highlight != nil {
+		if err := resolveHighlight(vc, req.Highlight); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := resolveAggregations(vc, req.Aggregations); err != nil {
+		return nil, err
+	}
+
+	res, err := idx.es.Search(ctx, req, es.AliasName(r.Resource), vc.GetSearchableFields(), r.Query)
 	if err != nil {
+		if errors.Is(err, es.ErrInvalidPageToken) {
+			return nil, &InvalidArgumentError{Msg: err.Error()}
+		}
 		return nil, err
 	}
 
 	return res, nil
 }
+
+// prepareFilters resolves the nested paths of the flat filters and the filter
+// tree, and validates all of them against the given version.
+func prepareFilters(vc *resource.VersionConfig, filters []*search.Filter, group *search.FilterGroup) error {
+	resolveNestedPaths(vc, filters)
+	if group != nil {
+		if err := resolveGroupNestedPaths(vc, group); err != nil {
+			return err
+		}
+		filters = append(slices.Clone(filters), groupFilters(group)...)
+	}
+
+	return validateFilters(vc, filters)
+}
+
+// validateSourceFields checks that every requested source field exists in the
+// given version. A field is either a document field ("fields.title"), a
+// relation field ("b.name", "b.d.name"), or one of the document's objects
+// ("fields", "fields.address", "b", "b.d", "id").
+func validateSourceFields(vc *resource.VersionConfig, fields []string) error {
+	if len(fields) == 0 {
+		return nil
+	}
+
+	known := map[string]bool{
+		es.DocIDField: true,
+		"fields":      true,
+	}
+	for _, rel := range vc.EmbeddedRelations() {
+		known[rel.Path] = true
+	}
+	for _, f := range vc.DocumentFields() {
+		known[f.Path] = true
+	}
+	for _, fc := range versionCapabilities(vc) {
+		known[fc.Field] = true
+	}
+
+	for _, f := range fields {
+		if !known[f] {
+			return &InvalidArgumentError{Msg: fmt.Sprintf("unknown source field %q", f)}
+		}
+	}
+
+	return nil
+}
+
+// resolveHighlight validates the highlight options and defaults the
+// highlighted fields to the searchable fields of the given version.
+func resolveHighlight(vc *resource.VersionConfig, h *search.Highlight) error {
+	if h.FragmentSize < 0 {
+		return &InvalidArgumentError{Msg: "highlight fragment_size cannot be negative"}
+	}
+	if h.NumberOfFragments != nil && *h.NumberOfFragments < 0 {
+		return &InvalidArgumentError{Msg: "highlight number_of_fragments cannot be negative"}
+	}
+
+	if len(h.Fields) == 0 {
+		for _, f := range vc.GetSearchableFields() {
+			// Highlight fields don't take a boost.
+			path, _, _ := strings.Cut(f, "^")
+			h.Fields = append(h.Fields, path)
+		}
+		return nil
+	}
+
+	known := make(map[string]bool)
+	for _, fc := range versionCapabilities(vc) {
+		known[fc.Field] = true
+	}
+
+	for _, f := range h.Fields {
+		if !known[f] {
+			return &InvalidArgumentError{Msg: fmt.Sprintf("unknown highlight field %q", f)}
+		}
+	}
+
+	return nil
+}
+
+// validateFilters checks that each filter's op is supported by the type of
+// the field it targets in the given version. Fields that are not part of the
+// config (e.g. relation ids or ES sub-fields) are passed through as-is and
+// left for Elasticsearch to resolve.
+func validateFilters(vc *resource.VersionConfig, filters []*search.Filter) error {
+	if len(filters) == 0 {
+		return nil
+	}
+
+	caps := make(map[string]*search.FieldCapability)
+	for _, fc := range versionCapabilities(vc) {
+		caps[fc.Field] = fc
+	}
+
+	for _, f := range filters {
+		if f == nil || f.Field == "" {
+			continue
+		}
+
+		fc, ok := caps[f.Field]
+		if !ok {
+			continue
+		}
+
+		if !slices.Contains(fc.FilterOps, f.Op) {
+			return &InvalidArgumentError{Msg: fmt.Sprintf("filter op %s is not supported on %s field %q", f.Op, fc.Type, f.Field)}
+		}
+	}
+
+	return nil
+}
+
+// groupFilters returns all leaf filters of a filter group, recursing into
+// child groups.
+func groupFilters(g *search.FilterGroup) []*search.Filter {
+	filters := slices.Clone(g.Filters)
+	for _, child := range g.Groups {
+		if child != nil {
+			filters = append(filters, groupFilters(child)...)
+		}
+	}
+	return filters
+}
+
+// resolveNestedPaths fills in the nested path of filters that target a field
+// of a cardinality "many" relation and don't specify one themselves.
+func resolveNestedPaths(vc *resource.VersionConfig, filters []*search.Filter) {
+	for _, f := range filters {
+		if f == nil || f.NestedPath != "" {
+			continue
+		}
+		f.NestedPath = vc.NestedPath(f.Field)
+	}
+}
+
+// resolveGroupNestedPaths resolves the nested paths of all filters in a filter
+// tree. Nested groups already provide the nesting for their children, so
+// their filters are only checked to belong to the group's relation.
+func resolveGroupNestedPaths(vc *resource.VersionConfig, g *search.FilterGroup) error {
+	if g.NestedPath != "" {
+		return verifyNestedGroup(vc, g)
+	}
+
+	resolveNestedPaths(vc, g.Filters)
+	for _, child := range g.Groups {
+		if child == nil {
+			continue
+		}
+		if err := resolveGroupNestedPaths(vc, child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func verifyNestedGroup(vc *resource.VersionConfig, g *search.FilterGroup) error {
+	if !vc.IsNestedPath(g.NestedPath) {
+		return &InvalidArgumentError{Msg: fmt.Sprintf("filter group nested_path %q is not a cardinality \"many\" relation or a nested field", g.NestedPath)}
+	}
+
+	if slices.ContainsFunc(g.Groups, containsNestedGroup) {
+		return &InvalidArgumentError{Msg: fmt.Sprintf("filter group nested_path %q cannot contain another nested group", g.NestedPath)}
+	}
+
+	for _, f := range groupFilters(g) {
+		if f == nil || f.Field == "" {
+			continue
+		}
+		if f.NestedPath != "" {
+			return &InvalidArgumentError{Msg: fmt.Sprintf("filter on %q cannot set nested_path inside nested group %q", f.Field, g.NestedPath)}
+		}
+		if !strings.HasPrefix(f.Field, g.NestedPath+".") {
+			return &InvalidArgumentError{Msg: fmt.Sprintf("filter on %q is outside of nested group %q", f.Field, g.NestedPath)}
+		}
+		// Fields of a "many" relation or a nested field within the group's
+		// objects are nested once more and need their own nested query.
+		if np := vc.NestedPath(f.Field); np != g.NestedPath {
+			return &InvalidArgumentError{Msg: fmt.Sprintf("filter on %q belongs to nested path %q, not to nested group %q", f.Field, np, g.NestedPath)}
+		}
+	}
+
+	return nil
+}
+
+func containsNestedGroup(g *search.FilterGroup) bool {
+	if g == nil {
+		return false
+	}
+	return g.NestedPath != "" || slices.ContainsFunc(g.Groups, containsNestedGroup)
+}
@@ -0,0 +1,397 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/theleeeo/indexer/gen/search/v1"
+	"github.com/theleeeo/indexer/resource"
+)
+
+func TestSearch_UnsupportedFilterOp(t *testing.T) {
+	cfg := &resource.Config{
+		Resource: "product",
+		Versions: []resource.VersionConfig{
+			{
+				Version: 1,
+				Fields: []resource.FieldConfig{
+					{Name: "title", Type: "text"},
+					{Name: "price", Type: "double"},
+				},
+			},
+		},
+	}
+	cfg.ApplyDefaults()
+	idx := New(Config{Resources: resource.Configs{cfg}})
+
+	tests := []struct {
+		name   string
+		filter *search.Filter
+	}{
+		{"eq on text", &search.Filter{Field: "fields.title", Op: search.FilterOp_FILTER_OP_EQ, Value: "x"}},
+		{"prefix on numeric", &search.Filter{Field: "fields.price", Op: search.FilterOp_FILTER_OP_PREFIX, Value: "1"}},
+		{"unspecified op", &search.Filter{Field: "fields.price"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := idx.Search(context.Background(), &search.SearchRequest{
+				Resource: "product",
+				Filters:  []*search.Filter{tt.filter},
+			})
+
+			var invalidArg *InvalidArgumentError
+			if !errors.As(err, &invalidArg) {
+				t.Fatalf("expected InvalidArgumentError, got %v", err)
+			}
+		})
+
+		t.Run(tt.name+" in group", func(t *testing.T) {
+			_, err := idx.Search(context.Background(), &search.SearchRequest{
+				Resource: "product",
+				FilterGroup: &search.FilterGroup{
+					Op: search.GroupOp_GROUP_OP_OR,
+					Groups: []*search.FilterGroup{
+						{Op: search.GroupOp_GROUP_OP_NOT, Filters: []*search.Filter{tt.filter}},
+					},
+				},
+			})
+
+			var invalidArg *InvalidArgumentError
+			if !errors.As(err, &invalidArg) {
+				t.Fatalf("expected InvalidArgumentError, got %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateFilters(t *testing.T) {
+	vc := &resource.VersionConfig{
+		Fields: []resource.FieldConfig{
+			{Name: "status"},
+			{Name: "price", Type: "double"},
+		},
+		Relations: []resource.RelationConfig{
+			{Resource: "customer", Fields: []resource.FieldConfig{{Name: "name", Type: "text"}}},
+		},
+	}
+
+	err := validateFilters(vc, []*search.Filter{
+		{Field: "fields.status", Op: search.FilterOp_FILTER_OP_NOT_IN, Values: []string{"archived"}},
+		{Field: "fields.price", Op: search.FilterOp_FILTER_OP_RANGE, Range: &search.RangeBounds{Gte: "10", Lte: "50"}},
+		{Field: "customer.name", Op: search.FilterOp_FILTER_OP_EXISTS},
+		// Unknown fields are left for ES to resolve.
+		{Field: "customer.id", Op: search.FilterOp_FILTER_OP_EQ, Value: "c1"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	err = validateFilters(vc, []*search.Filter{
+		{Field: "customer.name", Op: search.FilterOp_FILTER_OP_RANGE, Range: &search.RangeBounds{Gt: "a"}},
+	})
+	var invalidArg *InvalidArgumentError
+	if !errors.As(err, &invalidArg) {
+		t.Fatalf("expected InvalidArgumentError, got %v", err)
+	}
+	if invalidArg.Msg != `filter op FILTER_OP_RANGE is not supported on text field "customer.name"` {
+		t.Fatalf("unexpected message: %q", invalidArg.Msg)
+	}
+}
+
+func nestedTestVersion() *resource.VersionConfig {
+	return &resource.VersionConfig{
+		Fields: []resource.FieldConfig{{Name: "status"}},
+		Relations: []resource.RelationConfig{
+			{Resource: "c", Cardinality: "many", Fields: []resource.FieldConfig{{Name: "state"}, {Name: "number", Type: "integer"}}},
+			{Resource: "b", Cardinality: "one", Fields: []resource.FieldConfig{{Name: "name"}}},
+		},
+	}
+}
+
+func TestResolveNestedPaths(t *testing.T) {
+	vc := nestedTestVersion()
+
+	filters := []*search.Filter{
+		{Field: "fields.status", Op: search.FilterOp_FILTER_OP_EQ, Value: "open"},
+		{Field: "c.state", Op: search.FilterOp_FILTER_OP_EQ, Value: "active"},
+		{Field: "b.name", Op: search.FilterOp_FILTER_OP_EQ, Value: "x"},
+		{Field: "c.number", Op: search.FilterOp_FILTER_OP_EQ, Value: "1", NestedPath: "explicit"},
+	}
+	group := &search.FilterGroup{
+		Groups: []*search.FilterGroup{
+			{Filters: []*search.Filter{{Field: "c.number", Op: search.FilterOp_FILTER_OP_EQ, Value: "5"}}},
+		},
+	}
+
+	resolveNestedPaths(vc, filters)
+	if err := resolveGroupNestedPaths(vc, group); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	wantPaths := []string{"", "c", "", "explicit"}
+	for i, want := range wantPaths {
+		if filters[i].NestedPath != want {
+			t.Errorf("filter %d (%s): nested path got %q, want %q", i, filters[i].Field, filters[i].NestedPath, want)
+		}
+	}
+	if got := group.Groups[0].Filters[0].NestedPath; got != "c" {
+		t.Errorf("group filter: nested path got %q, want %q", got, "c")
+	}
+}
+
+func TestResolveGroupNestedPaths_NestedGroup(t *testing.T) {
+	vc := nestedTestVersion()
+
+	group := &search.FilterGroup{
+		NestedPath: "c",
+		Filters: []*search.Filter{
+			{Field: "c.state", Op: search.FilterOp_FILTER_OP_EQ, Value: "active"},
+			{Field: "c.number", Op: search.FilterOp_FILTER_OP_EQ, Value: "5"},
+		},
+	}
+	if err := resolveGroupNestedPaths(vc, group); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, f := range group.Filters {
+		if f.NestedPath != "" {
+			t.Errorf("filter %s inside nested group should not get a nested path, got %q", f.Field, f.NestedPath)
+		}
+	}
+
+	invalid := []struct {
+		name    string
+		group   *search.FilterGroup
+		wantMsg string
+	}{
+		{
+			name:    "not a many relation",
+			group:   &search.FilterGroup{NestedPath: "b", Filters: []*search.Filter{{Field: "b.name"}}},
+			wantMsg: `filter group nested_path "b" is not a cardinality "many" relation or a nested field`,
+		},
+		{
+			name:    "field outside of group",
+			group:   &search.FilterGroup{NestedPath: "c", Filters: []*search.Filter{{Field: "fields.status"}}},
+			wantMsg: `filter on "fields.status" is outside of nested group "c"`,
+		},
+		{
+			name:    "filter with own nested path",
+			group:   &search.FilterGroup{NestedPath: "c", Filters: []*search.Filter{{Field: "c.state", NestedPath: "c"}}},
+			wantMsg: `filter on "c.state" cannot set nested_path inside nested group "c"`,
+		},
+		{
+			name: "nested group in nested group",
+			group: &search.FilterGroup{NestedPath: "c", Groups: []*search.FilterGroup{
+				{Groups: []*search.FilterGroup{{NestedPath: "c"}}},
+			}},
+			wantMsg: `filter group nested_path "c" cannot contain another nested group`,
+		},
+	}
+
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			err := resolveGroupNestedPaths(vc, tt.group)
+			var invalidArg *InvalidArgumentError
+			if !errors.As(err, &invalidArg) {
+				t.Fatalf("expected InvalidArgumentError, got %v", err)
+			}
+			if invalidArg.Msg != tt.wantMsg {
+				t.Fatalf("unexpected message: %q", invalidArg.Msg)
+			}
+		})
+	}
+}
+
+func TestSearch_PageWithPageToken(t *testing.T) {
+	idx := New(Config{Resources: testResources()})
+
+	_, err := idx.Search(context.Background(), &search.SearchRequest{
+		Resource:  "product",
+		Page:      2,
+		PageToken: "token",
+	})
+
+	var invalidArg *InvalidArgumentError
+	if !errors.As(err, &invalidArg) {
+		t.Fatalf("expected InvalidArgumentError, got %v", err)
+	}
+	if invalidArg.Msg != "page cannot be combined with page_token" {
+		t.Fatalf("unexpected message: %q", invalidArg.Msg)
+	}
+}
+
+func TestValidateSourceFields(t *testing.T) {
+	vc := &resource.VersionConfig{
+		Fields: []resource.FieldConfig{{Name: "title", Type: "text"}},
+		Relations: []resource.RelationConfig{
+			{Resource: "b", Fields: []resource.FieldConfig{{Name: "name"}}},
+		},
+	}
+
+	valid := []string{"id", "fields", "fields.title", "b", "b.name"}
+	if err := validateSourceFields(vc, valid); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, field := range []string{"fields.missing", "c", "b.missing", ""} {
+		err := validateSourceFields(vc, []string{field})
+		var invalidArg *InvalidArgumentError
+		if !errors.As(err, &invalidArg) {
+			t.Fatalf("expected InvalidArgumentError for %q, got %v", field, err)
+		}
+	}
+}
+
+func TestSearch_UnknownSourceField(t *testing.T) {
+	idx := New(Config{Resources: testResources()})
+
+	_, err := idx.Search(context.Background(), &search.SearchRequest{
+		Resource: "product",
+		Fields:   []string{"fields.title", "fields.price"},
+	})
+
+	var invalidArg *InvalidArgumentError
+	if !errors.As(err, &invalidArg) {
+		t.Fatalf("expected InvalidArgumentError, got %v", err)
+	}
+	if invalidArg.Msg != `unknown source field "fields.price"` {
+		t.Fatalf("unexpected message: %q", invalidArg.Msg)
+	}
+}
+
+func TestResolveHighlight(t *testing.T) {
+	vc := &resource.VersionConfig{
+		Fields: []resource.FieldConfig{
+			{Name: "title", Type: "text", Query: resource.QueryConfig{Boost: 2}},
+			{Name: "secret", Query: resource.QueryConfig{Search: new(false)}},
+		},
+		Relations: []resource.RelationConfig{
+			{Resource: "b", Fields: []resource.FieldConfig{{Name: "name", Type: "text"}}},
+		},
+	}
+
+	t.Run("defaults to searchable fields", func(t *testing.T) {
+		h := &search.Highlight{}
+		if err := resolveHighlight(vc, h); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !slices.Equal(h.Fields, []string{"fields.title", "b.name"}) {
+			t.Fatalf("unexpected fields: %v", h.Fields)
+		}
+	})
+
+	t.Run("explicit fields", func(t *testing.T) {
+		h := &search.Highlight{Fields: []string{"fields.secret"}}
+		if err := resolveHighlight(vc, h); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	invalid := []struct {
+		name string
+		h    *search.Highlight
+	}{
+		{"unknown field", &search.Highlight{Fields: []string{"fields.missing"}}},
+		{"negative fragment size", &search.Highlight{FragmentSize: -1}},
+		{"negative number of fragments", &search.Highlight{NumberOfFragments: new(int32(-1))}},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			err := resolveHighlight(vc, tt.h)
+			var invalidArg *InvalidArgumentError
+			if !errors.As(err, &invalidArg) {
+				t.Fatalf("expected InvalidArgumentError, got %v", err)
+			}
+		})
+	}
+}
+
+func TestResolveNestedPaths_RelationsOfRelations(t *testing.T) {
+	vc := &resource.VersionConfig{
+		Relations: []resource.RelationConfig{
+			{
+				Resource: "lines",
+				Fields:   []resource.FieldConfig{{Name: "quantity", Type: "integer"}},
+				Relations: []resource.RelationConfig{
+					{Resource: "product", Cardinality: "one", Fields: []resource.FieldConfig{{Name: "name"}}},
+					{Resource: "tags", Fields: []resource.FieldConfig{{Name: "label"}}},
+				},
+			},
+		},
+	}
+
+	filters := []*search.Filter{
+		{Field: "lines.quantity", Op: search.FilterOp_FILTER_OP_EQ, Value: "1"},
+		{Field: "lines.product.name", Op: search.FilterOp_FILTER_OP_EQ, Value: "x"},
+		{Field: "lines.tags.label", Op: search.FilterOp_FILTER_OP_EQ, Value: "y"},
+	}
+	resolveNestedPaths(vc, filters)
+
+	for i, want := range []string{"lines", "lines", "lines.tags"} {
+		if filters[i].NestedPath != want {
+			t.Errorf("filter %s: nested path got %q, want %q", filters[i].Field, filters[i].NestedPath, want)
+		}
+	}
+
+	err := resolveGroupNestedPaths(vc, &search.FilterGroup{
+		NestedPath: "lines",
+		Filters:    []*search.Filter{{Field: "lines.tags.label", Op: search.FilterOp_FILTER_OP_EQ, Value: "y"}},
+	})
+	var invalidArg *InvalidArgumentError
+	if !errors.As(err, &invalidArg) {
+		t.Fatalf("expected InvalidArgumentError, got %v", err)
+	}
+	if invalidArg.Msg != `filter on "lines.tags.label" belongs to nested path "lines.tags", not to nested group "lines"` {
+		t.Fatalf("unexpected message: %q", invalidArg.Msg)
+	}
+}
+
+func TestResolveNestedPaths_ObjectFields(t *testing.T) {
+	vc := &resource.VersionConfig{
+		Fields: []resource.FieldConfig{
+			{Name: "address", Type: "object", Fields: []resource.FieldConfig{{Name: "city"}}},
+			{Name: "items", Type: "nested", Fields: []resource.FieldConfig{
+				{Name: "sku"},
+				{Name: "options", Type: "nested", Fields: []resource.FieldConfig{{Name: "value"}}},
+			}},
+		},
+		Relations: []resource.RelationConfig{
+			{Resource: "c", Fields: []resource.FieldConfig{
+				{Name: "meta", Type: "object", Fields: []resource.FieldConfig{{Name: "color"}}},
+			}},
+		},
+	}
+
+	filters := []*search.Filter{
+		{Field: "fields.address.city", Op: search.FilterOp_FILTER_OP_EQ, Value: "London"},
+		{Field: "fields.items.sku", Op: search.FilterOp_FILTER_OP_EQ, Value: "a"},
+		{Field: "fields.items.options.value", Op: search.FilterOp_FILTER_OP_EQ, Value: "red"},
+		{Field: "c.meta.color", Op: search.FilterOp_FILTER_OP_EQ, Value: "red"},
+	}
+	resolveNestedPaths(vc, filters)
+
+	for i, want := range []string{"", "fields.items", "fields.items.options", "c"} {
+		if filters[i].NestedPath != want {
+			t.Errorf("filter %s: nested path got %q, want %q", filters[i].Field, filters[i].NestedPath, want)
+		}
+	}
+
+	err := resolveGroupNestedPaths(vc, &search.FilterGroup{
+		NestedPath: "fields.items",
+		Filters:    []*search.Filter{{Field: "fields.items.sku", Op: search.FilterOp_FILTER_OP_EQ, Value: "a"}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	err = resolveGroupNestedPaths(vc, &search.FilterGroup{
+		NestedPath: "fields.address",
+		Filters:    []*search.Filter{{Field: "fields.address.city", Op: search.FilterOp_FILTER_OP_EQ, Value: "London"}},
+	})
+	var invalidArg *InvalidArgumentError
+	if !errors.As(err, &invalidArg) {
+		t.Fatalf("expected InvalidArgumentError, got %v", err)
+	}
+}
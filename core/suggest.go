@@ -0,0 +1,58 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/theleeeo/indexer/es"
+	"github.com/theleeeo/indexer/gen/search/v1"
+)
+
+// Suggest returns type-ahead completions for the prefix of the request from
+// the suggest fields of the given resource.
+func (idx *Indexer) Suggest(ctx context.Context, req *search.SuggestRequest) (*search.SuggestResponse, error) {
+	if req.Resource == "" {
+		return nil, errors.New("resource is required")
+	}
+
+	r := idx.resources.Get(req.Resource)
+	if r == nil {
+		return nil, ErrUnknownResource
+	}
+
+	if req.Prefix == "" {
+		return nil, &InvalidArgumentError{Msg: "prefix is required"}
+	}
+
+	if req.Size <= 0 {
+		req.Size = 10
+	}
+
+	if req.Size > 50 {
+		req.Size = 50
+	}
+
+	vc := r.ReadVersionConfig()
+
+	suggestFields := vc.GetSuggestFields()
+	if len(req.Fields) == 0 {
+		if len(suggestFields) == 0 {
+			return nil, &InvalidArgumentError{Msg: fmt.Sprintf("resource %q has no suggest fields", req.Resource)}
+		}
+		req.Fields = suggestFields
+	}
+
+	for _, f := range req.Fields {
+		if !slices.Contains(suggestFields, f) {
+			return nil, &InvalidArgumentError{Msg: fmt.Sprintf("field %q does not support suggest", f)}
+		}
+	}
+
+	if err := prepareFilters(vc, req.Filters, req.FilterGroup); err != nil {
+		return nil, err
+	}
+
+	return idx.es.Suggest(ctx, req, es.AliasName(r.Resource))
+}
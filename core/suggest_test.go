@@ -0,0 +1,69 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/theleeeo/indexer/gen/search/v1"
+	"github.com/theleeeo/indexer/resource"
+)
+
+func TestSuggest_InvalidRequest(t *testing.T) {
+	cfg := &resource.Config{
+		Resource: "product",
+		Versions: []resource.VersionConfig{
+			{
+				Version: 1,
+				Fields: []resource.FieldConfig{
+					{Name: "title", Type: "text", Suggest: true},
+					{Name: "sku"},
+				},
+			},
+		},
+	}
+	cfg.ApplyDefaults()
+	idx := New(Config{Resources: resource.Configs{cfg}})
+
+	tests := []struct {
+		name string
+		req  *search.SuggestRequest
+		msg  string
+	}{
+		{"missing prefix", &search.SuggestRequest{Resource: "product"}, "prefix is required"},
+		{"field without suggest", &search.SuggestRequest{Resource: "product", Prefix: "a", Fields: []string{"fields.sku"}}, `field "fields.sku" does not support suggest`},
+		{"unsupported filter op", &search.SuggestRequest{
+			Resource: "product",
+			Prefix:   "a",
+			Filters:  []*search.Filter{{Field: "fields.title", Op: search.FilterOp_FILTER_OP_EQ, Value: "x"}},
+		}, `filter op FILTER_OP_EQ is not supported on text field "fields.title"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := idx.Suggest(context.Background(), tt.req)
+
+			var invalidArg *InvalidArgumentError
+			if !errors.As(err, &invalidArg) {
+				t.Fatalf("expected InvalidArgumentError, got %v", err)
+			}
+			if invalidArg.Msg != tt.msg {
+				t.Fatalf("unexpected message: %q", invalidArg.Msg)
+			}
+		})
+	}
+}
+
+func TestSuggest_NoSuggestFields(t *testing.T) {
+	idx := New(Config{Resources: testResources()})
+
+	_, err := idx.Suggest(context.Background(), &search.SuggestRequest{Resource: "product", Prefix: "a"})
+
+	var invalidArg *InvalidArgumentError
+	if !errors.As(err, &invalidArg) {
+		t.Fatalf("expected InvalidArgumentError, got %v", err)
+	}
+	if invalidArg.Msg != `resource "product" has no suggest fields` {
+		t.Fatalf("unexpected message: %q", invalidArg.Msg)
+	}
+}
@@ -0,0 +1,168 @@
+package dsl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+
+	"github.com/theleeeo/indexer/projection"
+	"github.com/theleeeo/indexer/source"
+)
+
+// fetchChildren fetches the relations of a relation for each of its items,
+// recursively. parents[i] is the document items[i] is embedded in. Keys are
+// taken from the item itself, reverse relations match the item's ID. Each
+// relation is fetched for all items at once.
+func fetchChildren(
+	ctx context.Context,
+	provider source.Provider,
+	parents []projection.BuildDoc,
+	rels []*plannedRelation,
+	items []map[string]any,
+) ([]map[string]*fetchedRelation, error) {
+	if len(rels) == 0 {
+		return nil, nil
+	}
+
+	children := make([]map[string]*fetchedRelation, len(items))
+	for i := range items {
+		children[i] = make(map[string]*fetchedRelation, len(rels))
+	}
+	for _, rel := range rels {
+		frs, err := fetchChild(ctx, provider, parents, rel, items)
+		if err != nil {
+			return nil, err
+		}
+		for i, fr := range frs {
+			children[i][rel.Name()] = fr
+		}
+	}
+
+	return children, nil
+}
+
+// fetchChild fetches a relation for each of items.
+func fetchChild(
+	ctx context.Context,
+	provider source.Provider,
+	parents []projection.BuildDoc,
+	rel *plannedRelation,
+	items []map[string]any,
+) ([]*fetchedRelation, error) {
+	keys := make([][]source.ResourceKey, len(items))
+	for i, item := range items {
+		if rel.Reverse != nil {
+			if id, ok := source.FormatKeyValue(item["id"]); ok {
+				keys[i] = []source.ResourceKey{source.NewResourceKey(source.KeyPart{Field: rel.Reverse.Field, Value: id})}
+			}
+			continue
+		}
+		var err error
+		if keys[i], err = relationKeys(rel.Key, []map[string]any{item}); err != nil {
+			return nil, fmt.Errorf("extract key of %s: %w", rel.Resource, err)
+		}
+	}
+
+	related, err := fetchByItemKeys(ctx, provider, parents, rel, keys)
+	if err != nil {
+		return nil, fmt.Errorf("fetch related %s: %w", rel.Resource, err)
+	}
+
+	return newFetchedRelations(ctx, provider, parents, rel, related)
+}
+
+// fetchByItemKeys returns the related resources of the keys of each item. They
+// are fetched in one call if the provider implements source.BatchProvider,
+// otherwise key by key.
+func fetchByItemKeys(
+	ctx context.Context,
+	provider source.Provider,
+	parents []projection.BuildDoc,
+	rel *plannedRelation,
+	keys [][]source.ResourceKey,
+) ([][]map[string]any, error) {
+	if bp, ok := provider.(source.BatchProvider); ok {
+		related, err := fetchRelatedBatch(ctx, bp, parents, rel, keys)
+		if !errors.Is(err, source.ErrBatchUnsupported) {
+			return related, err
+		}
+	}
+
+	related := make([][]map[string]any, len(keys))
+	hints := relationHints(rel.RelationConfig)
+	for i, itemKeys := range keys {
+		if len(itemKeys) == 0 {
+			continue
+		}
+		var err error
+		if related[i], err = fetchByKeys(ctx, provider, parents[i], rel.Resource, itemKeys, hints); err != nil {
+			return nil, err
+		}
+	}
+	return related, nil
+}
+
+// fetchRelatedBatch returns the related resources of keys[i] for each
+// parents[i] with one FetchRelatedBatch call per distinct parent metadata.
+func fetchRelatedBatch(
+	ctx context.Context,
+	bp source.BatchProvider,
+	parents []projection.BuildDoc,
+	rel *plannedRelation,
+	keys [][]source.ResourceKey,
+) ([][]map[string]any, error) {
+	related := make([][]map[string]any, len(keys))
+	for _, group := range groupByMetadata(parents, keys) {
+		var lookups []source.RelatedLookup
+		for _, i := range group {
+			for _, key := range keys[i] {
+				lookups = append(lookups, source.RelatedLookup{
+					RootResource: source.RootResource{Type: parents[i].Root.Type, Id: parents[i].Root.Id},
+					Key:          key,
+				})
+			}
+		}
+
+		resp, err := bp.FetchRelatedBatch(ctx, source.FetchRelatedBatchParams{
+			ResourceType: rel.Resource,
+			Lookups:      lookups,
+			Metadata:     parents[group[0]].Metadata,
+			Hints:        relationHints(rel.RelationConfig),
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(resp.Related) != len(lookups) {
+			return nil, fmt.Errorf("got %d results for %d lookups", len(resp.Related), len(lookups))
+		}
+
+		byKey := resp.Related
+		for _, i := range group {
+			related[i] = mergeRelated(byKey[:len(keys[i])])
+			byKey = byKey[len(keys[i]):]
+		}
+	}
+	return related, nil
+}
+
+// groupByMetadata groups the indexes of the parents that have keys by their
+// metadata, in order of first appearance. The metadata is sent once per
+// batch, so parents with different metadata cannot share one.
+func groupByMetadata(parents []projection.BuildDoc, keys [][]source.ResourceKey) [][]int {
+	var groups [][]int
+next:
+	for i := range parents {
+		if len(keys[i]) == 0 {
+			continue
+		}
+		for g, group := range groups {
+			if maps.Equal(parents[group[0]].Metadata, parents[i].Metadata) {
+				groups[g] = append(group, i)
+				continue next
+			}
+		}
+		groups = append(groups, []int{i})
+	}
+	return groups
+}
@@ -0,0 +1,347 @@
+package dsl
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/theleeeo/indexer/resource"
+)
+
+// defaultDateFormats are the accepted formats of date fields without formats,
+// the default formats of an ES date field.
+var defaultDateFormats = []string{
+	resource.DateFormatStrictDateOptionalTime,
+	resource.DateFormatEpochMillis,
+}
+
+// strictDateOptionalTimeLayouts are the Go layouts of the ES format
+// strict_date_optional_time: a date, optionally with a time of day, which is
+// optionally followed by a zone offset. Fractions of seconds are accepted by
+// time.Parse after the seconds.
+var strictDateOptionalTimeLayouts = func() []string {
+	layouts := []string{"2006", "2006-01", "2006-01-02"}
+	for _, t := range []string{"2006-01-02T15", "2006-01-02T15:04", "2006-01-02T15:04:05"} {
+		for _, zone := range []string{"", "Z07:00", "Z0700", "Z07"} {
+			layouts = append(layouts, t+zone)
+		}
+	}
+	return layouts
+}()
+
+// integerRanges holds the value ranges of the signed ES integer types.
+// unsigned_long is checked separately.
+var integerRanges = map[string][2]int64{
+	"byte":    {math.MinInt8, math.MaxInt8},
+	"short":   {math.MinInt16, math.MaxInt16},
+	"integer": {math.MinInt32, math.MaxInt32},
+	"long":    {math.MinInt64, math.MaxInt64},
+}
+
+// coerce converts a provider value to the ES type of its field, e.g. "12" to
+// 12 for an integer field or "yes" to true for a boolean field. The elements
+// of arrays are converted one by one. Null and values of types without a
+// conversion are returned as is, and a blank string for a boolean is null.
+func coerce(f resource.FieldConfig, v any) (any, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	// A geo_point may be given as a [lon, lat] array.
+	if arr, ok := v.([]any); ok && (f.ESType() != "geo_point" || !isLonLat(arr)) {
+		out := make([]any, len(arr))
+		for i, item := range arr {
+			c, err := coerce(f, item)
+			if err != nil {
+				return nil, fmt.Errorf("element %d: %w", i, err)
+			}
+			out[i] = c
+		}
+		return out, nil
+	}
+
+	switch t := f.ESType(); t {
+	case "byte", "short", "integer", "long", "unsigned_long":
+		return coerceInteger(t, v)
+	case "double", "float", "half_float", "scaled_float":
+		return coerceFloat(v)
+	case "boolean":
+		return coerceBool(v)
+	case "date", "date_nanos":
+		formats := f.Formats
+		if len(formats) == 0 {
+			formats = defaultDateFormats
+		}
+		return coerceDate(formats, v)
+	case "geo_point":
+		return coerceGeoPoint(v)
+	default:
+		return v, nil
+	}
+}
+
+// coerceInteger converts to int64, or uint64 for unsigned_long. Integers and
+// integer strings are converted exactly; floats, and strings such as "12.0",
+// only if they are whole numbers.
+func coerceInteger(esType string, v any) (any, error) {
+	if esType == "unsigned_long" {
+		return coerceUnsigned(v)
+	}
+
+	var n int64
+	switch v := v.(type) {
+	case int:
+		n = int64(v)
+	case int32:
+		n = int64(v)
+	case int64:
+		n = v
+	case uint64:
+		if v > math.MaxInt64 {
+			return nil, fmt.Errorf("%d is out of range", v)
+		}
+		n = int64(v)
+	case string:
+		i, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+		if errors.Is(err, strconv.ErrRange) {
+			return nil, fmt.Errorf("%s is out of range", strings.TrimSpace(v))
+		}
+		if err != nil {
+			return coerceWholeFloat(esType, v)
+		}
+		n = i
+	default:
+		return coerceWholeFloat(esType, v)
+	}
+
+	r := integerRanges[esType]
+	if n < r[0] || n > r[1] {
+		return nil, fmt.Errorf("%d is out of range", n)
+	}
+	return n, nil
+}
+
+func coerceUnsigned(v any) (any, error) {
+	switch v := v.(type) {
+	case int:
+		return unsignedFromInt(int64(v))
+	case int32:
+		return unsignedFromInt(int64(v))
+	case int64:
+		return unsignedFromInt(v)
+	case uint64:
+		return v, nil
+	case string:
+		u, err := strconv.ParseUint(strings.TrimSpace(v), 10, 64)
+		if errors.Is(err, strconv.ErrRange) {
+			return nil, fmt.Errorf("%s is out of range", strings.TrimSpace(v))
+		}
+		if err != nil {
+			return coerceWholeFloat("unsigned_long", v)
+		}
+		return u, nil
+	default:
+		return coerceWholeFloat("unsigned_long", v)
+	}
+}
+
+func unsignedFromInt(n int64) (any, error) {
+	if n < 0 {
+		return nil, fmt.Errorf("%d is out of range", n)
+	}
+	return uint64(n), nil
+}
+
+// coerceWholeFloat converts a whole number given as a float, or as a string
+// such as "12.0" or "1e3", to the integer type.
+func coerceWholeFloat(esType string, v any) (any, error) {
+	f, err := coerceFloat(v)
+	if err != nil {
+		return nil, err
+	}
+	n := f.(float64)
+	if n != math.Trunc(n) {
+		return nil, fmt.Errorf("%v is not a whole number", n)
+	}
+
+	// Checked against the bounds as floats before converting: 2^63 and 2^64
+	// are the smallest floats out of range, and converting a float out of
+	// range is undefined.
+	if esType == "unsigned_long" {
+		if n < 0 || n >= 1<<64 {
+			return nil, fmt.Errorf("%v is out of range", n)
+		}
+		return uint64(n), nil
+	}
+	if n < math.MinInt64 || n >= 1<<63 {
+		return nil, fmt.Errorf("%v is out of range", n)
+	}
+	i := int64(n)
+	r := integerRanges[esType]
+	if i < r[0] || i > r[1] {
+		return nil, fmt.Errorf("%v is out of range", n)
+	}
+	return i, nil
+}
+
+func coerceFloat(v any) (any, error) {
+	switch v := v.(type) {
+	case float64:
+		return v, nil
+	case float32:
+		return float64(v), nil
+	case int:
+		return float64(v), nil
+	case int32:
+		return float64(v), nil
+	case int64:
+		return float64(v), nil
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+			return nil, errors.New("not a number")
+		}
+		return f, nil
+	default:
+		return nil, fmt.Errorf("unexpected %T", v)
+	}
+}
+
+func coerceBool(v any) (any, error) {
+	switch v := v.(type) {
+	case bool:
+		return v, nil
+	case string:
+		switch strings.ToLower(strings.TrimSpace(v)) {
+		case "true", "1", "yes", "y", "on":
+			return true, nil
+		case "false", "0", "no", "n", "off":
+			return false, nil
+		case "":
+			// Blank provider data is missing, not false.
+			return nil, nil
+		}
+		return nil, errors.New("not a boolean")
+	default:
+		f, err := coerceFloat(v)
+		if err != nil {
+			return nil, err
+		}
+		switch f.(float64) {
+		case 0:
+			return false, nil
+		case 1:
+			return true, nil
+		}
+		return nil, errors.New("not a boolean")
+	}
+}
+
+// coerceDate parses a date in any of the formats and returns it in RFC 3339
+// in UTC.
+// coerceDate checks that a date matches one of the formats. Dates in a format
+// ES accepts by default are returned as is, others as RFC 3339, and epoch
+// seconds as epoch millis.
+func coerceDate(formats []string, v any) (any, error) {
+	for _, format := range formats {
+		t, ok := parseDate(format, v)
+		if !ok {
+			continue
+		}
+		switch format {
+		case resource.DateFormatStrictDateOptionalTime, resource.DateFormatRFC3339, resource.DateFormatEpochMillis:
+			return v, nil
+		case resource.DateFormatEpochSecond:
+			return t.UnixMilli(), nil
+		default:
+			return t.Format(time.RFC3339Nano), nil
+		}
+	}
+	return nil, fmt.Errorf("does not match any of the formats %s", strings.Join(formats, ", "))
+}
+
+func parseDate(format string, v any) (time.Time, bool) {
+	switch format {
+	case resource.DateFormatEpochMillis, resource.DateFormatEpochSecond:
+		f, err := coerceFloat(v)
+		if err != nil {
+			return time.Time{}, false
+		}
+		n := f.(float64)
+		if format == resource.DateFormatEpochSecond {
+			n *= 1000
+		}
+		return time.UnixMilli(int64(n)), true
+	case resource.DateFormatStrictDateOptionalTime:
+		for _, layout := range strictDateOptionalTimeLayouts {
+			if t, ok := parseDate(layout, v); ok {
+				return t, true
+			}
+		}
+		return time.Time{}, false
+	case resource.DateFormatRFC3339:
+		format = time.RFC3339Nano
+	}
+
+	s, ok := v.(string)
+	if !ok {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(format, strings.TrimSpace(s))
+	return t, err == nil
+}
+
+// coerceGeoPoint accepts an object with lat and lon (or latitude and
+// longitude), a "lat,lon" string or a [lon, lat] array, as in GeoJSON, and
+// returns an object with lat and lon.
+func coerceGeoPoint(v any) (any, error) {
+	var lat, lon any
+	switch v := v.(type) {
+	case map[string]any:
+		lat, lon = v["lat"], v["lon"]
+		if lat == nil && lon == nil {
+			lat, lon = v["latitude"], v["longitude"]
+		}
+	case string:
+		a, b, ok := strings.Cut(v, ",")
+		if !ok {
+			return nil, errors.New(`expected "lat,lon"`)
+		}
+		lat, lon = a, b
+	case []any:
+		lon, lat = v[0], v[1]
+	default:
+		return nil, fmt.Errorf("unexpected %T", v)
+	}
+	if lat == nil || lon == nil {
+		return nil, errors.New("lat and lon required")
+	}
+
+	latF, err := coerceFloat(lat)
+	if err != nil {
+		return nil, fmt.Errorf("lat: %w", err)
+	}
+	lonF, err := coerceFloat(lon)
+	if err != nil {
+		return nil, fmt.Errorf("lon: %w", err)
+	}
+	if math.Abs(latF.(float64)) > 90 || math.Abs(lonF.(float64)) > 180 {
+		return nil, errors.New("lat or lon is out of range")
+	}
+	return map[string]any{"lat": latF, "lon": lonF}, nil
+}
+
+func isLonLat(arr []any) bool {
+	if len(arr) != 2 {
+		return false
+	}
+	for _, v := range arr {
+		if _, err := coerceFloat(v); err != nil {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,116 @@
+package dsl
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/theleeeo/indexer/projection"
+	"github.com/theleeeo/indexer/resource"
+)
+
+func TestCoerce(t *testing.T) {
+	tests := []struct {
+		name  string
+		field resource.FieldConfig
+		value any
+		want  any
+	}{
+		{"integer from string", resource.FieldConfig{Type: "integer"}, "12", int64(12)},
+		{"integer from float", resource.FieldConfig{Type: "long"}, 12.0, int64(12)},
+		{"integer array", resource.FieldConfig{Type: "integer"}, []any{"1", 2.0}, []any{int64(1), int64(2)}},
+		{"integer from whole float string", resource.FieldConfig{Type: "integer"}, "12.0", int64(12)},
+		{"long beyond float precision", resource.FieldConfig{Type: "long"}, "9007199254740993", int64(9007199254740993)},
+		{"long from int64", resource.FieldConfig{Type: "long"}, int64(9007199254740993), int64(9007199254740993)},
+		{"long max", resource.FieldConfig{Type: "long"}, "9223372036854775807", int64(math.MaxInt64)},
+		{"unsigned_long max", resource.FieldConfig{Type: "unsigned_long"}, "18446744073709551615", uint64(math.MaxUint64)},
+		{"unsigned_long from float", resource.FieldConfig{Type: "unsigned_long"}, 12.0, uint64(12)},
+		{"double from string", resource.FieldConfig{Type: "double"}, " 1.5 ", 1.5},
+		{"double from int", resource.FieldConfig{Type: "float"}, 3, 3.0},
+		{"boolean from string", resource.FieldConfig{Type: "boolean"}, "Yes", true},
+		{"boolean from number", resource.FieldConfig{Type: "boolean"}, 0.0, false},
+		{"boolean from blank string", resource.FieldConfig{Type: "boolean"}, " ", nil},
+		{"date rfc3339", resource.FieldConfig{Type: "date"}, "2024-05-17T15:45:00+02:00", "2024-05-17T15:45:00+02:00"},
+		{"date only", resource.FieldConfig{Type: "date"}, "2024-05-17", "2024-05-17"},
+		{"date without seconds", resource.FieldConfig{Type: "date"}, "2024-01-02T10:00", "2024-01-02T10:00"},
+		{"date with fraction and offset", resource.FieldConfig{Type: "date"}, "2024-01-02T10:00:00.123+0100", "2024-01-02T10:00:00.123+0100"},
+		{"date year and month", resource.FieldConfig{Type: "date"}, "2024-01", "2024-01"},
+		{"date epoch millis", resource.FieldConfig{Type: "date"}, 1715953500000.0, 1715953500000.0},
+		{
+			"date custom format",
+			resource.FieldConfig{Type: "date", Formats: []string{"02/01/2006", resource.DateFormatEpochSecond}},
+			"17/05/2024", "2024-05-17T00:00:00Z",
+		},
+		{
+			"date epoch seconds",
+			resource.FieldConfig{Type: "date", Formats: []string{resource.DateFormatEpochSecond}},
+			"1715953500", int64(1715953500000),
+		},
+		{"geo_point object", resource.FieldConfig{Type: "geo_point"}, map[string]any{"lat": "51.5", "lon": -0.1}, map[string]any{"lat": 51.5, "lon": -0.1}},
+		{"geo_point latitude", resource.FieldConfig{Type: "geo_point"}, map[string]any{"latitude": 51.5, "longitude": -0.1}, map[string]any{"lat": 51.5, "lon": -0.1}},
+		{"geo_point string", resource.FieldConfig{Type: "geo_point"}, "51.5, -0.1", map[string]any{"lat": 51.5, "lon": -0.1}},
+		{"geo_point lon lat", resource.FieldConfig{Type: "geo_point"}, []any{-0.1, 51.5}, map[string]any{"lat": 51.5, "lon": -0.1}},
+		{"keyword unchanged", resource.FieldConfig{}, 12.0, 12.0},
+		{"null", resource.FieldConfig{Type: "integer"}, nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := coerce(tt.field, tt.value)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCoerce_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		field   resource.FieldConfig
+		value   any
+		wantErr string
+	}{
+		{"not a number", resource.FieldConfig{Type: "integer"}, "twelve", "not a number"},
+		{"fraction", resource.FieldConfig{Type: "integer"}, 1.5, "1.5 is not a whole number"},
+		{"out of range", resource.FieldConfig{Type: "byte"}, 300.0, "300 is out of range"},
+		{"out of range string", resource.FieldConfig{Type: "byte"}, "300", "300 is out of range"},
+		{"long overflow", resource.FieldConfig{Type: "long"}, "9223372036854775808", "9223372036854775808 is out of range"},
+		{"long overflow float", resource.FieldConfig{Type: "long"}, 9223372036854775807.0, "9.223372036854776e+18 is out of range"},
+		{"unsigned_long overflow", resource.FieldConfig{Type: "unsigned_long"}, "18446744073709551616", "18446744073709551616 is out of range"},
+		{"unsigned_long negative", resource.FieldConfig{Type: "unsigned_long"}, "-1", "-1 is out of range"},
+		{"array element", resource.FieldConfig{Type: "integer"}, []any{1.0, "x"}, "element 1: not a number"},
+		{"not a boolean", resource.FieldConfig{Type: "boolean"}, "maybe", "not a boolean"},
+		{"bad date", resource.FieldConfig{Type: "date", Formats: []string{"2006-01-02"}}, "17/05/2024", "does not match any of the formats 2006-01-02"},
+		{"geo_point missing lon", resource.FieldConfig{Type: "geo_point"}, map[string]any{"lat": 1.0}, "lat and lon required"},
+		{"geo_point out of range", resource.FieldConfig{Type: "geo_point"}, "91,0", "lat or lon is out of range"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := coerce(tt.field, tt.value)
+			require.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestFilterFields_CoercionPolicy(t *testing.T) {
+	data := map[string]any{"age": "unknown", "score": "7"}
+	fields := []resource.FieldConfig{
+		{Name: "age", Type: "integer"},
+		{Name: "score", Type: "integer"},
+	}
+	wantViolations := []projection.Violation{{
+		Field:  "fields.age",
+		Type:   "integer",
+		Value:  "unknown",
+		Reason: "not a number",
+	}}
+
+	filtered, violations := filterFields(data, fields, "fields", resource.CoercionConfig{Policy: resource.CoercionDrop})
+	require.Equal(t, map[string]any{"score": int64(7)}, filtered)
+	require.Equal(t, wantViolations, violations)
+
+	filtered, violations = filterFields(data, fields, "fields", resource.CoercionConfig{Policy: resource.CoercionNull})
+	require.Equal(t, map[string]any{"age": nil, "score": int64(7)}, filtered)
+	require.Equal(t, wantViolations, violations)
+}
@@ -0,0 +1,122 @@
+package dsl
+
+import (
+	"context"
+	"fmt"
+	"maps"
+
+	"github.com/theleeeo/indexer/expr"
+	"github.com/theleeeo/indexer/projection"
+	"github.com/theleeeo/indexer/resource"
+)
+
+// computedFetcher implements aggregation.SubFetcher[BuildDoc] for the
+// computed fields of a resource. It runs after all relations are resolved and
+// evaluates each field's expression. References resolve to the root's data
+// and to the data of the root's relations, by relation name. Computed fields
+// can reference the computed fields defined before them.
+type computedFetcher struct {
+	resourceName string
+	fields       []computedField
+	relations    []resource.RelationConfig
+	coercion     resource.CoercionConfig
+}
+
+type computedField struct {
+	field resource.FieldConfig
+	expr  *expr.Expr
+	// err is the parse error of the expression. Validation rejects invalid
+	// expressions, so it is only reported if the config was not validated.
+	err error
+}
+
+func newComputedFetcher(resourceName string, vc *resource.VersionConfig, coercion resource.CoercionConfig) *computedFetcher {
+	f := &computedFetcher{
+		resourceName: resourceName,
+		relations:    vc.Relations,
+		coercion:     coercion,
+	}
+	for _, field := range vc.Fields {
+		if field.Computed == "" {
+			continue
+		}
+		e, err := field.ComputedExpr()
+		f.fields = append(f.fields, computedField{field: field, expr: e, err: err})
+	}
+	return f
+}
+
+// computedValues holds the values of the computed fields by name, converted
+// to the types of the fields. Fields evaluating to null are left out.
+type computedValues struct {
+	values     map[string]any
+	violations []projection.Violation
+}
+
+func (f *computedFetcher) Fetch(_ context.Context, parent projection.BuildDoc) (any, error) {
+	if parent.Doc == nil {
+		return (*computedValues)(nil), nil
+	}
+
+	vars := make(map[string]any)
+	if root := parent.Resolved[f.resourceName]; len(root) > 0 {
+		maps.Copy(vars, root[0])
+	}
+	for _, rel := range f.relations {
+		vars[rel.Name()] = parent.Resolved[rel.Name()]
+	}
+
+	result := &computedValues{values: make(map[string]any)}
+	for _, cf := range f.fields {
+		name := cf.field.Name
+		if cf.err != nil {
+			return nil, fmt.Errorf("computed field %q: %w", name, cf.err)
+		}
+		// An expression can fail on the data of a single resource, e.g. on a
+		// string where it expects a number. That is a violation like a value
+		// that cannot be converted, handled by the coercion policy.
+		v, err := cf.expr.Eval(vars)
+		if err != nil {
+			result.addViolation(cf.field, cf.field.Computed, err, f.coercion)
+			vars[name] = nil
+			continue
+		}
+
+		vars[name] = v
+		if v == nil {
+			continue
+		}
+
+		c, err := coerce(cf.field, v)
+		if err != nil {
+			result.addViolation(cf.field, v, err, f.coercion)
+			continue
+		}
+		result.values[name] = c
+	}
+
+	return result, nil
+}
+
+// addViolation records that the field has no valid value. With the null
+// policy the field is indexed as null, otherwise it is left out.
+func (cv *computedValues) addViolation(field resource.FieldConfig, value any, err error, coercion resource.CoercionConfig) {
+	cv.violations = append(cv.violations, projection.Violation{
+		Field:  "fields." + field.Name,
+		Type:   field.ESType(),
+		Value:  value,
+		Reason: err.Error(),
+	})
+	if coercion.Policy == resource.CoercionNull {
+		cv.values[field.Name] = nil
+	}
+}
+
+func hasComputedFields(fields []resource.FieldConfig) bool {
+	for _, f := range fields {
+		if f.Computed != "" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,150 @@
+package dsl
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/theleeeo/indexer/projection"
+	"github.com/theleeeo/indexer/resource"
+	"github.com/theleeeo/indexer/source"
+)
+
+// relationKeys extracts the lookup keys of a relation from the items of its
+// key source. Every item contributes its own key, and array values fan out
+// to one key per element unless the relation batches them. Items missing a
+// key value are skipped. Duplicate keys are only returned once.
+func relationKeys(key resource.KeyConfig, items []map[string]any) ([]source.ResourceKey, error) {
+	fields := key.KeyFields()
+	if len(fields) == 1 {
+		return singleFieldKeys(fields[0], key.Array == resource.KeyArrayBatch, items)
+	}
+
+	var keys []source.ResourceKey
+	seen := make(map[string]bool)
+	for _, item := range items {
+		parts := make([]source.KeyPart, 0, len(fields))
+		ids := make([]string, 0, len(fields))
+		for _, f := range fields {
+			if _, ok := item[f].([]any); ok {
+				return nil, fmt.Errorf("composite key field %q is an array", f)
+			}
+			s, ok := source.FormatKeyValue(item[f])
+			if !ok {
+				break
+			}
+			parts = append(parts, source.KeyPart{Field: f, Value: item[f]})
+			ids = append(ids, s)
+		}
+		if len(parts) < len(fields) {
+			continue
+		}
+
+		id := strings.Join(ids, "\x00")
+		if !seen[id] {
+			seen[id] = true
+			keys = append(keys, source.NewResourceKey(parts...))
+		}
+	}
+
+	return keys, nil
+}
+
+func singleFieldKeys(field string, batch bool, items []map[string]any) ([]source.ResourceKey, error) {
+	var values []any
+	seen := make(map[string]bool)
+	add := func(v any) error {
+		s, ok := source.FormatKeyValue(v)
+		if !ok {
+			if v == nil || v == "" {
+				return nil
+			}
+			return fmt.Errorf("key field %q has an unsupported value of type %T", field, v)
+		}
+		if !seen[s] {
+			seen[s] = true
+			values = append(values, v)
+		}
+		return nil
+	}
+
+	for _, item := range items {
+		if arr, ok := item[field].([]any); ok {
+			for _, v := range arr {
+				if err := add(v); err != nil {
+					return nil, err
+				}
+			}
+			continue
+		}
+		if err := add(item[field]); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(values) == 0 {
+		return nil, nil
+	}
+	if batch {
+		return []source.ResourceKey{source.NewResourceKey(source.KeyPart{Field: field, Value: values})}, nil
+	}
+
+	keys := make([]source.ResourceKey, len(values))
+	for i, v := range values {
+		keys[i] = source.NewResourceKey(source.KeyPart{Field: field, Value: v})
+	}
+	return keys, nil
+}
+
+// fetchByKeys fetches the related resources of every key and concatenates
+// them. Resources returned for more than one key are only included once.
+func fetchByKeys(
+	ctx context.Context,
+	provider source.Provider,
+	parent projection.BuildDoc,
+	resourceType string,
+	keys []source.ResourceKey,
+	hints source.RelationHints,
+) ([]map[string]any, error) {
+	byKey := make([][]map[string]any, len(keys))
+	for i, key := range keys {
+		resp, err := provider.FetchRelated(ctx, source.FetchRelatedParams{
+			RootResource: source.RootResource{
+				Type: parent.Root.Type,
+				Id:   parent.Root.Id,
+			},
+			ResourceType: resourceType,
+			Key:          key,
+			Metadata:     parent.Metadata,
+			Hints:        hints,
+		})
+		if err != nil {
+			return nil, err
+		}
+		byKey[i] = resp.Related
+	}
+	return mergeRelated(byKey), nil
+}
+
+// mergeRelated concatenates the related resources fetched for each key of a
+// parent. Resources returned for more than one key are only included once.
+func mergeRelated(byKey [][]map[string]any) []map[string]any {
+	if len(byKey) == 1 {
+		return byKey[0]
+	}
+
+	var related []map[string]any
+	seen := make(map[string]bool)
+	for _, items := range byKey {
+		for _, r := range items {
+			if id, ok := source.FormatKeyValue(r["id"]); ok {
+				if seen[id] {
+					continue
+				}
+				seen[id] = true
+			}
+			related = append(related, r)
+		}
+	}
+	return related
+}
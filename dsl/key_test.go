@@ -0,0 +1,92 @@
+package dsl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/theleeeo/indexer/resource"
+	"github.com/theleeeo/indexer/source"
+)
+
+func TestRelationKeys(t *testing.T) {
+	tests := []struct {
+		name  string
+		key   resource.KeyConfig
+		items []map[string]any
+		want  []source.ResourceKey
+	}{
+		{
+			name:  "string",
+			key:   resource.KeyConfig{Field: "customer_id"},
+			items: []map[string]any{{"customer_id": "c1"}},
+			want:  []source.ResourceKey{{Field: "customer_id", Value: "c1", Parts: []source.KeyPart{{Field: "customer_id", Value: "c1"}}}},
+		},
+		{
+			name:  "number",
+			key:   resource.KeyConfig{Field: "customer_id"},
+			items: []map[string]any{{"customer_id": float64(42)}},
+			want:  []source.ResourceKey{{Field: "customer_id", Value: "42", Parts: []source.KeyPart{{Field: "customer_id", Value: float64(42)}}}},
+		},
+		{
+			name:  "missing",
+			key:   resource.KeyConfig{Field: "customer_id"},
+			items: []map[string]any{{"customer_id": ""}, {}},
+		},
+		{
+			name:  "array fans out per element",
+			key:   resource.KeyConfig{Field: "tag_ids"},
+			items: []map[string]any{{"tag_ids": []any{"t1", "t2", "t1"}}},
+			want: []source.ResourceKey{
+				{Field: "tag_ids", Value: "t1", Parts: []source.KeyPart{{Field: "tag_ids", Value: "t1"}}},
+				{Field: "tag_ids", Value: "t2", Parts: []source.KeyPart{{Field: "tag_ids", Value: "t2"}}},
+			},
+		},
+		{
+			name:  "array batched",
+			key:   resource.KeyConfig{Field: "tag_ids", Array: resource.KeyArrayBatch},
+			items: []map[string]any{{"tag_ids": []any{"t1", "t2"}}, {"tag_ids": []any{"t2", "t3"}}},
+			want: []source.ResourceKey{
+				{Field: "tag_ids", Parts: []source.KeyPart{{Field: "tag_ids", Value: []any{"t1", "t2", "t3"}}}},
+			},
+		},
+		{
+			name:  "every source item",
+			key:   resource.KeyConfig{Field: "product_id"},
+			items: []map[string]any{{"product_id": "p1"}, {"product_id": "p2"}, {"product_id": "p1"}},
+			want: []source.ResourceKey{
+				{Field: "product_id", Value: "p1", Parts: []source.KeyPart{{Field: "product_id", Value: "p1"}}},
+				{Field: "product_id", Value: "p2", Parts: []source.KeyPart{{Field: "product_id", Value: "p2"}}},
+			},
+		},
+		{
+			name:  "composite",
+			key:   resource.KeyConfig{Fields: []string{"tenant_id", "sku"}},
+			items: []map[string]any{{"tenant_id": float64(7), "sku": "A-1"}, {"tenant_id": float64(7)}},
+			want: []source.ResourceKey{{
+				Field: "tenant_id",
+				Value: "7",
+				Parts: []source.KeyPart{{Field: "tenant_id", Value: float64(7)}, {Field: "sku", Value: "A-1"}},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := relationKeys(tt.key, tt.items)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRelationKeys_Invalid(t *testing.T) {
+	_, err := relationKeys(resource.KeyConfig{Fields: []string{"tenant_id", "skus"}}, []map[string]any{
+		{"tenant_id": "t1", "skus": []any{"A-1"}},
+	})
+	require.EqualError(t, err, `composite key field "skus" is an array`)
+
+	_, err = relationKeys(resource.KeyConfig{Field: "customer"}, []map[string]any{
+		{"customer": map[string]any{"id": "c1"}},
+	})
+	require.EqualError(t, err, `key field "customer" has an unsupported value of type map[string]interface {}`)
+}
@@ -2,7 +2,9 @@ package dsl
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"maps"
 
 	"github.com/theleeeo/indexer/aggregation"
 	"github.com/theleeeo/indexer/model"
@@ -11,15 +13,51 @@ import (
 	"github.com/theleeeo/indexer/source"
 )
 
+// RelationGraph reads the recorded relations between resources. It is used by
+// reverse relations with source "store". store.PostgresStore implements it.
+type RelationGraph interface {
+	// GetParentResourcesOfType returns the resources of the given type that
+	// have a relation to child.
+	GetParentResourcesOfType(ctx context.Context, child model.Resource, parentType string) ([]model.Resource, error)
+}
+
+// planEnv holds the dependencies shared by the plans of all resources.
+type planEnv struct {
+	provider source.Provider
+	graph    RelationGraph
+	// reverseRefs holds the reverse relations embedding each resource type.
+	reverseRefs map[string][]resource.ReverseRef
+	// coercion holds the coercion config of each resource type.
+	coercion map[string]resource.CoercionConfig
+	// concurrency holds the number of documents of each resource type whose
+	// relations are fetched at once.
+	concurrency map[string]int
+}
+
 // BuildPlansFromConfig constructs aggregation plans for each resource type
 // and version in the config. This is the default plan builder used by the standalone binary.
 // Library users can build their own plans and pass them to NewBuilder directly.
-func BuildPlansFromConfig(provider source.Provider, resources resource.Configs) map[string][]projection.Plan {
+// The graph is only used by reverse relations reading from the store and may
+// be nil if there are none.
+func BuildPlansFromConfig(provider source.Provider, graph RelationGraph, resources resource.Configs) map[string][]projection.Plan {
+	env := planEnv{
+		provider:    provider,
+		graph:       graph,
+		reverseRefs: make(map[string][]resource.ReverseRef),
+		coercion:    make(map[string]resource.CoercionConfig),
+		concurrency: make(map[string]int),
+	}
+	for _, rCfg := range resources {
+		env.reverseRefs[rCfg.Resource] = resources.ReverseRefs(rCfg.Resource)
+		env.coercion[rCfg.Resource] = rCfg.Coercion
+		env.concurrency[rCfg.Resource] = rCfg.Concurrency
+	}
+
 	plans := make(map[string][]projection.Plan, len(resources))
 	for _, rCfg := range resources {
 		versionPlans := make([]projection.Plan, len(rCfg.Versions))
 		for i, vc := range rCfg.Versions {
-			versionPlans[i] = buildPlanForVersion(provider, rCfg.Resource, &vc)
+			versionPlans[i] = buildPlanForVersion(env, rCfg.Resource, &vc)
 		}
 		plans[rCfg.Resource] = versionPlans
 	}
@@ -27,17 +65,26 @@ func BuildPlansFromConfig(provider source.Provider, resources resource.Configs)
 }
 
 // buildPlanForVersion creates a RootPlan for the resource version and chains SubPlans
-// for each relation in topological order.
-func buildPlanForVersion(provider source.Provider, resourceName string, vc *resource.VersionConfig) projection.Plan {
+// for each level of relations in topological order, followed by one for the
+// computed fields.
+func buildPlanForVersion(env planEnv, resourceName string, vc *resource.VersionConfig) projection.Plan {
+	refs := env.reverseRefs[resourceName]
+	coercion := env.coercion[resourceName]
+	concurrency := env.concurrency[resourceName]
+
 	// Root plan: fetches the root resource and initialises the BuildDoc.
-	// When ResourceID is empty, the plan lists all resources of the type with
-	// pagination via provider.ListResources. When set, it fetches a single
-	// resource as before.
+	// When ResourceID is set, it fetches a single resource. When ResourceIDs
+	// is set, it fetches those resources in pages. Otherwise the plan lists
+	// all resources of the type with pagination via provider.ListResources.
 	rootPlan := aggregation.NewRootPlan(func(params aggregation.FetchParameters[projection.BuildRequest]) (aggregation.FetchResult[projection.BuildDoc], error) {
-		if params.Request.ResourceID == "" {
-			return fetchAllResources(provider, resourceName, vc.Fields, params)
+		switch {
+		case params.Request.ResourceID != "":
+			return fetchSingleResource(env.provider, resourceName, vc.Fields, refs, coercion, params)
+		case len(params.Request.ResourceIDs) > 0:
+			return fetchResourcesByIDs(env.provider, resourceName, vc.Fields, refs, coercion, params)
+		default:
+			return fetchAllResources(env.provider, resourceName, vc.Fields, refs, coercion, params)
 		}
-		return fetchSingleResource(provider, resourceName, vc.Fields, params)
 	})
 
 	// Resolve the topological order of relations.
@@ -53,10 +100,16 @@ func buildPlanForVersion(provider source.Provider, resourceName string, vc *reso
 		}
 	}
 
-	// Chain a SubPlan for each relation.
+	// Chain a SubPlan for each level of relations. The relations of a level
+	// do not depend on each other and are fetched at once.
 	var current aggregation.Executer[projection.BuildRequest, projection.BuildDoc] = rootPlan
-	for _, rel := range ordered {
-		current = buildRelationSubPlan(provider, current, rel)
+	for _, level := range resolveLevels(resourceName, ordered) {
+		current = buildRelationSubPlan(env, current, level, coercion).WithConcurrency(concurrency)
+	}
+
+	// Computed fields are evaluated last, so that they can use all relations.
+	if hasComputedFields(vc.Fields) {
+		current = buildComputedSubPlan(current, newComputedFetcher(resourceName, vc, coercion)).WithConcurrency(concurrency)
 	}
 
 	return projection.Plan{
@@ -65,15 +118,28 @@ func buildPlanForVersion(provider source.Provider, resourceName string, vc *reso
 	}
 }
 
-// buildRelationSubPlan creates a SubPlan for a single relation.
+// buildRelationSubPlan creates a SubPlan fetching a level of independent
+// relations in parallel.
 func buildRelationSubPlan(
-	provider source.Provider,
+	env planEnv,
 	parent aggregation.Executer[projection.BuildRequest, projection.BuildDoc],
-	rel resource.RelationConfig,
+	rels []resource.RelationConfig,
+	coercion resource.CoercionConfig,
 ) *aggregation.SubPlan[projection.BuildRequest, projection.BuildDoc, projection.BuildDoc] {
-	fetcher := &relationFetcher{
-		provider: provider,
-		rel:      rel,
+	fetcher := make(aggregation.ParallelFetcher[projection.BuildDoc], len(rels))
+	for i, rel := range rels {
+		if rel.Reverse != nil {
+			fetcher[i] = &reverseFetcher{
+				provider: env.provider,
+				graph:    env.graph,
+				rel:      newPlannedRelation(rel),
+			}
+		} else {
+			fetcher[i] = &relationFetcher{
+				provider: env.provider,
+				rel:      newPlannedRelation(rel),
+			}
+		}
 	}
 
 	builder := func(parentDoc projection.BuildDoc, fetchResult any) projection.BuildDoc {
@@ -81,54 +147,163 @@ func buildRelationSubPlan(
 			return parentDoc
 		}
 
-		fr, ok := fetchResult.(*fetchedRelation)
-		if !ok || fr == nil {
+		results, _ := fetchResult.([]any)
+		for i, rel := range rels {
+			if i >= len(results) {
+				break
+			}
+			fr, ok := results[i].(*fetchedRelation)
+			if !ok || fr == nil {
+				continue
+			}
+
+			// Update the resolved map so downstream relations can reference this data.
+			parentDoc.Resolved[rel.Name()] = fr.Related
+
+			parentDoc.Doc[rel.Name()] = embedRelated(&parentDoc, fr, rel, rel.Name(), coercion)
+		}
+		return parentDoc
+	}
+
+	return aggregation.NewSubPlan(parent, fetcher, builder)
+}
+
+// buildComputedSubPlan creates the SubPlan adding the computed fields to the
+// document.
+func buildComputedSubPlan(
+	parent aggregation.Executer[projection.BuildRequest, projection.BuildDoc],
+	fetcher *computedFetcher,
+) *aggregation.SubPlan[projection.BuildRequest, projection.BuildDoc, projection.BuildDoc] {
+	builder := func(parentDoc projection.BuildDoc, fetchResult any) projection.BuildDoc {
+		cv, ok := fetchResult.(*computedValues)
+		if parentDoc.Doc == nil || !ok || cv == nil {
 			return parentDoc
 		}
 
-		for _, r := range fr.Related {
-			if id, ok := r["id"]; ok {
-				if idStr, ok := id.(string); ok {
-					parentDoc.Relations = append(parentDoc.Relations, model.Resource{Type: fr.ResourceType, Id: idStr})
-				}
+		fields, _ := parentDoc.Doc["fields"].(map[string]any)
+		maps.Copy(fields, cv.values)
+		parentDoc.Violations = append(parentDoc.Violations, cv.violations...)
+		return parentDoc
+	}
+
+	return aggregation.NewSubPlan(parent, fetcher, builder)
+}
+
+// embedRelated returns the document objects of the fetched items of a
+// relation embedded at path, with the objects of its own relations embedded
+// in each. Every item on every level is recorded in the relations of doc, so
+// that a change to any of them reaches the root, and every value that cannot
+// be converted in its violations.
+func embedRelated(doc *projection.BuildDoc, fr *fetchedRelation, rel resource.RelationConfig, path string, coercion resource.CoercionConfig) []map[string]any {
+	objects := make([]map[string]any, 0, len(fr.Related))
+	for i, r := range fr.Related {
+		filtered, violations := filterFields(r, rel.Fields, path, coercion)
+		doc.Violations = append(doc.Violations, violations...)
+		if id, ok := r["id"]; ok {
+			filtered["id"] = id
+			if idStr, ok := source.FormatKeyValue(id); ok {
+				doc.Relations = append(doc.Relations, model.Resource{Type: fr.ResourceType, Id: idStr})
 			}
 		}
 
-		// Update the resolved map so downstream relations can reference this data.
-		parentDoc.Resolved[rel.Resource] = fr.Related
-
-		subResources := make([]map[string]any, 0, len(fr.Related))
-		for _, r := range fr.Related {
-			filtered := filterFields(r, rel.Fields)
-			if id, ok := r["id"]; ok {
-				filtered["id"] = id
+		if i < len(fr.Children) {
+			for _, child := range rel.Relations {
+				if cfr := fr.Children[i][child.Name()]; cfr != nil {
+					filtered[child.Name()] = embedRelated(doc, cfr, child, path+"."+child.Name(), coercion)
+				}
 			}
-			subResources = append(subResources, filtered)
 		}
 
-		parentDoc.Doc[rel.Resource] = subResources
-		return parentDoc
+		objects = append(objects, filtered)
 	}
 
-	return aggregation.NewSubPlan(parent, fetcher, builder)
+	// Items outside the relation's selection may enter it when they change.
+	for _, r := range fr.Excluded {
+		if id, ok := source.FormatKeyValue(r["id"]); ok {
+			doc.Relations = append(doc.Relations, model.Resource{Type: fr.ResourceType, Id: id})
+		}
+	}
+
+	return objects
 }
 
-func filterFields(data map[string]any, fields []resource.FieldConfig) map[string]any {
+// filterFields copies the configured fields from provider data, converting
+// each value to the type of its field if coercion is enabled. A value that
+// cannot be converted is reported as a violation of the field in the object
+// at path and is left out, or set to null with the "null" coercion policy.
+func filterFields(data map[string]any, fields []resource.FieldConfig, path string, coercion resource.CoercionConfig) (map[string]any, []projection.Violation) {
 	result := make(map[string]any, len(fields))
+	var violations []projection.Violation
 	for _, f := range fields {
-		if v, ok := data[f.Name]; ok {
-			result[f.Name] = v
+		if f.Computed != "" {
+			continue
+		}
+		v, ok := f.Value(data)
+		if !ok {
+			continue
+		}
+
+		var c any
+		var err error
+		if f.IsObject() {
+			var objViolations []projection.Violation
+			c, objViolations, err = filterObject(v, f.Fields, path+"."+f.Name, coercion)
+			violations = append(violations, objViolations...)
+		} else if coercion.Enabled() {
+			c, err = coerce(f, v)
+		} else {
+			c = v
 		}
+		if err != nil {
+			violations = append(violations, projection.Violation{
+				Field:  path + "." + f.Name,
+				Type:   f.ESType(),
+				Value:  v,
+				Reason: err.Error(),
+			})
+			if coercion.Policy == resource.CoercionNull {
+				result[f.Name] = nil
+			}
+			continue
+		}
+		result[f.Name] = c
+	}
+	return result, violations
+}
+
+// filterObject projects the fields of the object, or of every object in the
+// list, of an object or nested field.
+func filterObject(v any, fields []resource.FieldConfig, path string, coercion resource.CoercionConfig) (any, []projection.Violation, error) {
+	switch v := v.(type) {
+	case nil:
+		return nil, nil, nil
+	case map[string]any:
+		obj, violations := filterFields(v, fields, path, coercion)
+		return obj, violations, nil
+	case []any:
+		objects := make([]any, 0, len(v))
+		var violations []projection.Violation
+		for i, item := range v {
+			m, ok := item.(map[string]any)
+			if !ok {
+				return nil, nil, fmt.Errorf("element %d: expected an object, got %T", i, item)
+			}
+			obj, objViolations := filterFields(m, fields, path, coercion)
+			objects = append(objects, obj)
+			violations = append(violations, objViolations...)
+		}
+		return objects, violations, nil
+	default:
+		return nil, nil, fmt.Errorf("expected an object, got %T", v)
 	}
-	return result
 }
 
 // resolveOrder topologically sorts relations so that dependencies (relations
 // whose key source is another relation) are resolved before their dependants.
 func resolveOrder(rootType string, relations []resource.RelationConfig) ([]resource.RelationConfig, error) {
-	byResource := make(map[string]resource.RelationConfig, len(relations))
+	byName := make(map[string]resource.RelationConfig, len(relations))
 	for _, r := range relations {
-		byResource[r.Resource] = r
+		byName[r.Name()] = r
 	}
 
 	var ordered []resource.RelationConfig
@@ -137,16 +312,16 @@ func resolveOrder(rootType string, relations []resource.RelationConfig) ([]resou
 
 	var visit func(rel resource.RelationConfig) error
 	visit = func(rel resource.RelationConfig) error {
-		if inStack[rel.Resource] {
-			return fmt.Errorf("cycle detected involving %q", rel.Resource)
+		if inStack[rel.Name()] {
+			return fmt.Errorf("cycle detected involving %q", rel.Name())
 		}
-		if visited[rel.Resource] {
+		if visited[rel.Name()] {
 			return nil
 		}
-		inStack[rel.Resource] = true
+		inStack[rel.Name()] = true
 
-		if rel.Key.Source != rootType {
-			dep, ok := byResource[rel.Key.Source]
+		if rel.Reverse == nil && rel.Key.Source != rootType {
+			dep, ok := byName[rel.Key.Source]
 			if !ok {
 				return fmt.Errorf("key source %q not found among relations", rel.Key.Source)
 			}
@@ -155,8 +330,8 @@ func resolveOrder(rootType string, relations []resource.RelationConfig) ([]resou
 			}
 		}
 
-		inStack[rel.Resource] = false
-		visited[rel.Resource] = true
+		inStack[rel.Name()] = false
+		visited[rel.Name()] = true
 		ordered = append(ordered, rel)
 		return nil
 	}
@@ -170,14 +345,37 @@ func resolveOrder(rootType string, relations []resource.RelationConfig) ([]resou
 	return ordered, nil
 }
 
+// resolveLevels groups topologically ordered relations by their depth in the
+// dependency graph. Relations keyed by the root and reverse relations are on
+// the first level, every other relation on the level after its key source.
+func resolveLevels(rootType string, ordered []resource.RelationConfig) [][]resource.RelationConfig {
+	depth := make(map[string]int, len(ordered))
+	var levels [][]resource.RelationConfig
+	for _, rel := range ordered {
+		d := 0
+		if rel.Reverse == nil && rel.Key.Source != rootType {
+			d = depth[rel.Key.Source] + 1
+		}
+		depth[rel.Name()] = d
+
+		if d == len(levels) {
+			levels = append(levels, nil)
+		}
+		levels[d] = append(levels[d], rel)
+	}
+	return levels
+}
+
 // fetchSingleResource fetches one resource by ID and wraps it in a BuildDoc.
 func fetchSingleResource(
 	provider source.Provider,
 	resourceName string,
 	fields []resource.FieldConfig,
+	refs []resource.ReverseRef,
+	coercion resource.CoercionConfig,
 	params aggregation.FetchParameters[projection.BuildRequest],
 ) (aggregation.FetchResult[projection.BuildDoc], error) {
-	data, err := provider.FetchResource(context.Background(), source.FetchResourceParams{
+	data, err := provider.FetchResource(params.Context, source.FetchResourceParams{
 		ResourceType: params.Request.ResourceType,
 		ResourceID:   params.Request.ResourceID,
 		Metadata:     params.Request.Metadata,
@@ -186,33 +384,126 @@ func fetchSingleResource(
 		return aggregation.FetchResult[projection.BuildDoc]{}, fmt.Errorf("fetch resource %s/%s: %w", params.Request.ResourceType, params.Request.ResourceID, err)
 	}
 
-	root := model.Resource{Type: params.Request.ResourceType, Id: params.Request.ResourceID}
+	doc := newBuildDoc(resourceName, params.Request.ResourceID, data.Data, fields, refs, coercion, params.Request)
+	return aggregation.FetchResult[projection.BuildDoc]{Items: []projection.BuildDoc{doc}}, nil
+}
 
-	if data.Data == nil {
-		return aggregation.FetchResult[projection.BuildDoc]{Items: []projection.BuildDoc{{
-			Doc:      nil,
-			Resolved: nil,
-			Root:     root,
-			Metadata: params.Request.Metadata,
-		}}}, nil
+// resourceBatchSize is the number of resources fetched per page when
+// building many resources.
+const resourceBatchSize = 100
+
+// fetchResourcesByIDs fetches a page of the requested IDs and wraps each in a
+// BuildDoc, in the order of the IDs. The NextPageToken is the index of the
+// first ID of the next page. Resources that do not exist get a BuildDoc
+// without a Doc.
+func fetchResourcesByIDs(
+	provider source.Provider,
+	resourceName string,
+	fields []resource.FieldConfig,
+	refs []resource.ReverseRef,
+	coercion resource.CoercionConfig,
+	params aggregation.FetchParameters[projection.BuildRequest],
+) (aggregation.FetchResult[projection.BuildDoc], error) {
+	start := 0
+	if params.NextPageToken != nil {
+		start = params.NextPageToken.(int)
 	}
+	end := min(start+resourceBatchSize, len(params.Request.ResourceIDs))
+	ids := params.Request.ResourceIDs[start:end]
 
-	filtered := filterFields(data.Data, fields)
+	data, err := fetchResources(params.Context, provider, params.Request.ResourceType, ids, params.Request.Metadata)
+	if err != nil {
+		return aggregation.FetchResult[projection.BuildDoc]{}, err
+	}
+
+	items := make([]projection.BuildDoc, len(ids))
+	for i, id := range ids {
+		items[i] = newBuildDoc(resourceName, id, data[id], fields, refs, coercion, params.Request)
+	}
+
+	var npt any
+	if end < len(params.Request.ResourceIDs) {
+		npt = end
+	}
 
 	return aggregation.FetchResult[projection.BuildDoc]{
-		Items: []projection.BuildDoc{{
-			Doc: map[string]any{
-				"fields": filtered,
-			},
-			Resolved: map[string][]map[string]any{
-				resourceName: {data.Data},
-			},
-			Root:     root,
-			Metadata: params.Request.Metadata,
-		}},
+		Items:         items,
+		NextPageToken: npt,
 	}, nil
 }
 
+// fetchResources fetches the data of the resources by ID, in one call if the
+// provider implements source.ResourceBatchProvider and one call per ID
+// otherwise.
+func fetchResources(ctx context.Context, provider source.Provider, resourceType string, ids []string, metadata map[string]string) (map[string]map[string]any, error) {
+	if bp, ok := provider.(source.ResourceBatchProvider); ok {
+		resp, err := bp.FetchResources(ctx, source.FetchResourcesParams{
+			ResourceType: resourceType,
+			ResourceIDs:  ids,
+			Metadata:     metadata,
+		})
+		if err == nil {
+			return resp.Data, nil
+		}
+		if !errors.Is(err, source.ErrBatchUnsupported) {
+			return nil, fmt.Errorf("fetch resources %s: %w", resourceType, err)
+		}
+	}
+
+	data := make(map[string]map[string]any, len(ids))
+	for _, id := range ids {
+		resp, err := provider.FetchResource(ctx, source.FetchResourceParams{
+			ResourceType: resourceType,
+			ResourceID:   id,
+			Metadata:     metadata,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("fetch resource %s/%s: %w", resourceType, id, err)
+		}
+		if resp.Data != nil {
+			data[id] = resp.Data
+		}
+	}
+	return data, nil
+}
+
+// newBuildDoc wraps the data of a root resource in a BuildDoc. Without data
+// the resource no longer exists and the BuildDoc has no Doc.
+func newBuildDoc(
+	resourceName string,
+	id string,
+	data map[string]any,
+	fields []resource.FieldConfig,
+	refs []resource.ReverseRef,
+	coercion resource.CoercionConfig,
+	req projection.BuildRequest,
+) projection.BuildDoc {
+	root := model.Resource{Type: req.ResourceType, Id: id}
+	if data == nil {
+		return projection.BuildDoc{
+			Root:     root,
+			Metadata: req.Metadata,
+		}
+	}
+
+	filtered, violations := filterFields(data, fields, "fields", coercion)
+
+	return projection.BuildDoc{
+		Doc: map[string]any{
+			// Copy of the document ID, used as tiebreaker when paging search results.
+			"id":     id,
+			"fields": filtered,
+		},
+		Resolved: map[string][]map[string]any{
+			resourceName: {data},
+		},
+		Root:       root,
+		Metadata:   req.Metadata,
+		Relations:  reverseRelations(data, refs),
+		Violations: violations,
+	}
+}
+
 // fetchAllResources lists resources of a type with pagination and returns a
 // page of BuildDocs. The NextPageToken from the provider is passed through so
 // that the RootPlan's pagination loop keeps calling until exhausted.
@@ -220,6 +511,8 @@ func fetchAllResources(
 	provider source.Provider,
 	resourceName string,
 	fields []resource.FieldConfig,
+	refs []resource.ReverseRef,
+	coercion resource.CoercionConfig,
 	params aggregation.FetchParameters[projection.BuildRequest],
 ) (aggregation.FetchResult[projection.BuildDoc], error) {
 	var pageToken string
@@ -227,10 +520,10 @@ func fetchAllResources(
 		pageToken = params.NextPageToken.(string)
 	}
 
-	resp, err := provider.ListResources(context.Background(), source.ListResourcesParams{
+	resp, err := provider.ListResources(params.Context, source.ListResourcesParams{
 		ResourceType: params.Request.ResourceType,
 		PageToken:    pageToken,
-		PageSize:     100,
+		PageSize:     resourceBatchSize,
 		Metadata:     params.Request.Metadata,
 	})
 	if err != nil {
@@ -242,17 +535,7 @@ func fetchAllResources(
 		if r.Data == nil {
 			continue
 		}
-		filtered := filterFields(r.Data, fields)
-		items = append(items, projection.BuildDoc{
-			Doc: map[string]any{
-				"fields": filtered,
-			},
-			Resolved: map[string][]map[string]any{
-				resourceName: {r.Data},
-			},
-			Root:     model.Resource{Type: params.Request.ResourceType, Id: r.ID},
-			Metadata: params.Request.Metadata,
-		})
+		items = append(items, newBuildDoc(resourceName, r.ID, r.Data, fields, refs, coercion, params.Request))
 	}
 
 	var npt any
@@ -265,3 +548,16 @@ func fetchAllResources(
 		NextPageToken: npt,
 	}, nil
 }
+
+// reverseRelations returns the resources that embed a resource through a
+// reverse relation, as referenced by the resource's own data. Recording them
+// as relations of the resource lets changes to it reach those resources.
+func reverseRelations(data map[string]any, refs []resource.ReverseRef) []model.Resource {
+	var relations []model.Resource
+	for _, ref := range refs {
+		if id, ok := source.FormatKeyValue(data[ref.Field]); ok {
+			relations = append(relations, model.Resource{Type: ref.Resource, Id: id})
+		}
+	}
+	return relations
+}
@@ -3,9 +3,11 @@ package dsl
 import (
 	"context"
 	"fmt"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	"github.com/theleeeo/indexer/model"
 	"github.com/theleeeo/indexer/projection"
 	"github.com/theleeeo/indexer/resource"
 	"github.com/theleeeo/indexer/source"
@@ -13,6 +15,8 @@ import (
 
 // mockProvider is a minimal source.Provider for testing plan execution.
 type mockProvider struct {
+	// mu guards the last* fields, written by concurrent fetches.
+	mu        sync.Mutex
 	resources map[string]map[string]any   // "type|id" -> data
 	related   map[string][]map[string]any // "type|keyval" -> []data
 	listed    map[string][]source.ListedResource
@@ -20,6 +24,7 @@ type mockProvider struct {
 	lastFetchResourceMetadata map[string]string
 	lastFetchRelatedMetadata  map[string]string
 	lastListMetadata          map[string]string
+	lastFetchRelatedHints     source.RelationHints
 	// pageSize controls how many items are returned per ListResources page.
 	pageSize int
 }
@@ -45,6 +50,8 @@ func copyMetadata(in map[string]string) map[string]string {
 }
 
 func (m *mockProvider) FetchResource(_ context.Context, params source.FetchResourceParams) (source.FetchResourceResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	m.lastFetchResourceMetadata = copyMetadata(params.Metadata)
 	data, ok := m.resources[params.ResourceType+"|"+params.ResourceID]
 	if !ok {
@@ -54,7 +61,10 @@ func (m *mockProvider) FetchResource(_ context.Context, params source.FetchResou
 }
 
 func (m *mockProvider) FetchRelated(_ context.Context, params source.FetchRelatedParams) (source.FetchRelatedResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	m.lastFetchRelatedMetadata = copyMetadata(params.Metadata)
+	m.lastFetchRelatedHints = params.Hints
 	key := params.ResourceType + "|" + params.Key.Value
 	data, ok := m.related[key]
 	if !ok {
@@ -64,6 +74,8 @@ func (m *mockProvider) FetchRelated(_ context.Context, params source.FetchRelate
 }
 
 func (m *mockProvider) ListResources(_ context.Context, params source.ListResourcesParams) (source.ListResourcesResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	m.lastListMetadata = copyMetadata(params.Metadata)
 	all, ok := m.listed[params.ResourceType]
 	if !ok {
@@ -115,7 +127,7 @@ func TestBuildPlanForVersion_PropagatesMetadata(t *testing.T) {
 		}},
 	}
 
-	plan := buildPlanForVersion(prov, "order", vc)
+	plan := buildPlanForVersion(planEnv{provider: prov}, "order", vc)
 	metadata := map[string]string{"tenant-id": "t1", "trace-id": "abc"}
 
 	ch := plan.Execute(context.Background(), projection.BuildRequest{
@@ -146,7 +158,7 @@ func TestBuildPlanForVersion_FetchSingle(t *testing.T) {
 
 	fields := []resource.FieldConfig{{Name: "title"}}
 	vc := &resource.VersionConfig{Fields: fields}
-	plan := buildPlanForVersion(prov, "product", vc)
+	plan := buildPlanForVersion(planEnv{provider: prov}, "product", vc)
 
 	ch := plan.Execute(context.Background(), projection.BuildRequest{
 		ResourceType: "product",
@@ -162,6 +174,7 @@ func TestBuildPlanForVersion_FetchSingle(t *testing.T) {
 	require.Len(t, docs, 1)
 	require.Equal(t, "product", docs[0].Root.Type)
 	require.Equal(t, "1", docs[0].Root.Id)
+	require.Equal(t, "1", docs[0].Doc["id"])
 	require.Equal(t, "Widget", docs[0].Doc["fields"].(map[string]any)["title"])
 }
 
@@ -170,7 +183,7 @@ func TestBuildPlanForVersion_FetchSingle_NotFound(t *testing.T) {
 
 	fields := []resource.FieldConfig{{Name: "title"}}
 	vc := &resource.VersionConfig{Fields: fields}
-	plan := buildPlanForVersion(prov, "product", vc)
+	plan := buildPlanForVersion(planEnv{provider: prov}, "product", vc)
 
 	ch := plan.Execute(context.Background(), projection.BuildRequest{
 		ResourceType: "product",
@@ -196,7 +209,7 @@ func TestBuildPlanForVersion_FetchAll_SinglePage(t *testing.T) {
 
 	fields := []resource.FieldConfig{{Name: "title"}}
 	vc := &resource.VersionConfig{Fields: fields}
-	plan := buildPlanForVersion(prov, "product", vc)
+	plan := buildPlanForVersion(planEnv{provider: prov}, "product", vc)
 
 	ch := plan.Execute(context.Background(), projection.BuildRequest{
 		ResourceType: "product",
@@ -227,7 +240,7 @@ func TestBuildPlanForVersion_FetchAll_MultiplePages(t *testing.T) {
 
 	fields := []resource.FieldConfig{{Name: "title"}}
 	vc := &resource.VersionConfig{Fields: fields}
-	plan := buildPlanForVersion(prov, "product", vc)
+	plan := buildPlanForVersion(planEnv{provider: prov}, "product", vc)
 
 	ch := plan.Execute(context.Background(), projection.BuildRequest{
 		ResourceType: "product",
@@ -255,7 +268,7 @@ func TestBuildPlanForVersion_FetchAll_Empty(t *testing.T) {
 
 	fields := []resource.FieldConfig{{Name: "title"}}
 	vc := &resource.VersionConfig{Fields: fields}
-	plan := buildPlanForVersion(prov, "product", vc)
+	plan := buildPlanForVersion(planEnv{provider: prov}, "product", vc)
 
 	ch := plan.Execute(context.Background(), projection.BuildRequest{
 		ResourceType: "product",
@@ -292,7 +305,7 @@ func TestBuildPlanForVersion_FetchAll_WithRelation(t *testing.T) {
 		},
 	}
 
-	plan := buildPlanForVersion(prov, "order", vc)
+	plan := buildPlanForVersion(planEnv{provider: prov}, "order", vc)
 
 	ch := plan.Execute(context.Background(), projection.BuildRequest{
 		ResourceType: "order",
@@ -337,7 +350,7 @@ func TestBuildPlansFromConfig_VersionedPlans(t *testing.T) {
 		},
 	}
 
-	plans := BuildPlansFromConfig(prov, cfgs)
+	plans := BuildPlansFromConfig(prov, nil, cfgs)
 	require.Len(t, plans, 1)
 	require.Len(t, plans["product"], 2)
 
@@ -371,3 +384,972 @@ func TestBuildPlansFromConfig_VersionedPlans(t *testing.T) {
 	require.Equal(t, "Widget", fields2["title"])
 	require.Equal(t, "9.99", fields2["price"])
 }
+
+// fakeGraph is a RelationGraph serving parents from a map of "child|parentType".
+type fakeGraph map[string][]model.Resource
+
+func (g fakeGraph) GetParentResourcesOfType(_ context.Context, child model.Resource, parentType string) ([]model.Resource, error) {
+	return g[child.Type+"|"+child.Id+"|"+parentType], nil
+}
+
+func reverseOrderVersion(source string) *resource.VersionConfig {
+	return &resource.VersionConfig{
+		Fields: []resource.FieldConfig{{Name: "name"}},
+		Relations: []resource.RelationConfig{
+			{
+				Resource: "order",
+				Reverse:  &resource.ReverseConfig{Field: "customer_id", Source: source},
+				Fields:   []resource.FieldConfig{{Name: "number"}},
+			},
+		},
+	}
+}
+
+func TestBuildPlanForVersion_ReverseRelation_Provider(t *testing.T) {
+	prov := newMockProvider()
+	prov.resources["customer|c1"] = map[string]any{"id": "c1", "name": "Alice"}
+	prov.related["order|c1"] = []map[string]any{
+		{"id": "o1", "number": "ORD-1", "customer_id": "c1"},
+		{"id": "o2", "number": "ORD-2", "customer_id": "c1"},
+	}
+
+	plan := buildPlanForVersion(planEnv{provider: prov}, "customer", reverseOrderVersion(""))
+	doc := executeSingle(t, plan, "customer", "c1")
+
+	orders, ok := doc.Doc["order"].([]map[string]any)
+	require.True(t, ok, "order field should be present")
+	require.Equal(t, []map[string]any{
+		{"id": "o1", "number": "ORD-1"},
+		{"id": "o2", "number": "ORD-2"},
+	}, orders)
+	require.Equal(t, []model.Resource{{Type: "order", Id: "o1"}, {Type: "order", Id: "o2"}}, doc.Relations)
+}
+
+func TestBuildPlanForVersion_ReverseRelation_Store(t *testing.T) {
+	prov := newMockProvider()
+	prov.resources["customer|c1"] = map[string]any{"id": "c1", "name": "Alice"}
+	prov.resources["order|o1"] = map[string]any{"number": "ORD-1", "customer_id": "c1"}
+	// Moved to another customer since it was last built.
+	prov.resources["order|o2"] = map[string]any{"number": "ORD-2", "customer_id": "c2"}
+
+	graph := fakeGraph{
+		"customer|c1|order": {{Type: "order", Id: "o1"}, {Type: "order", Id: "o2"}, {Type: "order", Id: "o3"}},
+	}
+
+	plan := buildPlanForVersion(planEnv{provider: prov, graph: graph}, "customer", reverseOrderVersion(resource.ReverseSourceStore))
+	doc := executeSingle(t, plan, "customer", "c1")
+
+	require.Equal(t, []map[string]any{{"id": "o1", "number": "ORD-1"}}, doc.Doc["order"])
+	require.Equal(t, []model.Resource{{Type: "order", Id: "o1"}}, doc.Relations)
+}
+
+func TestBuildPlansFromConfig_RecordsReverseReferences(t *testing.T) {
+	prov := newMockProvider()
+	prov.resources["order|o1"] = map[string]any{"id": "o1", "number": "ORD-1", "customer_id": "c1"}
+
+	cfgs := resource.Configs{
+		{Resource: "customer", Versions: []resource.VersionConfig{*reverseOrderVersion("")}},
+		{Resource: "order", Versions: []resource.VersionConfig{{Fields: []resource.FieldConfig{{Name: "number"}}}}},
+	}
+
+	plans := BuildPlansFromConfig(prov, nil, cfgs)
+	doc := executeSingle(t, plans["order"][0], "order", "o1")
+
+	// The order is a child of the customer embedding it, so that changes to
+	// the order's reference reach the customer.
+	require.Equal(t, []model.Resource{{Type: "customer", Id: "c1"}}, doc.Relations)
+}
+
+func executeSingle(t *testing.T, plan projection.Plan, resourceType, id string) projection.BuildDoc {
+	t.Helper()
+
+	var docs []projection.BuildDoc
+	for r := range plan.Execute(context.Background(), projection.BuildRequest{ResourceType: resourceType, ResourceID: id}) {
+		require.NoError(t, r.Err)
+		docs = append(docs, r.Items...)
+	}
+	require.Len(t, docs, 1)
+	return docs[0]
+}
+
+func TestBuildPlanForVersion_RelationsOfRelations(t *testing.T) {
+	prov := newMockProvider()
+	prov.resources["order|1"] = map[string]any{"id": "1", "number": "ORD-1"}
+	prov.related["line|1"] = []map[string]any{
+		{"id": "l1", "quantity": 2, "product_id": "p1"},
+		{"id": "l2", "quantity": 1, "product_id": "p2"},
+	}
+	prov.related["product|p1"] = []map[string]any{{"id": "p1", "name": "Widget"}}
+	prov.related["product|p2"] = []map[string]any{{"id": "p2", "name": "Gadget"}}
+
+	vc := &resource.VersionConfig{
+		Fields: []resource.FieldConfig{{Name: "number"}},
+		Relations: []resource.RelationConfig{
+			{
+				Resource: "line",
+				Key:      resource.KeyConfig{Source: "order", Field: "id"},
+				Fields:   []resource.FieldConfig{{Name: "quantity"}},
+				Relations: []resource.RelationConfig{
+					{
+						Resource:    "product",
+						Key:         resource.KeyConfig{Source: "line", Field: "product_id"},
+						Cardinality: "one",
+						Fields:      []resource.FieldConfig{{Name: "name"}},
+					},
+				},
+			},
+		},
+	}
+
+	plan := buildPlanForVersion(planEnv{provider: prov}, "order", vc)
+	doc := executeSingle(t, plan, "order", "1")
+
+	require.Equal(t, []map[string]any{
+		{"id": "l1", "quantity": 2, "product": []map[string]any{{"id": "p1", "name": "Widget"}}},
+		{"id": "l2", "quantity": 1, "product": []map[string]any{{"id": "p2", "name": "Gadget"}}},
+	}, doc.Doc["line"])
+
+	// Every hop is recorded so that a product change reaches the order.
+	require.ElementsMatch(t, []model.Resource{
+		{Type: "line", Id: "l1"}, {Type: "product", Id: "p1"},
+		{Type: "line", Id: "l2"}, {Type: "product", Id: "p2"},
+	}, doc.Relations)
+}
+
+func TestBuildPlanForVersion_RelationAliases(t *testing.T) {
+	prov := newMockProvider()
+	prov.resources["ticket|1"] = map[string]any{"id": "1", "assignee_id": "u1", "reporter_id": "u2"}
+	prov.related["user|u1"] = []map[string]any{{"id": "u1", "name": "Alice", "team_id": "t1"}}
+	prov.related["user|u2"] = []map[string]any{{"id": "u2", "name": "Bob", "team_id": "t2"}}
+	prov.related["team|t1"] = []map[string]any{{"id": "t1", "name": "Platform"}}
+
+	vc := &resource.VersionConfig{
+		Relations: []resource.RelationConfig{
+			{
+				Resource:    "user",
+				As:          "assignee",
+				Key:         resource.KeyConfig{Source: "ticket", Field: "assignee_id"},
+				Cardinality: "one",
+				Fields:      []resource.FieldConfig{{Name: "name"}},
+			},
+			{
+				Resource:    "user",
+				As:          "reporter",
+				Key:         resource.KeyConfig{Source: "ticket", Field: "reporter_id"},
+				Cardinality: "one",
+				Fields:      []resource.FieldConfig{{Name: "name"}},
+			},
+			{
+				// Keyed by the aliased relation.
+				Resource:    "team",
+				As:          "assignee_team",
+				Key:         resource.KeyConfig{Source: "assignee", Field: "team_id"},
+				Cardinality: "one",
+				Fields:      []resource.FieldConfig{{Name: "name"}},
+			},
+		},
+	}
+
+	plan := buildPlanForVersion(planEnv{provider: prov}, "ticket", vc)
+	doc := executeSingle(t, plan, "ticket", "1")
+
+	require.Equal(t, []map[string]any{{"id": "u1", "name": "Alice"}}, doc.Doc["assignee"])
+	require.Equal(t, []map[string]any{{"id": "u2", "name": "Bob"}}, doc.Doc["reporter"])
+	require.Equal(t, []map[string]any{{"id": "t1", "name": "Platform"}}, doc.Doc["assignee_team"])
+	require.NotContains(t, doc.Doc, "user")
+	require.ElementsMatch(t, []model.Resource{
+		{Type: "user", Id: "u1"}, {Type: "user", Id: "u2"}, {Type: "team", Id: "t1"},
+	}, doc.Relations)
+}
+
+func TestBuildPlanForVersion_ArrayKey(t *testing.T) {
+	prov := newMockProvider()
+	prov.resources["article|1"] = map[string]any{"id": "1", "tag_ids": []any{"t1", "t2"}}
+	prov.related["tag|t1"] = []map[string]any{{"id": "t1", "label": "go"}}
+	prov.related["tag|t2"] = []map[string]any{{"id": "t2", "label": "search"}}
+
+	vc := &resource.VersionConfig{
+		Relations: []resource.RelationConfig{
+			{
+				Resource: "tag",
+				Key:      resource.KeyConfig{Source: "article", Field: "tag_ids"},
+				Fields:   []resource.FieldConfig{{Name: "label"}},
+			},
+		},
+	}
+
+	plan := buildPlanForVersion(planEnv{provider: prov}, "article", vc)
+	doc := executeSingle(t, plan, "article", "1")
+
+	require.Equal(t, []map[string]any{
+		{"id": "t1", "label": "go"},
+		{"id": "t2", "label": "search"},
+	}, doc.Doc["tag"])
+}
+
+func TestBuildPlanForVersion_ComputedFields(t *testing.T) {
+	prov := newMockProvider()
+	prov.resources["customer|1"] = map[string]any{
+		"id": "1", "first_name": "Ada", "last_name": "Lovelace", "created_at": "2024-05-17T13:45:00Z",
+		// Computed fields are not copied from the provider data.
+		"full_name": "stale",
+	}
+	prov.related["order|1"] = []map[string]any{
+		{"id": "o1", "status": "open", "total": 10.5},
+		{"id": "o2", "status": "closed", "total": 4.5},
+	}
+
+	vc := &resource.VersionConfig{
+		Fields: []resource.FieldConfig{
+			{Name: "first_name"},
+			{Name: "full_name", Computed: `concat(first_name, " ", last_name)`},
+			{Name: "order_count", Computed: `count(orders)`},
+			{Name: "has_open_orders", Computed: `any(orders, status == "open")`},
+			{Name: "largest_order", Computed: `max(orders.total)`},
+			{Name: "signup_month", Computed: `date_trunc("month", created_at)`},
+			// Refers to a computed field defined before it.
+			{Name: "greeting", Computed: `"Hello " + full_name`},
+			{Name: "nickname", Computed: `lower(nickname)`},
+		},
+		Relations: []resource.RelationConfig{
+			{
+				Resource: "order",
+				As:       "orders",
+				Key:      resource.KeyConfig{Source: "customer", Field: "id"},
+				Fields:   []resource.FieldConfig{{Name: "status"}},
+			},
+		},
+	}
+
+	plan := buildPlanForVersion(planEnv{provider: prov}, "customer", vc)
+	doc := executeSingle(t, plan, "customer", "1")
+
+	require.Equal(t, map[string]any{
+		"first_name":      "Ada",
+		"full_name":       "Ada Lovelace",
+		"order_count":     int64(2),
+		"has_open_orders": true,
+		"largest_order":   10.5,
+		"signup_month":    "2024-05-01T00:00:00Z",
+		"greeting":        "Hello Ada Lovelace",
+	}, doc.Doc["fields"])
+}
+
+func TestBuildPlanForVersion_ComputedFields_EvalError(t *testing.T) {
+	prov := newMockProvider()
+	prov.resources["customer|1"] = map[string]any{"id": "1", "name": "Ada"}
+
+	vc := &resource.VersionConfig{
+		Fields: []resource.FieldConfig{
+			{Name: "name"},
+			{Name: "double_name", Type: "double", Computed: `name * 2`},
+			// Sees the failed field as null.
+			{Name: "has_double_name", Computed: `double_name != null`},
+		},
+	}
+
+	for _, policy := range []string{resource.CoercionFail, resource.CoercionNull} {
+		t.Run(policy, func(t *testing.T) {
+			env := planEnv{
+				provider: prov,
+				coercion: map[string]resource.CoercionConfig{"customer": {Policy: policy}},
+			}
+			plan := buildPlanForVersion(env, "customer", vc)
+			doc := executeSingle(t, plan, "customer", "1")
+
+			want := map[string]any{"name": "Ada", "has_double_name": false}
+			if policy == resource.CoercionNull {
+				want["double_name"] = nil
+			}
+			require.Equal(t, want, doc.Doc["fields"])
+			require.Equal(t, []projection.Violation{{
+				Field:  "fields.double_name",
+				Type:   "double",
+				Value:  "name * 2",
+				Reason: "operator * is not supported on a string and a number",
+			}}, doc.Violations)
+		})
+	}
+}
+
+func TestFilterFields_SourcePaths(t *testing.T) {
+	data := map[string]any{
+		"name": "Ada",
+		"address": map[string]any{
+			"city": "London",
+			"geo":  map[string]any{"lat": 51.5},
+		},
+		"tags": []any{"math", "poetry"},
+		"lines": []any{
+			map[string]any{"sku": "a"},
+			map[string]any{"sku": "b"},
+			map[string]any{"qty": 1.0},
+		},
+		"meta": map[string]any{"content.type": "json"},
+	}
+
+	fields := []resource.FieldConfig{
+		{Name: "name"},
+		{Name: "city", Source: "address.city"},
+		{Name: "lat", Source: "$.address.geo.lat"},
+		{Name: "first_tag", Source: "tags[0]"},
+		{Name: "skus", Source: "lines[*].sku"},
+		{Name: "content_type", Source: "meta['content.type']"},
+		{Name: "zip", Source: "address.zip"},
+		{Name: "third_tag", Source: "tags[2]"},
+	}
+
+	filtered, violations := filterFields(data, fields, "fields", resource.CoercionConfig{})
+	require.Empty(t, violations)
+	require.Equal(t, map[string]any{
+		"name":         "Ada",
+		"city":         "London",
+		"lat":          51.5,
+		"first_tag":    "math",
+		"skus":         []any{"a", "b"},
+		"content_type": "json",
+	}, filtered)
+}
+
+func TestBuildPlanForVersion_SourcePaths(t *testing.T) {
+	prov := newMockProvider()
+	prov.resources["customer|1"] = map[string]any{
+		"id":         "1",
+		"country_id": "uk",
+		"address":    map[string]any{"city": "London"},
+	}
+	prov.related["country|uk"] = []map[string]any{
+		{"id": "uk", "names": map[string]any{"en": "United Kingdom"}},
+	}
+
+	vc := &resource.VersionConfig{
+		Fields: []resource.FieldConfig{
+			{Name: "city", Source: "address.city"},
+		},
+		Relations: []resource.RelationConfig{
+			{
+				Resource: "country",
+				Key:      resource.KeyConfig{Source: "customer", Field: "country_id"},
+				Fields:   []resource.FieldConfig{{Name: "name", Source: "names.en"}},
+			},
+		},
+	}
+
+	plan := buildPlanForVersion(planEnv{provider: prov}, "customer", vc)
+	doc := executeSingle(t, plan, "customer", "1")
+
+	require.Equal(t, map[string]any{"city": "London"}, doc.Doc["fields"])
+	require.Equal(t, []map[string]any{{"id": "uk", "name": "United Kingdom"}}, doc.Doc["country"])
+}
+
+func TestBuildPlanForVersion_CoercionViolations(t *testing.T) {
+	prov := newMockProvider()
+	prov.resources["order|1"] = map[string]any{"id": "1", "total": "12.5", "placed_at": "yesterday"}
+	prov.related["line|1"] = []map[string]any{
+		{"id": "l1", "quantity": "2"},
+		{"id": "l2", "quantity": "two"},
+	}
+
+	vc := &resource.VersionConfig{
+		Fields: []resource.FieldConfig{
+			{Name: "total", Type: "double"},
+			{Name: "placed_at", Type: "date"},
+		},
+		Relations: []resource.RelationConfig{
+			{
+				Resource: "line",
+				As:       "lines",
+				Key:      resource.KeyConfig{Source: "order", Field: "id"},
+				Fields:   []resource.FieldConfig{{Name: "quantity", Type: "integer"}},
+			},
+		},
+	}
+
+	env := planEnv{
+		provider: prov,
+		coercion: map[string]resource.CoercionConfig{"order": {Policy: resource.CoercionNull}},
+	}
+	plan := buildPlanForVersion(env, "order", vc)
+	doc := executeSingle(t, plan, "order", "1")
+
+	require.Equal(t, map[string]any{"total": 12.5, "placed_at": nil}, doc.Doc["fields"])
+	require.Equal(t, []map[string]any{
+		{"id": "l1", "quantity": int64(2)},
+		{"id": "l2", "quantity": nil},
+	}, doc.Doc["lines"])
+	require.Equal(t, []projection.Violation{
+		{
+			Field:  "fields.placed_at",
+			Type:   "date",
+			Value:  "yesterday",
+			Reason: "does not match any of the formats strict_date_optional_time, epoch_millis",
+		},
+		{Field: "lines.quantity", Type: "integer", Value: "two", Reason: "not a number"},
+	}, doc.Violations)
+}
+
+func TestFilterFields_NoCoercionPolicy(t *testing.T) {
+	data := map[string]any{"total": "12.5", "placed_at": "yesterday"}
+	fields := []resource.FieldConfig{
+		{Name: "total", Type: "double"},
+		{Name: "placed_at", Type: "date"},
+	}
+
+	// Without a policy the values are indexed as provided.
+	filtered, violations := filterFields(data, fields, "fields", resource.CoercionConfig{})
+	require.Empty(t, violations)
+	require.Equal(t, data, filtered)
+}
+
+func TestFilterFields_ObjectFields(t *testing.T) {
+	data := map[string]any{
+		"address": map[string]any{"city": "London", "zip": "N1", "geo": map[string]any{"lat": "51.5", "lon": "-0.1"}},
+		"items": []any{
+			map[string]any{"sku": "a", "qty": "2", "internal": true},
+			map[string]any{"sku": "b", "qty": "x"},
+		},
+		"tags": "not an object",
+	}
+	fields := []resource.FieldConfig{
+		{Name: "address", Type: "object", Fields: []resource.FieldConfig{
+			{Name: "city"},
+			{Name: "location", Type: "geo_point", Source: "geo"},
+		}},
+		{Name: "items", Type: "nested", Fields: []resource.FieldConfig{
+			{Name: "sku"},
+			{Name: "qty", Type: "integer"},
+		}},
+		{Name: "tags", Type: "object", Fields: []resource.FieldConfig{{Name: "label"}}},
+	}
+
+	filtered, violations := filterFields(data, fields, "fields", resource.CoercionConfig{Policy: resource.CoercionDrop})
+	require.Equal(t, map[string]any{
+		"address": map[string]any{"city": "London", "location": map[string]any{"lat": 51.5, "lon": -0.1}},
+		"items": []any{
+			map[string]any{"sku": "a", "qty": int64(2)},
+			map[string]any{"sku": "b"},
+		},
+	}, filtered)
+	require.Equal(t, []projection.Violation{
+		{Field: "fields.items.qty", Type: "integer", Value: "x", Reason: "not a number"},
+		{Field: "fields.tags", Type: "object", Value: "not an object", Reason: "expected an object, got string"},
+	}, violations)
+}
+
+func TestBuildPlanForVersion_RelationSelection(t *testing.T) {
+	prov := newMockProvider()
+	prov.resources["customer|1"] = map[string]any{"id": "1"}
+	prov.related["order|1"] = []map[string]any{
+		{"id": "o1", "status": "open", "placed_at": "2024-01-01"},
+		{"id": "o2", "status": "closed", "placed_at": "2024-04-01"},
+		{"id": "o3", "status": "open", "placed_at": "2024-03-01"},
+		{"id": "o4", "status": "open", "placed_at": "2024-02-01"},
+	}
+
+	vc := &resource.VersionConfig{
+		Relations: []resource.RelationConfig{
+			{
+				Resource: "order",
+				As:       "recent_open_orders",
+				Key:      resource.KeyConfig{Source: "customer", Field: "id"},
+				Fields:   []resource.FieldConfig{{Name: "placed_at"}},
+				Where:    `status == "open"`,
+				OrderBy:  []resource.OrderByConfig{{Field: "placed_at", Order: resource.OrderDesc}},
+				Limit:    2,
+			},
+		},
+	}
+
+	plan := buildPlanForVersion(planEnv{provider: prov}, "customer", vc)
+	doc := executeSingle(t, plan, "customer", "1")
+
+	require.Equal(t, []map[string]any{
+		{"id": "o3", "placed_at": "2024-03-01"},
+		{"id": "o4", "placed_at": "2024-02-01"},
+	}, doc.Doc["recent_open_orders"])
+	require.Len(t, doc.Resolved["recent_open_orders"], 2)
+
+	// All orders are recorded, so a change to any of them rebuilds the
+	// customer.
+	require.ElementsMatch(t, []model.Resource{
+		{Type: "order", Id: "o1"}, {Type: "order", Id: "o2"},
+		{Type: "order", Id: "o3"}, {Type: "order", Id: "o4"},
+	}, doc.Relations)
+
+	require.Equal(t, source.RelationHints{
+		Where:   `status == "open"`,
+		OrderBy: []source.OrderBy{{Field: "placed_at", Descending: true}},
+		Limit:   2,
+	}, prov.lastFetchRelatedHints)
+}
+
+func TestResolveLevels(t *testing.T) {
+	rels := []resource.RelationConfig{
+		{Resource: "product", Key: resource.KeyConfig{Source: "line", Field: "product_id"}},
+		{Resource: "line", Key: resource.KeyConfig{Source: "order", Field: "id"}},
+		{Resource: "customer", Key: resource.KeyConfig{Source: "order", Field: "customer_id"}},
+		{Resource: "note", Reverse: &resource.ReverseConfig{Field: "order_id"}},
+		{Resource: "supplier", Key: resource.KeyConfig{Source: "product", Field: "supplier_id"}},
+	}
+
+	ordered, err := resolveOrder("order", rels)
+	require.NoError(t, err)
+
+	var names [][]string
+	for _, level := range resolveLevels("order", ordered) {
+		var levelNames []string
+		for _, rel := range level {
+			levelNames = append(levelNames, rel.Name())
+		}
+		names = append(names, levelNames)
+	}
+	require.Equal(t, [][]string{
+		{"line", "customer", "note"},
+		{"product"},
+		{"supplier"},
+	}, names)
+}
+
+func TestBuildPlanForVersion_Concurrency(t *testing.T) {
+	prov := newMockProvider()
+	for i := range 20 {
+		id := fmt.Sprint(i)
+		prov.listed["order"] = append(prov.listed["order"], source.ListedResource{
+			ID:   id,
+			Data: map[string]any{"id": id, "customer_id": "c" + id},
+		})
+		prov.related["customer|c"+id] = []map[string]any{{"id": "c" + id, "name": "Customer " + id, "region_id": "r" + id}}
+		prov.related["line|"+id] = []map[string]any{{"id": "l" + id, "quantity": i}}
+		prov.related["region|r"+id] = []map[string]any{{"id": "r" + id, "name": "Region " + id}}
+	}
+
+	vc := &resource.VersionConfig{
+		Relations: []resource.RelationConfig{
+			{
+				Resource: "region",
+				Key:      resource.KeyConfig{Source: "customer", Field: "region_id"},
+				Fields:   []resource.FieldConfig{{Name: "name"}},
+			},
+			{
+				Resource: "customer",
+				Key:      resource.KeyConfig{Source: "order", Field: "customer_id"},
+				Fields:   []resource.FieldConfig{{Name: "name"}},
+			},
+			{
+				Resource: "line",
+				Key:      resource.KeyConfig{Source: "order", Field: "id"},
+				Fields:   []resource.FieldConfig{{Name: "quantity"}},
+			},
+		},
+	}
+
+	env := planEnv{provider: prov, concurrency: map[string]int{"order": 4}}
+	plan := buildPlanForVersion(env, "order", vc)
+
+	var docs []projection.BuildDoc
+	for r := range plan.Execute(context.Background(), projection.BuildRequest{ResourceType: "order"}) {
+		require.NoError(t, r.Err)
+		docs = append(docs, r.Items...)
+	}
+
+	require.Len(t, docs, 20)
+	for i, doc := range docs {
+		id := fmt.Sprint(i)
+		require.Equal(t, id, doc.Root.Id)
+		require.Equal(t, []map[string]any{{"id": "c" + id, "name": "Customer " + id}}, doc.Doc["customer"])
+		require.Equal(t, []map[string]any{{"id": "l" + id, "quantity": i}}, doc.Doc["line"])
+		require.Equal(t, []map[string]any{{"id": "r" + id, "name": "Region " + id}}, doc.Doc["region"])
+	}
+}
+
+// batchMockProvider is a mockProvider implementing source.BatchProvider.
+type batchMockProvider struct {
+	*mockProvider
+	unsupported      bool
+	batches          []source.FetchRelatedBatchParams
+	resourceBatches  [][]string
+	fetchedResources int
+}
+
+func (m *batchMockProvider) FetchResource(ctx context.Context, params source.FetchResourceParams) (source.FetchResourceResult, error) {
+	m.mu.Lock()
+	m.fetchedResources++
+	m.mu.Unlock()
+	return m.mockProvider.FetchResource(ctx, params)
+}
+
+func (m *batchMockProvider) FetchResources(_ context.Context, params source.FetchResourcesParams) (source.FetchResourcesResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.unsupported {
+		return source.FetchResourcesResult{}, source.ErrBatchUnsupported
+	}
+	m.resourceBatches = append(m.resourceBatches, params.ResourceIDs)
+	data := make(map[string]map[string]any)
+	for _, id := range params.ResourceIDs {
+		if d, ok := m.resources[params.ResourceType+"|"+id]; ok {
+			data[id] = d
+		}
+	}
+	return source.FetchResourcesResult{Data: data}, nil
+}
+
+func (m *batchMockProvider) FetchRelatedBatch(_ context.Context, params source.FetchRelatedBatchParams) (source.FetchRelatedBatchResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.unsupported {
+		return source.FetchRelatedBatchResult{}, source.ErrBatchUnsupported
+	}
+	m.batches = append(m.batches, params)
+	related := make([][]map[string]any, len(params.Lookups))
+	for i, l := range params.Lookups {
+		related[i] = m.related[params.ResourceType+"|"+l.Key.Value]
+	}
+	return source.FetchRelatedBatchResult{Related: related}, nil
+}
+
+func TestBuildPlanForVersion_BatchProvider(t *testing.T) {
+	for _, unsupported := range []bool{false, true} {
+		t.Run(fmt.Sprintf("unsupported=%v", unsupported), func(t *testing.T) {
+			prov := &batchMockProvider{mockProvider: newMockProvider(), unsupported: unsupported}
+			for _, id := range []string{"1", "2", "3"} {
+				prov.listed["order"] = append(prov.listed["order"], source.ListedResource{
+					ID:   id,
+					Data: map[string]any{"id": id, "customer_ids": []any{"c" + id, "shared"}},
+				})
+				prov.related["customer|c"+id] = []map[string]any{{"id": "c" + id, "name": "Customer " + id}}
+			}
+			// Returned for two keys of every order, but embedded once.
+			prov.related["customer|shared"] = []map[string]any{{"id": "shared", "name": "Shared"}, {"id": "c1", "name": "Customer 1"}}
+
+			vc := &resource.VersionConfig{
+				Relations: []resource.RelationConfig{
+					{
+						Resource: "customer",
+						Key:      resource.KeyConfig{Source: "order", Field: "customer_ids"},
+						Fields:   []resource.FieldConfig{{Name: "name"}},
+					},
+				},
+			}
+
+			plan := buildPlanForVersion(planEnv{provider: prov}, "order", vc)
+
+			var docs []projection.BuildDoc
+			for r := range plan.Execute(context.Background(), projection.BuildRequest{ResourceType: "order", Metadata: map[string]string{"tenant": "t1"}}) {
+				require.NoError(t, r.Err)
+				docs = append(docs, r.Items...)
+			}
+
+			require.Len(t, docs, 3)
+			require.Equal(t, []map[string]any{
+				{"id": "c1", "name": "Customer 1"},
+				{"id": "shared", "name": "Shared"},
+			}, docs[0].Doc["customer"])
+			require.Equal(t, []map[string]any{
+				{"id": "c2", "name": "Customer 2"},
+				{"id": "shared", "name": "Shared"},
+				{"id": "c1", "name": "Customer 1"},
+			}, docs[1].Doc["customer"])
+
+			if unsupported {
+				require.Empty(t, prov.batches)
+				return
+			}
+			// One call for the whole page, with a lookup per key.
+			require.Len(t, prov.batches, 1)
+			require.Len(t, prov.batches[0].Lookups, 6)
+			require.Equal(t, source.RootResource{Type: "order", Id: "2"}, prov.batches[0].Lookups[2].RootResource)
+			require.Equal(t, map[string]string{"tenant": "t1"}, prov.batches[0].Metadata)
+		})
+	}
+}
+
+func TestBuildPlanForVersion_RelationsOfRelationsBatch(t *testing.T) {
+	for _, unsupported := range []bool{false, true} {
+		t.Run(fmt.Sprintf("unsupported=%v", unsupported), func(t *testing.T) {
+			prov := &batchMockProvider{mockProvider: newMockProvider(), unsupported: unsupported}
+			prov.resources["order|1"] = map[string]any{"id": "1"}
+			prov.related["line|1"] = []map[string]any{
+				{"id": "l1", "product_id": "p1"},
+				{"id": "l2", "product_id": "p2"},
+				{"id": "l3"},
+			}
+			prov.related["product|p1"] = []map[string]any{{"id": "p1", "name": "Widget"}}
+			prov.related["product|p2"] = []map[string]any{{"id": "p2", "name": "Gadget"}}
+
+			vc := &resource.VersionConfig{
+				Relations: []resource.RelationConfig{
+					{
+						Resource: "line",
+						Key:      resource.KeyConfig{Source: "order", Field: "id"},
+						Relations: []resource.RelationConfig{
+							{
+								Resource:    "product",
+								Key:         resource.KeyConfig{Source: "line", Field: "product_id"},
+								Cardinality: "one",
+								Fields:      []resource.FieldConfig{{Name: "name"}},
+							},
+						},
+					},
+				},
+			}
+
+			plan := buildPlanForVersion(planEnv{provider: prov}, "order", vc)
+			doc := executeSingle(t, plan, "order", "1")
+
+			require.Equal(t, []map[string]any{
+				{"id": "l1", "product": []map[string]any{{"id": "p1", "name": "Widget"}}},
+				{"id": "l2", "product": []map[string]any{{"id": "p2", "name": "Gadget"}}},
+				{"id": "l3", "product": []map[string]any{}},
+			}, doc.Doc["line"])
+
+			if unsupported {
+				require.Empty(t, prov.batches)
+				return
+			}
+			// The products of all lines are fetched in one call.
+			require.Len(t, prov.batches, 2)
+			require.Equal(t, "product", prov.batches[1].ResourceType)
+			require.Len(t, prov.batches[1].Lookups, 2)
+		})
+	}
+}
+
+func TestRelationFetcher_FetchBatch_RelationsOfRelations(t *testing.T) {
+	prov := &batchMockProvider{mockProvider: newMockProvider()}
+	rel := newPlannedRelation(resource.RelationConfig{
+		Resource: "line",
+		Key:      resource.KeyConfig{Source: "order", Field: "id"},
+		Relations: []resource.RelationConfig{
+			{
+				Resource: "product",
+				Key:      resource.KeyConfig{Source: "line", Field: "product_id"},
+				Fields:   []resource.FieldConfig{{Name: "name"}},
+			},
+		},
+	})
+
+	tenants := []string{"t1", "t2", "t1"}
+	parents := make([]projection.BuildDoc, len(tenants))
+	for i, tenant := range tenants {
+		id := fmt.Sprint(i + 1)
+		parents[i] = projection.BuildDoc{
+			Root:     model.Resource{Type: "order", Id: id},
+			Metadata: map[string]string{"tenant": tenant},
+			Doc:      map[string]any{},
+			Resolved: map[string][]map[string]any{"order": {{"id": id}}},
+		}
+		prov.related["line|"+id] = []map[string]any{{"id": "l" + id, "product_id": "p" + id}}
+		prov.related["product|p"+id] = []map[string]any{{"id": "p" + id, "name": "Product " + id}}
+	}
+
+	f := &relationFetcher{provider: prov, rel: rel}
+	results, err := f.FetchBatch(context.Background(), parents)
+	require.NoError(t, err)
+
+	require.Len(t, results, 3)
+	for i, r := range results {
+		fr := r.(*fetchedRelation)
+		id := fmt.Sprint(i + 1)
+		require.Equal(t, []map[string]any{{"id": "l" + id, "product_id": "p" + id}}, fr.Related)
+		require.Equal(t, []map[string]any{{"id": "p" + id, "name": "Product " + id}}, fr.Children[0]["product"].Related)
+	}
+
+	// One call per relation and tenant, never one per parent.
+	require.Len(t, prov.batches, 4)
+	for i, want := range []struct {
+		resource string
+		tenant   string
+		roots    []string
+	}{
+		{"line", "t1", []string{"1", "3"}},
+		{"line", "t2", []string{"2"}},
+		{"product", "t1", []string{"1", "3"}},
+		{"product", "t2", []string{"2"}},
+	} {
+		b := prov.batches[i]
+		require.Equal(t, want.resource, b.ResourceType)
+		require.Equal(t, map[string]string{"tenant": want.tenant}, b.Metadata)
+		var roots []string
+		for _, l := range b.Lookups {
+			roots = append(roots, l.RootResource.Id)
+		}
+		require.Equal(t, want.roots, roots)
+	}
+}
+
+func TestBuildPlanForVersion_FetchByIDs(t *testing.T) {
+	for _, unsupported := range []bool{false, true} {
+		t.Run(fmt.Sprintf("unsupported=%v", unsupported), func(t *testing.T) {
+			prov := &batchMockProvider{mockProvider: newMockProvider(), unsupported: unsupported}
+			var ids []string
+			for i := range 250 {
+				id := fmt.Sprint(i)
+				ids = append(ids, id)
+				// Every tenth resource no longer exists.
+				if i%10 != 9 {
+					prov.resources["order|"+id] = map[string]any{"id": id, "number": "ORD-" + id}
+				}
+			}
+
+			vc := &resource.VersionConfig{Fields: []resource.FieldConfig{{Name: "number"}}}
+			plan := buildPlanForVersion(planEnv{provider: prov}, "order", vc)
+
+			var pages [][]projection.BuildDoc
+			for r := range plan.Execute(context.Background(), projection.BuildRequest{ResourceType: "order", ResourceIDs: ids}) {
+				require.NoError(t, r.Err)
+				pages = append(pages, r.Items)
+			}
+
+			require.Len(t, pages, 3)
+			require.Len(t, pages[0], 100)
+			require.Len(t, pages[2], 50)
+			var docs []projection.BuildDoc
+			for _, page := range pages {
+				docs = append(docs, page...)
+			}
+			for i, doc := range docs {
+				require.Equal(t, ids[i], doc.Root.Id)
+				if i%10 == 9 {
+					require.Nil(t, doc.Doc)
+					continue
+				}
+				require.Equal(t, "ORD-"+ids[i], doc.Doc["fields"].(map[string]any)["number"])
+			}
+
+			if unsupported {
+				require.Empty(t, prov.resourceBatches)
+				require.Equal(t, 250, prov.fetchedResources)
+				return
+			}
+			require.Len(t, prov.resourceBatches, 3)
+			require.Equal(t, ids[200:], prov.resourceBatches[2])
+			require.Zero(t, prov.fetchedResources)
+		})
+	}
+}
+
+// ctxCheckingProvider fails every call whose context lacks the test value.
+type ctxCheckingProvider struct {
+	*mockProvider
+}
+
+type ctxKey struct{}
+
+func checkCtx(ctx context.Context) error {
+	if ctx.Value(ctxKey{}) == nil {
+		return fmt.Errorf("context not propagated")
+	}
+	return nil
+}
+
+func (p *ctxCheckingProvider) FetchResource(ctx context.Context, params source.FetchResourceParams) (source.FetchResourceResult, error) {
+	if err := checkCtx(ctx); err != nil {
+		return source.FetchResourceResult{}, err
+	}
+	return p.mockProvider.FetchResource(ctx, params)
+}
+
+func (p *ctxCheckingProvider) FetchRelated(ctx context.Context, params source.FetchRelatedParams) (source.FetchRelatedResult, error) {
+	if err := checkCtx(ctx); err != nil {
+		return source.FetchRelatedResult{}, err
+	}
+	return p.mockProvider.FetchRelated(ctx, params)
+}
+
+func TestBuildPlanForVersion_PropagatesContext(t *testing.T) {
+	prov := &ctxCheckingProvider{mockProvider: newMockProvider()}
+	prov.resources["order|1"] = map[string]any{"id": "1", "number": "ORD-1"}
+	prov.related["line|1"] = []map[string]any{{"id": "l1", "product_id": "p1"}}
+	prov.related["product|p1"] = []map[string]any{{"id": "p1", "name": "Widget"}}
+
+	vc := &resource.VersionConfig{
+		Fields: []resource.FieldConfig{{Name: "number"}},
+		Relations: []resource.RelationConfig{
+			{
+				Resource: "line",
+				Key:      resource.KeyConfig{Source: "order", Field: "id"},
+				Relations: []resource.RelationConfig{
+					{
+						Resource: "product",
+						Key:      resource.KeyConfig{Source: "line", Field: "product_id"},
+						Fields:   []resource.FieldConfig{{Name: "name"}},
+					},
+				},
+			},
+		},
+	}
+
+	plan := buildPlanForVersion(planEnv{provider: prov}, "order", vc)
+	ctx := context.WithValue(context.Background(), ctxKey{}, true)
+
+	var docs []projection.BuildDoc
+	for r := range plan.Execute(ctx, projection.BuildRequest{ResourceType: "order", ResourceIDs: []string{"1"}}) {
+		require.NoError(t, r.Err)
+		docs = append(docs, r.Items...)
+	}
+	require.Len(t, docs, 1)
+	require.Equal(t, []map[string]any{
+		{"id": "l1", "product": []map[string]any{{"id": "p1", "name": "Widget"}}},
+	}, docs[0].Doc["line"])
+}
+
+func TestBuildPlanForVersion_Cancelled(t *testing.T) {
+	prov := &batchMockProvider{mockProvider: newMockProvider()}
+	prov.resources["order|1"] = map[string]any{"id": "1"}
+
+	plan := buildPlanForVersion(planEnv{provider: prov}, "order", &resource.VersionConfig{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for r := range plan.Execute(ctx, projection.BuildRequest{ResourceType: "order", ResourceIDs: []string{"1"}}) {
+		require.ErrorIs(t, r.Err, context.Canceled)
+	}
+	require.Empty(t, prov.resourceBatches)
+	require.Zero(t, prov.fetchedResources)
+}
+
+// relatedBatchOnlyProvider implements source.BatchProvider but not
+// source.ResourceBatchProvider.
+type relatedBatchOnlyProvider struct {
+	*mockProvider
+	batch *batchMockProvider
+}
+
+func (p *relatedBatchOnlyProvider) FetchRelatedBatch(ctx context.Context, params source.FetchRelatedBatchParams) (source.FetchRelatedBatchResult, error) {
+	return p.batch.FetchRelatedBatch(ctx, params)
+}
+
+func TestBuildPlanForVersion_RelatedBatchOnlyProvider(t *testing.T) {
+	batch := &batchMockProvider{mockProvider: newMockProvider()}
+	prov := &relatedBatchOnlyProvider{mockProvider: batch.mockProvider, batch: batch}
+	for _, id := range []string{"1", "2"} {
+		prov.resources["order|"+id] = map[string]any{"id": id}
+		prov.related["customer|"+id] = []map[string]any{{"id": "c" + id, "name": "Customer " + id}}
+	}
+
+	vc := &resource.VersionConfig{
+		Relations: []resource.RelationConfig{
+			{
+				Resource: "customer",
+				Key:      resource.KeyConfig{Source: "order", Field: "id"},
+				Fields:   []resource.FieldConfig{{Name: "name"}},
+			},
+		},
+	}
+
+	plan := buildPlanForVersion(planEnv{provider: prov}, "order", vc)
+
+	var docs []projection.BuildDoc
+	for r := range plan.Execute(context.Background(), projection.BuildRequest{ResourceType: "order", ResourceIDs: []string{"1", "2"}}) {
+		require.NoError(t, r.Err)
+		docs = append(docs, r.Items...)
+	}
+
+	// Roots are fetched one by one, relations still in one batch.
+	require.Len(t, docs, 2)
+	require.Equal(t, []map[string]any{{"id": "c2", "name": "Customer 2"}}, docs[1].Doc["customer"])
+	require.Len(t, batch.batches, 1)
+	require.Empty(t, batch.resourceBatches)
+}
@@ -2,60 +2,132 @@ package dsl
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
+	"github.com/theleeeo/indexer/aggregation"
 	"github.com/theleeeo/indexer/projection"
-	"github.com/theleeeo/indexer/resource"
 	"github.com/theleeeo/indexer/source"
 )
 
-// relationFetcher implements aggregation.SubFetcher[BuildDoc].
+// relationFetcher implements aggregation.BatchSubFetcher[BuildDoc]. A page is
+// fetched in one batch if the provider implements source.BatchProvider.
 type relationFetcher struct {
 	provider source.Provider
-	rel      resource.RelationConfig
+	rel      *plannedRelation
 }
 
-func (f *relationFetcher) Fetch(parent projection.BuildDoc) (any, error) {
+func (f *relationFetcher) Fetch(ctx context.Context, parent projection.BuildDoc) (any, error) {
 	if parent.Doc == nil {
 		return (*fetchedRelation)(nil), nil
 	}
 
-	sourceData, ok := parent.Resolved[f.rel.Key.Source]
-	if !ok || len(sourceData) == 0 {
+	keys, err := f.keys(parent)
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
 		return &fetchedRelation{}, nil
 	}
 
-	var key source.ResourceKey
-	if val, ok := sourceData[0][f.rel.Key.Field]; ok {
-		if valStr, ok := val.(string); ok {
-			key = source.ResourceKey{Field: f.rel.Key.Field, Value: valStr}
+	related, err := fetchByKeys(ctx, f.provider, parent, f.rel.Resource, keys, relationHints(f.rel.RelationConfig))
+	if err != nil {
+		return nil, fmt.Errorf("fetch related %s for %s/%s: %w", f.rel.Resource, parent.Root.Type, parent.Root.Id, err)
+	}
+
+	return f.newFetchedRelation(ctx, parent, related)
+}
+
+// FetchBatch fetches the related resources of every key of every parent in
+// one provider call per distinct metadata, and then the relations of the
+// selected items of all parents together.
+func (f *relationFetcher) FetchBatch(ctx context.Context, parents []projection.BuildDoc) ([]any, error) {
+	bp, ok := f.provider.(source.BatchProvider)
+	if !ok {
+		return nil, aggregation.ErrBatchUnsupported
+	}
+
+	results := make([]any, len(parents))
+	keys := make([][]source.ResourceKey, len(parents))
+	for i, parent := range parents {
+		if parent.Doc == nil {
+			results[i] = (*fetchedRelation)(nil)
+			continue
+		}
+
+		var err error
+		if keys[i], err = f.keys(parent); err != nil {
+			return nil, err
+		}
+		if len(keys[i]) == 0 {
+			results[i] = &fetchedRelation{}
 		}
 	}
-	if key.Value == "" {
-		return &fetchedRelation{}, nil
+
+	related, err := fetchRelatedBatch(ctx, bp, parents, f.rel, keys)
+	if errors.Is(err, source.ErrBatchUnsupported) {
+		return nil, aggregation.ErrBatchUnsupported
+	}
+	if err != nil {
+		return nil, fmt.Errorf("fetch related %s: %w", f.rel.Resource, err)
 	}
 
-	relatedResp, err := f.provider.FetchRelated(context.Background(), source.FetchRelatedParams{
-		RootResource: source.RootResource{
-			Type: parent.Root.Type,
-			Id:   parent.Root.Id,
-		},
-		ResourceType: f.rel.Resource,
-		Key:          key,
-		Metadata:     parent.Metadata,
-	})
+	var fetched []int
+	var fetchedParents []projection.BuildDoc
+	var fetchedRelated [][]map[string]any
+	for i, parent := range parents {
+		if results[i] == nil {
+			fetched = append(fetched, i)
+			fetchedParents = append(fetchedParents, parent)
+			fetchedRelated = append(fetchedRelated, related[i])
+		}
+	}
+	if len(fetched) == 0 {
+		return results, nil
+	}
+
+	frs, err := newFetchedRelations(ctx, f.provider, fetchedParents, f.rel, fetchedRelated)
 	if err != nil {
-		return nil, fmt.Errorf("fetch related %s for %s/%s: %w", f.rel.Resource, parent.Root.Type, parent.Root.Id, err)
+		return nil, err
 	}
+	for j, i := range fetched {
+		results[i] = frs[j]
+	}
+	return results, nil
+}
 
-	return &fetchedRelation{
-		ResourceType: f.rel.Resource,
-		Related:      relatedResp.Related,
-	}, nil
+// keys returns the lookup keys of the relation for parent.
+func (f *relationFetcher) keys(parent projection.BuildDoc) ([]source.ResourceKey, error) {
+	sourceData, ok := parent.Resolved[f.rel.Key.Source]
+	if !ok || len(sourceData) == 0 {
+		return nil, nil
+	}
+
+	keys, err := relationKeys(f.rel.Key, sourceData)
+	if err != nil {
+		return nil, fmt.Errorf("extract key of %s for %s/%s: %w", f.rel.Resource, parent.Root.Type, parent.Root.Id, err)
+	}
+	return keys, nil
+}
+
+func (f *relationFetcher) newFetchedRelation(ctx context.Context, parent projection.BuildDoc, related []map[string]any) (*fetchedRelation, error) {
+	fr, err := newFetchedRelation(ctx, f.provider, parent, f.rel, related)
+	if err != nil {
+		return nil, fmt.Errorf("%s/%s: %w", parent.Root.Type, parent.Root.Id, err)
+	}
+	return fr, nil
 }
 
 // fetchedRelation holds the raw data returned by a relation fetch.
 type fetchedRelation struct {
 	ResourceType string
 	Related      []map[string]any
+
+	// Excluded holds the fetched items left out by the relation's where and
+	// limit. They are not embedded, but still recorded as relations.
+	Excluded []map[string]any
+
+	// Children holds the fetched relations of each related item, by item
+	// index and relation name. Nil if the relation has no relations.
+	Children []map[string]*fetchedRelation
 }
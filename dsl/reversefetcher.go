@@ -0,0 +1,108 @@
+package dsl
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/theleeeo/indexer/model"
+	"github.com/theleeeo/indexer/projection"
+	"github.com/theleeeo/indexer/resource"
+	"github.com/theleeeo/indexer/source"
+)
+
+// reverseFetcher implements aggregation.SubFetcher[BuildDoc] for reverse
+// relations. It fetches the resources that reference the root.
+type reverseFetcher struct {
+	provider source.Provider
+	graph    RelationGraph
+	rel      *plannedRelation
+}
+
+func (f *reverseFetcher) Fetch(ctx context.Context, parent projection.BuildDoc) (any, error) {
+	if parent.Doc == nil {
+		return (*fetchedRelation)(nil), nil
+	}
+
+	var related []map[string]any
+	var err error
+	if f.rel.Reverse.Source == resource.ReverseSourceStore {
+		related, err = f.fetchFromStore(ctx, parent)
+	} else {
+		related, err = f.fetchFromProvider(ctx, parent)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("fetch reverse %s for %s/%s: %w", f.rel.Resource, parent.Root.Type, parent.Root.Id, err)
+	}
+
+	fr, err := newFetchedRelation(ctx, f.provider, parent, f.rel, related)
+	if err != nil {
+		return nil, fmt.Errorf("%s/%s: %w", parent.Root.Type, parent.Root.Id, err)
+	}
+	return fr, nil
+}
+
+// fetchFromProvider asks the provider for the related resources whose reverse
+// field holds the root ID.
+func (f *reverseFetcher) fetchFromProvider(ctx context.Context, parent projection.BuildDoc) ([]map[string]any, error) {
+	resp, err := f.provider.FetchRelated(ctx, source.FetchRelatedParams{
+		RootResource: source.RootResource{
+			Type: parent.Root.Type,
+			Id:   parent.Root.Id,
+		},
+		ResourceType: f.rel.Resource,
+		Key:          source.NewResourceKey(source.KeyPart{Field: f.rel.Reverse.Field, Value: parent.Root.Id}),
+		Metadata:     parent.Metadata,
+		Hints:        relationHints(f.rel.RelationConfig),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Related, nil
+}
+
+// fetchFromStore looks up the related resources that referenced the root when
+// they were last built, and fetches each of them from the provider.
+func (f *reverseFetcher) fetchFromStore(ctx context.Context, parent projection.BuildDoc) ([]map[string]any, error) {
+	if f.graph == nil {
+		return nil, fmt.Errorf("no relation graph configured")
+	}
+
+	refs, err := f.graph.GetParentResourcesOfType(ctx, parent.Root, f.rel.Resource)
+	if err != nil {
+		return nil, fmt.Errorf("get referencing resources: %w", err)
+	}
+
+	related := make([]map[string]any, 0, len(refs))
+	for _, ref := range refs {
+		data, err := f.provider.FetchResource(ctx, source.FetchResourceParams{
+			ResourceType: ref.Type,
+			ResourceID:   ref.Id,
+			Metadata:     parent.Metadata,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("fetch resource %s/%s: %w", ref.Type, ref.Id, err)
+		}
+		// Deleted, or no longer referencing the root. The stale relation is
+		// cleaned up when the resource itself is rebuilt.
+		if data.Data == nil || !referencesRoot(data.Data, f.rel.Reverse.Field, parent.Root) {
+			continue
+		}
+
+		item := data.Data
+		if _, ok := item["id"]; !ok {
+			item = make(map[string]any, len(data.Data)+1)
+			for k, v := range data.Data {
+				item[k] = v
+			}
+			item["id"] = ref.Id
+		}
+		related = append(related, item)
+	}
+
+	return related, nil
+}
+
+func referencesRoot(data map[string]any, field string, root model.Resource) bool {
+	id, ok := source.FormatKeyValue(data[field])
+	return ok && id == root.Id
+}
@@ -0,0 +1,244 @@
+package dsl
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"github.com/theleeeo/indexer/expr"
+	"github.com/theleeeo/indexer/projection"
+	"github.com/theleeeo/indexer/resource"
+	"github.com/theleeeo/indexer/source"
+)
+
+// newFetchedRelation selects the items of a relation to embed from the
+// fetched ones and fetches their own relations.
+func newFetchedRelation(
+	ctx context.Context,
+	provider source.Provider,
+	parent projection.BuildDoc,
+	rel *plannedRelation,
+	fetched []map[string]any,
+) (*fetchedRelation, error) {
+	frs, err := newFetchedRelations(ctx, provider, []projection.BuildDoc{parent}, rel, [][]map[string]any{fetched})
+	if err != nil {
+		return nil, err
+	}
+	return frs[0], nil
+}
+
+// newFetchedRelations is newFetchedRelation for the items fetched for several
+// parents of a relation at once, fetched[i] being the items of parents[i].
+// The relations of the selected items of all parents are fetched together.
+func newFetchedRelations(
+	ctx context.Context,
+	provider source.Provider,
+	parents []projection.BuildDoc,
+	rel *plannedRelation,
+	fetched [][]map[string]any,
+) ([]*fetchedRelation, error) {
+	frs := make([]*fetchedRelation, len(fetched))
+	var selected []map[string]any
+	var selectedParents []projection.BuildDoc
+	for i, items := range fetched {
+		related, excluded, err := selectRelated(rel, items)
+		if err != nil {
+			return nil, fmt.Errorf("select %s: %w", rel.Resource, err)
+		}
+		frs[i] = &fetchedRelation{
+			ResourceType: rel.Resource,
+			Related:      related,
+			Excluded:     excluded,
+		}
+		selected = append(selected, related...)
+		for range related {
+			selectedParents = append(selectedParents, parents[i])
+		}
+	}
+
+	children, err := fetchChildren(ctx, provider, selectedParents, rel.children, selected)
+	if err != nil {
+		return nil, fmt.Errorf("fetch relations of %s: %w", rel.Resource, err)
+	}
+	if children != nil {
+		for _, fr := range frs {
+			fr.Children, children = children[:len(fr.Related)], children[len(fr.Related):]
+		}
+	}
+
+	return frs, nil
+}
+
+// plannedRelation is a relation with its where and orderBy parsed once when
+// the plan is built, and its own relations.
+type plannedRelation struct {
+	resource.RelationConfig
+	where   *expr.Expr
+	orderBy []resource.FieldPath
+	// err is the parse error of the selection. Validation rejects invalid
+	// selections, so it is only reported if the config was not validated.
+	err      error
+	children []*plannedRelation
+}
+
+func newPlannedRelation(rel resource.RelationConfig) *plannedRelation {
+	p := &plannedRelation{RelationConfig: rel}
+	if rel.Where != "" {
+		if p.where, p.err = expr.Parse(rel.Where); p.err != nil {
+			p.err = fmt.Errorf("where: %w", p.err)
+		}
+	}
+	for _, o := range rel.OrderBy {
+		path, err := resource.ParseFieldPath(o.Field)
+		if err != nil && p.err == nil {
+			p.err = fmt.Errorf("orderBy %q: %w", o.Field, err)
+		}
+		p.orderBy = append(p.orderBy, path)
+	}
+	p.children = newPlannedRelations(rel.Relations)
+	return p
+}
+
+func newPlannedRelations(rels []resource.RelationConfig) []*plannedRelation {
+	if len(rels) == 0 {
+		return nil
+	}
+	planned := make([]*plannedRelation, len(rels))
+	for i, rel := range rels {
+		planned[i] = newPlannedRelation(rel)
+	}
+	return planned
+}
+
+// selectRelated applies the where, orderBy and limit of a relation to the
+// fetched items. It returns the selected items in order, and the others. The
+// only error is an invalid where or orderBy.
+func selectRelated(rel *plannedRelation, items []map[string]any) (selected, excluded []map[string]any, err error) {
+	if rel.err != nil {
+		return nil, nil, rel.err
+	}
+	if rel.where == nil && len(rel.orderBy) == 0 && rel.Limit == 0 {
+		return items, nil, nil
+	}
+
+	selected = items
+	if rel.where != nil {
+		selected = make([]map[string]any, 0, len(items))
+		for _, item := range items {
+			// An item the expression fails on, e.g. comparing a number with a
+			// string, is excluded like any other item it is not true for.
+			v, err := rel.where.Eval(item)
+			if err != nil {
+				slog.Warn("relation where failed, excluding item", "resource", rel.Resource, "id", item["id"], "error", err)
+			}
+			if v == true {
+				selected = append(selected, item)
+			} else {
+				excluded = append(excluded, item)
+			}
+		}
+	}
+
+	if len(rel.orderBy) > 0 {
+		// Don't reorder the fetched items in place.
+		selected = slices.Clone(selected)
+		slices.SortStableFunc(selected, func(a, b map[string]any) int {
+			for i, o := range rel.OrderBy {
+				if c := compareOrderValues(rel.orderBy[i], a, b, o.Order == resource.OrderDesc); c != 0 {
+					return c
+				}
+			}
+			return 0
+		})
+	}
+
+	if rel.Limit > 0 && len(selected) > rel.Limit {
+		excluded = append(excluded, selected[rel.Limit:]...)
+		selected = selected[:rel.Limit]
+	}
+
+	return selected, excluded, nil
+}
+
+// compareOrderValues compares the values of a field of two items. Missing
+// values sort last regardless of the direction. Numbers sort before strings,
+// and values of other types are equal.
+func compareOrderValues(path resource.FieldPath, a, b map[string]any, desc bool) int {
+	av, aok := path.Get(a)
+	bv, bok := path.Get(b)
+	aok = aok && av != nil
+	bok = bok && bv != nil
+	switch {
+	case !aok && !bok:
+		return 0
+	case !aok:
+		return 1
+	case !bok:
+		return -1
+	}
+
+	c := compareValues(av, bv)
+	if desc {
+		return -c
+	}
+	return c
+}
+
+func compareValues(a, b any) int {
+	af, aNum := toFloat(a)
+	bf, bNum := toFloat(b)
+	switch {
+	case aNum && bNum:
+		return cmp.Compare(af, bf)
+	case aNum:
+		return -1
+	case bNum:
+		return 1
+	}
+
+	as, aStr := a.(string)
+	bs, bStr := b.(string)
+	switch {
+	case aStr && bStr:
+		return cmp.Compare(as, bs)
+	case aStr:
+		return -1
+	case bStr:
+		return 1
+	}
+	return 0
+}
+
+func toFloat(v any) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
+
+// relationHints returns the selection of a relation as provider hints.
+func relationHints(rel resource.RelationConfig) source.RelationHints {
+	hints := source.RelationHints{
+		Where: rel.Where,
+		Limit: rel.Limit,
+	}
+	for _, o := range rel.OrderBy {
+		hints.OrderBy = append(hints.OrderBy, source.OrderBy{
+			Field:      o.Field,
+			Descending: o.Order == resource.OrderDesc,
+		})
+	}
+	return hints
+}
@@ -0,0 +1,92 @@
+package dsl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/theleeeo/indexer/resource"
+)
+
+func TestSelectRelated(t *testing.T) {
+	items := []map[string]any{
+		{"id": "1", "total": 5.0, "meta": map[string]any{"rank": "b"}},
+		{"id": "2", "total": 12.0, "meta": map[string]any{"rank": "a"}},
+		{"id": "3", "meta": map[string]any{"rank": "c"}},
+		{"id": "4", "total": 12.0, "meta": map[string]any{"rank": "c"}},
+	}
+
+	ids := func(items []map[string]any) []string {
+		var out []string
+		for _, it := range items {
+			out = append(out, it["id"].(string))
+		}
+		return out
+	}
+
+	tests := []struct {
+		name         string
+		rel          resource.RelationConfig
+		wantSelected []string
+		wantExcluded []string
+	}{
+		{
+			name:         "no selection",
+			rel:          resource.RelationConfig{},
+			wantSelected: []string{"1", "2", "3", "4"},
+		},
+		{
+			name:         "where",
+			rel:          resource.RelationConfig{Where: "total > 10"},
+			wantSelected: []string{"2", "4"},
+			wantExcluded: []string{"1", "3"},
+		},
+		{
+			name: "order by missing last",
+			rel: resource.RelationConfig{OrderBy: []resource.OrderByConfig{
+				{Field: "total", Order: resource.OrderDesc},
+				{Field: "meta.rank"},
+			}},
+			wantSelected: []string{"2", "4", "1", "3"},
+		},
+		{
+			name: "order and limit",
+			rel: resource.RelationConfig{
+				OrderBy: []resource.OrderByConfig{{Field: "$.meta.rank"}},
+				Limit:   2,
+			},
+			wantSelected: []string{"2", "1"},
+			wantExcluded: []string{"3", "4"},
+		},
+		{
+			name:         "limit above count",
+			rel:          resource.RelationConfig{Limit: 10},
+			wantSelected: []string{"1", "2", "3", "4"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selected, excluded, err := selectRelated(newPlannedRelation(tt.rel), items)
+			require.NoError(t, err)
+			require.Equal(t, tt.wantSelected, ids(selected))
+			require.Equal(t, tt.wantExcluded, ids(excluded))
+		})
+	}
+
+	// The fetched items keep their order.
+	require.Equal(t, []string{"1", "2", "3", "4"}, ids(items))
+}
+
+func TestSelectRelated_WhereError(t *testing.T) {
+	items := []map[string]any{{"id": "1", "n": "x"}, {"id": "2", "n": 2}}
+	selected, excluded, err := selectRelated(newPlannedRelation(resource.RelationConfig{Where: "n > 1"}), items)
+	require.NoError(t, err)
+	require.Equal(t, items[1:], selected)
+	require.Equal(t, items[:1], excluded)
+}
+
+func TestSelectRelated_ParseError(t *testing.T) {
+	rel := newPlannedRelation(resource.RelationConfig{Where: "name =="})
+	_, _, err := selectRelated(rel, []map[string]any{{"name": "x"}})
+	require.ErrorContains(t, err, "where: ")
+}
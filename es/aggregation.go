@@ -0,0 +1,221 @@
+package es
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/theleeeo/indexer/gen/search/v1"
+)
+
+const (
+	// nestedAggName is the name of the inner aggregation when an aggregation
+	// is wrapped in a nested aggregation.
+	nestedAggName = "inner"
+	// reverseNestedAggName is the name of the reverse_nested sub-aggregation
+	// used to count root documents per bucket of a nested aggregation.
+	reverseNestedAggName = "root"
+)
+
+// buildAggregations compiles the requested aggregations into the "aggs"
+// section of a search body, keyed by aggregation name.
+func buildAggregations(aggs []*search.Aggregation) (map[string]any, error) {
+	out := make(map[string]any, len(aggs))
+	for _, a := range aggs {
+		if a == nil {
+			continue
+		}
+		agg, err := buildAggregation(a)
+		if err != nil {
+			return nil, err
+		}
+		out[a.Name] = agg
+	}
+	return out, nil
+}
+
+// buildAggregation compiles a single aggregation. The options of its kind are
+// validated by the caller.
+func buildAggregation(a *search.Aggregation) (map[string]any, error) {
+	var agg map[string]any
+	bucketing := true
+
+	switch k := a.Kind.(type) {
+	case *search.Aggregation_Terms:
+		size := k.Terms.Size
+		if size <= 0 {
+			size = 10
+		}
+		agg = map[string]any{"terms": map[string]any{
+			"field": k.Terms.Field,
+			"size":  size,
+		}}
+
+	case *search.Aggregation_Range:
+		ranges := make([]any, 0, len(k.Range.Ranges))
+		for _, r := range k.Range.Ranges {
+			rng := map[string]any{}
+			if r.Key != "" {
+				rng["key"] = r.Key
+			}
+			if r.From != nil {
+				rng["from"] = *r.From
+			}
+			if r.To != nil {
+				rng["to"] = *r.To
+			}
+			ranges = append(ranges, rng)
+		}
+		agg = map[string]any{"range": map[string]any{
+			"field":  k.Range.Field,
+			"ranges": ranges,
+		}}
+
+	case *search.Aggregation_DateHistogram:
+		dh := k.DateHistogram
+		body := map[string]any{"field": dh.Field}
+		if dh.CalendarInterval != "" {
+			body["calendar_interval"] = dh.CalendarInterval
+		} else {
+			body["fixed_interval"] = dh.FixedInterval
+		}
+		if dh.Format != "" {
+			body["format"] = dh.Format
+		}
+		agg = map[string]any{"date_histogram": body}
+
+	case *search.Aggregation_Metric:
+		var metric string
+		switch k.Metric.Type {
+		case search.MetricType_METRIC_TYPE_MIN:
+			metric = "min"
+		case search.MetricType_METRIC_TYPE_MAX:
+			metric = "max"
+		case search.MetricType_METRIC_TYPE_AVG:
+			metric = "avg"
+		case search.MetricType_METRIC_TYPE_SUM:
+			metric = "sum"
+		default:
+			return nil, fmt.Errorf("metric aggregation %q requires a metric type", a.Name)
+		}
+		agg = map[string]any{metric: map[string]any{"field": k.Metric.Field}}
+		bucketing = false
+
+	default:
+		return nil, fmt.Errorf("aggregation %q requires a kind", a.Name)
+	}
+
+	if a.NestedPath == "" {
+		return agg, nil
+	}
+
+	// Count root documents rather than nested items per bucket.
+	if bucketing {
+		agg["aggs"] = map[string]any{
+			reverseNestedAggName: map[string]any{"reverse_nested": map[string]any{}},
+		}
+	}
+
+	return map[string]any{
+		"nested": map[string]any{"path": a.NestedPath},
+		"aggs":   map[string]any{nestedAggName: agg},
+	}, nil
+}
+
+// parseAggregations converts the "aggregations" section of a search response
+// into typed results, in the order of the requested aggregations.
+func parseAggregations(aggs []*search.Aggregation, raw map[string]any) []*search.AggregationResult {
+	var out []*search.AggregationResult
+	for _, a := range aggs {
+		if a == nil {
+			continue
+		}
+
+		res, _ := raw[a.Name].(map[string]any)
+		nested := a.NestedPath != ""
+		if nested {
+			res, _ = res[nestedAggName].(map[string]any)
+		}
+		buckets, _ := res["buckets"].([]any)
+
+		result := &search.AggregationResult{Name: a.Name}
+		switch a.Kind.(type) {
+		case *search.Aggregation_Terms:
+			tr := &search.TermsResult{}
+			for _, b := range buckets {
+				bm, _ := b.(map[string]any)
+				tr.Buckets = append(tr.Buckets, &search.TermsBucket{
+					Key:      bucketKey(bm),
+					DocCount: bucketDocCount(bm, nested),
+				})
+			}
+			result.Result = &search.AggregationResult_Terms{Terms: tr}
+
+		case *search.Aggregation_Range:
+			rr := &search.RangeResult{}
+			for _, b := range buckets {
+				bm, _ := b.(map[string]any)
+				rb := &search.RangeBucket{
+					Key:      bucketKey(bm),
+					DocCount: bucketDocCount(bm, nested),
+				}
+				if v, ok := bm["from"].(float64); ok {
+					rb.From = &v
+				}
+				if v, ok := bm["to"].(float64); ok {
+					rb.To = &v
+				}
+				rr.Buckets = append(rr.Buckets, rb)
+			}
+			result.Result = &search.AggregationResult_Range{Range: rr}
+
+		case *search.Aggregation_DateHistogram:
+			dr := &search.DateHistogramResult{}
+			for _, b := range buckets {
+				bm, _ := b.(map[string]any)
+				ts, _ := bm["key"].(float64)
+				dr.Buckets = append(dr.Buckets, &search.DateHistogramBucket{
+					Key:       bucketKey(bm),
+					Timestamp: int64(ts),
+					DocCount:  bucketDocCount(bm, nested),
+				})
+			}
+			result.Result = &search.AggregationResult_DateHistogram{DateHistogram: dr}
+
+		case *search.Aggregation_Metric:
+			mr := &search.MetricResult{}
+			if v, ok := res["value"].(float64); ok {
+				mr.Value = &v
+			}
+			result.Result = &search.AggregationResult_Metric{Metric: mr}
+		}
+
+		out = append(out, result)
+	}
+	return out
+}
+
+// bucketKey returns the formatted key of a bucket, preferring key_as_string
+// (set for dates and booleans) over the raw key.
+func bucketKey(b map[string]any) string {
+	if s, ok := b["key_as_string"].(string); ok {
+		return s
+	}
+	switch k := b["key"].(type) {
+	case string:
+		return k
+	case float64:
+		return strconv.FormatFloat(k, 'f', -1, 64)
+	default:
+		return ""
+	}
+}
+
+// bucketDocCount returns the number of documents in a bucket. For nested
+// aggregations this is the number of root documents, not nested items.
+func bucketDocCount(b map[string]any, nested bool) int64 {
+	if nested {
+		b, _ = b[reverseNestedAggName].(map[string]any)
+	}
+	c, _ := b["doc_count"].(float64)
+	return int64(c)
+}
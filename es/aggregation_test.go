@@ -0,0 +1,137 @@
+package es
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/theleeeo/indexer/gen/search/v1"
+)
+
+func TestBuildAggregations(t *testing.T) {
+	from, to := 10.0, 50.0
+	aggs := []*search.Aggregation{
+		{Name: "status", Kind: &search.Aggregation_Terms{Terms: &search.TermsAggregation{Field: "fields.status"}}},
+		{Name: "price", Kind: &search.Aggregation_Range{Range: &search.RangeAggregation{
+			Field:  "fields.price",
+			Ranges: []*search.AggregationRange{{To: &from}, {Key: "mid", From: &from, To: &to}},
+		}}},
+		{Name: "created", Kind: &search.Aggregation_DateHistogram{DateHistogram: &search.DateHistogramAggregation{
+			Field: "fields.created_at", CalendarInterval: "month", Format: "yyyy-MM",
+		}}},
+		{Name: "avg_price", Kind: &search.Aggregation_Metric{Metric: &search.MetricAggregation{
+			Field: "fields.price", Type: search.MetricType_METRIC_TYPE_AVG,
+		}}},
+		{Name: "c_state", NestedPath: "c", Kind: &search.Aggregation_Terms{Terms: &search.TermsAggregation{Field: "c.state", Size: 5}}},
+		{Name: "c_sum", NestedPath: "c", Kind: &search.Aggregation_Metric{Metric: &search.MetricAggregation{
+			Field: "c.number", Type: search.MetricType_METRIC_TYPE_SUM,
+		}}},
+	}
+
+	got, err := buildAggregations(aggs)
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{
+		"status": map[string]any{"terms": map[string]any{"field": "fields.status", "size": int32(10)}},
+		"price": map[string]any{"range": map[string]any{
+			"field":  "fields.price",
+			"ranges": []any{map[string]any{"to": 10.0}, map[string]any{"key": "mid", "from": 10.0, "to": 50.0}},
+		}},
+		"created": map[string]any{"date_histogram": map[string]any{
+			"field": "fields.created_at", "calendar_interval": "month", "format": "yyyy-MM",
+		}},
+		"avg_price": map[string]any{"avg": map[string]any{"field": "fields.price"}},
+		"c_state": map[string]any{
+			"nested": map[string]any{"path": "c"},
+			"aggs": map[string]any{"inner": map[string]any{
+				"terms": map[string]any{"field": "c.state", "size": int32(5)},
+				"aggs":  map[string]any{"root": map[string]any{"reverse_nested": map[string]any{}}},
+			}},
+		},
+		"c_sum": map[string]any{
+			"nested": map[string]any{"path": "c"},
+			"aggs":   map[string]any{"inner": map[string]any{"sum": map[string]any{"field": "c.number"}}},
+		},
+	}, got)
+}
+
+func TestBuildAggregations_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		agg     *search.Aggregation
+		wantErr string
+	}{
+		{
+			name:    "metric without type",
+			agg:     &search.Aggregation{Name: "a", Kind: &search.Aggregation_Metric{Metric: &search.MetricAggregation{Field: "f"}}},
+			wantErr: `metric aggregation "a" requires a metric type`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := buildAggregations([]*search.Aggregation{tt.agg})
+			require.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestParseAggregations(t *testing.T) {
+	aggs := []*search.Aggregation{
+		{Name: "status", Kind: &search.Aggregation_Terms{Terms: &search.TermsAggregation{Field: "fields.status"}}},
+		{Name: "price", Kind: &search.Aggregation_Range{Range: &search.RangeAggregation{Field: "fields.price"}}},
+		{Name: "created", Kind: &search.Aggregation_DateHistogram{DateHistogram: &search.DateHistogramAggregation{Field: "fields.created_at"}}},
+		{Name: "max_price", Kind: &search.Aggregation_Metric{Metric: &search.MetricAggregation{Field: "fields.price"}}},
+		{Name: "min_price", Kind: &search.Aggregation_Metric{Metric: &search.MetricAggregation{Field: "fields.price"}}},
+		{Name: "c_state", NestedPath: "c", Kind: &search.Aggregation_Terms{Terms: &search.TermsAggregation{Field: "c.state"}}},
+	}
+
+	raw := map[string]any{
+		"status": map[string]any{"buckets": []any{
+			map[string]any{"key": "open", "doc_count": 3.0},
+			map[string]any{"key": "closed", "doc_count": 1.0},
+		}},
+		"price": map[string]any{"buckets": []any{
+			map[string]any{"key": "*-10.0", "to": 10.0, "doc_count": 2.0},
+			map[string]any{"key": "10.0-*", "from": 10.0, "doc_count": 5.0},
+		}},
+		"created": map[string]any{"buckets": []any{
+			map[string]any{"key": 1704067200000.0, "key_as_string": "2024-01-01", "doc_count": 4.0},
+		}},
+		"max_price": map[string]any{"value": 42.5},
+		"min_price": map[string]any{"value": nil},
+		"c_state": map[string]any{"doc_count": 9.0, "inner": map[string]any{"buckets": []any{
+			map[string]any{"key": "active", "doc_count": 7.0, "root": map[string]any{"doc_count": 2.0}},
+		}}},
+	}
+
+	got := parseAggregations(aggs, raw)
+	require.Len(t, got, 6)
+
+	require.Equal(t, "status", got[0].Name)
+	terms := got[0].GetTerms().Buckets
+	require.Len(t, terms, 2)
+	require.Equal(t, "open", terms[0].Key)
+	require.Equal(t, int64(3), terms[0].DocCount)
+
+	ranges := got[1].GetRange().Buckets
+	require.Len(t, ranges, 2)
+	require.Nil(t, ranges[0].From)
+	require.Equal(t, 10.0, ranges[0].GetTo())
+	require.Equal(t, 10.0, ranges[1].GetFrom())
+	require.Nil(t, ranges[1].To)
+	require.Equal(t, int64(5), ranges[1].DocCount)
+
+	hist := got[2].GetDateHistogram().Buckets
+	require.Len(t, hist, 1)
+	require.Equal(t, "2024-01-01", hist[0].Key)
+	require.Equal(t, int64(1704067200000), hist[0].Timestamp)
+	require.Equal(t, int64(4), hist[0].DocCount)
+
+	require.Equal(t, 42.5, got[3].GetMetric().GetValue())
+	require.Nil(t, got[4].GetMetric().Value)
+
+	// Nested buckets count root documents.
+	nested := got[5].GetTerms().Buckets
+	require.Len(t, nested, 1)
+	require.Equal(t, "active", nested[0].Key)
+	require.Equal(t, int64(2), nested[0].DocCount)
+}
@@ -95,9 +95,19 @@ type BulkItem struct {
 	Doc   any
 }
 
-func (c *Client) BulkUpsert(ctx context.Context, items []BulkItem) error {
+// BulkItemFailure is an item of a bulk request that ES rejected, e.g. for a
+// mapping conflict, while the rest of the request succeeded.
+type BulkItemFailure struct {
+	Index  string
+	ID     string
+	Reason string
+}
+
+// BulkUpsert indexes the items in one bulk request. It returns an error if
+// the request as a whole failed, and the items ES rejected one by one.
+func (c *Client) BulkUpsert(ctx context.Context, items []BulkItem) ([]BulkItemFailure, error) {
 	if len(items) == 0 {
-		return nil
+		return nil, nil
 	}
 
 	var buf bytes.Buffer
@@ -106,11 +116,11 @@ func (c *Client) BulkUpsert(ctx context.Context, items []BulkItem) error {
 	for _, it := range items {
 		meta := map[string]any{"index": map[string]any{"_index": it.Index, "_id": it.ID}}
 		if err := json.MarshalEncode(enc, meta); err != nil {
-			return fmt.Errorf("marshal index meta: %w", err)
+			return nil, fmt.Errorf("marshal index meta: %w", err)
 		}
 
 		if err := json.MarshalEncode(enc, it.Doc); err != nil {
-			return fmt.Errorf("marshal doc: %w", err)
+			return nil, fmt.Errorf("marshal doc: %w", err)
 		}
 	}
 
@@ -128,16 +138,61 @@ func (c *Client) BulkUpsert(ctx context.Context, items []BulkItem) error {
 		c.es.Bulk.WithRefresh(refresh),
 	)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer res.Body.Close()
 
 	if res.IsError() {
 		b, _ := io.ReadAll(res.Body)
-		return fmt.Errorf("es bulk error: %s %s", res.Status(), string(b))
+		return nil, fmt.Errorf("es bulk error: %s %s", res.Status(), string(b))
 	}
-	slog.Info("bulk upserted docs", "count", len(items))
-	return nil
+
+	failures, err := parseBulkResponse(res.Body, items)
+	if err != nil {
+		return nil, err
+	}
+	slog.Info("bulk upserted docs", "count", len(items)-len(failures), "failed", len(failures))
+	return failures, nil
+}
+
+// parseBulkResponse returns the items a bulk response reports as failed. ES
+// answers 200 even if some items were rejected; those have an error in the
+// response item at the same position as the request item.
+func parseBulkResponse(r io.Reader, items []BulkItem) ([]BulkItemFailure, error) {
+	var decoded struct {
+		Errors bool `json:"errors"`
+		Items  []map[string]struct {
+			Status int `json:"status"`
+			Error  *struct {
+				Type   string `json:"type"`
+				Reason string `json:"reason"`
+			} `json:"error"`
+		} `json:"items"`
+	}
+	if err := json.UnmarshalRead(r, &decoded); err != nil {
+		return nil, fmt.Errorf("decode bulk response: %w", err)
+	}
+	if !decoded.Errors {
+		return nil, nil
+	}
+	if len(decoded.Items) != len(items) {
+		return nil, fmt.Errorf("bulk response has %d items for %d requested", len(decoded.Items), len(items))
+	}
+
+	var failures []BulkItemFailure
+	for i, result := range decoded.Items {
+		for _, action := range result {
+			if action.Error == nil {
+				continue
+			}
+			failures = append(failures, BulkItemFailure{
+				Index:  items[i].Index,
+				ID:     items[i].ID,
+				Reason: fmt.Sprintf("%s: %s (status %d)", action.Error.Type, action.Error.Reason, action.Status),
+			})
+		}
+	}
+	return failures, nil
 }
 
 func (c *Client) Get(ctx context.Context, indexAlias, docID string, includeFields []string) (map[string]any, error) {
@@ -0,0 +1,42 @@
+package es
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseBulkResponse(t *testing.T) {
+	items := []BulkItem{
+		{Index: "a_v1", ID: "1"},
+		{Index: "a_v1", ID: "2"},
+		{Index: "a_v2", ID: "1"},
+	}
+
+	body := `{
+		"took": 3,
+		"errors": true,
+		"items": [
+			{"index": {"_index": "a_v1_000001", "_id": "1", "status": 200}},
+			{"index": {"_index": "a_v1_000001", "_id": "2", "status": 400, "error": {"type": "mapper_parsing_exception", "reason": "failed to parse field [fields.count]"}}},
+			{"index": {"_index": "a_v2_000001", "_id": "1", "status": 201}}
+		]
+	}`
+
+	failures, err := parseBulkResponse(strings.NewReader(body), items)
+	require.NoError(t, err)
+	require.Equal(t, []BulkItemFailure{{
+		Index:  "a_v1",
+		ID:     "2",
+		Reason: "mapper_parsing_exception: failed to parse field [fields.count] (status 400)",
+	}}, failures)
+}
+
+func TestParseBulkResponse_NoErrors(t *testing.T) {
+	body := `{"took": 1, "errors": false, "items": [{"index": {"_id": "1", "status": 200}}]}`
+
+	failures, err := parseBulkResponse(strings.NewReader(body), []BulkItem{{Index: "a_v1", ID: "1"}})
+	require.NoError(t, err)
+	require.Empty(t, failures)
+}
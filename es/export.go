@@ -0,0 +1,103 @@
+package es
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/theleeeo/indexer/gen/search/v1"
+	"github.com/theleeeo/indexer/resource"
+)
+
+const (
+	defaultExportBatchSize = 500
+	maxExportBatchSize     = 5000
+)
+
+// Export runs the query of the request against a point-in-time snapshot of
+// the index and passes every matching hit to fn, one batch at a time. Batches
+// are paged with search_after, so there is no limit on the number of hits.
+//
+// Export stops when ctx is cancelled or fn returns an error. The
+// point-in-time is always released before returning.
+func (c *Client) Export(ctx context.Context, req *search.ExportRequest, indexAlias string, searchFields []string, mode resource.QueryModeConfig, fn func([]*search.SearchHit) error) error {
+	query, err := buildQuery(req.Query, searchFields, mode, req.Filters, req.FilterGroup)
+	if err != nil {
+		return err
+	}
+
+	batchSize := int(req.BatchSize)
+	if batchSize <= 0 {
+		batchSize = defaultExportBatchSize
+	}
+	if batchSize > maxExportBatchSize {
+		batchSize = maxExportBatchSize
+	}
+
+	pitID, err := c.openPointInTime(ctx, indexAlias)
+	if err != nil {
+		if errors.Is(err, errSearchNotFound) {
+			// index not found, nothing to export
+			return nil
+		}
+		return err
+	}
+	// Release the snapshot even if the export was cancelled.
+	defer c.releasePointInTime(ctx, pitID)
+
+	sort := buildSort(req.Sort)
+	var searchAfter []any
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		body := map[string]any{
+			"query":            query,
+			"size":             batchSize,
+			"sort":             sort,
+			"track_total_hits": false,
+		}
+		if len(req.Fields) > 0 {
+			body["_source"] = map[string]any{"includes": req.Fields}
+		}
+		if searchAfter != nil {
+			body["search_after"] = searchAfter
+		}
+
+		decoded, err := c.doSearch(ctx, indexAlias, pitID, body)
+		if err != nil {
+			if errors.Is(err, errSearchNotFound) {
+				return fmt.Errorf("point in time expired during export: %w", err)
+			}
+			return err
+		}
+
+		// ES may hand out a new ID for the point-in-time on every search.
+		if id, ok := decoded["pit_id"].(string); ok && id != "" {
+			pitID = id
+		}
+
+		hitsObj, _ := decoded["hits"].(map[string]any)
+		rawHits, _ := hitsObj["hits"].([]any)
+		if len(rawHits) == 0 {
+			return nil
+		}
+
+		hits, lastSort, err := parseHits(rawHits)
+		if err != nil {
+			return err
+		}
+		if len(hits) > 0 {
+			if err := fn(hits); err != nil {
+				return err
+			}
+		}
+
+		if len(rawHits) < batchSize || len(lastSort) == 0 {
+			return nil
+		}
+		searchAfter = lastSort
+	}
+}
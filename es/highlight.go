@@ -0,0 +1,53 @@
+package es
+
+import (
+	"github.com/theleeeo/indexer/gen/search/v1"
+)
+
+// buildHighlight compiles the highlight options of a search request into the
+// ES highlight section. Options left unset fall back to the ES defaults.
+func buildHighlight(h *search.Highlight) map[string]any {
+	fields := make(map[string]any, len(h.Fields))
+	for _, f := range h.Fields {
+		fields[f] = map[string]any{}
+	}
+
+	out := map[string]any{"fields": fields}
+
+	if h.FragmentSize > 0 {
+		out["fragment_size"] = h.FragmentSize
+	}
+	if h.NumberOfFragments != nil {
+		out["number_of_fragments"] = *h.NumberOfFragments
+	}
+	if h.PreTag != "" {
+		out["pre_tags"] = []string{h.PreTag}
+	}
+	if h.PostTag != "" {
+		out["post_tags"] = []string{h.PostTag}
+	}
+
+	return out
+}
+
+// parseHighlights converts the highlight section of a raw ES hit into
+// fragments keyed by field path.
+func parseHighlights(raw map[string]any) map[string]*search.HighlightFragments {
+	if len(raw) == 0 {
+		return nil
+	}
+
+	out := make(map[string]*search.HighlightFragments, len(raw))
+	for field, v := range raw {
+		list, _ := v.([]any)
+		fragments := make([]string, 0, len(list))
+		for _, f := range list {
+			if s, ok := f.(string); ok {
+				fragments = append(fragments, s)
+			}
+		}
+		out[field] = &search.HighlightFragments{Fragments: fragments}
+	}
+
+	return out
+}
@@ -0,0 +1,48 @@
+package es
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/theleeeo/indexer/gen/search/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestBuildHighlight(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		got := buildHighlight(&search.Highlight{Fields: []string{"fields.title", "b.name"}})
+		require.Equal(t, map[string]any{
+			"fields": map[string]any{
+				"fields.title": map[string]any{},
+				"b.name":       map[string]any{},
+			},
+		}, got)
+	})
+
+	t.Run("options", func(t *testing.T) {
+		got := buildHighlight(&search.Highlight{
+			Fields:            []string{"fields.title"},
+			FragmentSize:      50,
+			NumberOfFragments: proto.Int32(0),
+			PreTag:            "<b>",
+			PostTag:           "</b>",
+		})
+		require.Equal(t, map[string]any{
+			"fields":              map[string]any{"fields.title": map[string]any{}},
+			"fragment_size":       int32(50),
+			"number_of_fragments": int32(0),
+			"pre_tags":            []string{"<b>"},
+			"post_tags":           []string{"</b>"},
+		}, got)
+	})
+}
+
+func TestParseHighlights(t *testing.T) {
+	require.Nil(t, parseHighlights(nil))
+
+	got := parseHighlights(map[string]any{
+		"fields.title": []any{"a <em>red</em> shoe", "<em>red</em>"},
+	})
+	require.Len(t, got, 1)
+	require.Equal(t, []string{"a <em>red</em> shoe", "<em>red</em>"}, got["fields.title"].Fragments)
+}
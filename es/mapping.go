@@ -2,16 +2,16 @@ package es
 
 import "github.com/theleeeo/indexer/resource"
 
-// GenerateMapping builds an Elasticsearch index mapping from a version config.
+// GenerateMapping builds the Elasticsearch index body from a version config:
+// the mappings, plus the analysis settings if the version defines any.
 func GenerateMapping(vc *resource.VersionConfig) map[string]any {
 	fieldsProps := make(map[string]any, len(vc.Fields))
 	for _, f := range vc.Fields {
-		fieldsProps[f.Name] = map[string]any{
-			"type": f.ESType(),
-		}
+		fieldsProps[f.Name] = fieldMapping(f)
 	}
 
 	properties := map[string]any{
+		DocIDField: map[string]any{"type": "keyword"},
 		"fields": map[string]any{
 			"type":       "object",
 			"properties": fieldsProps,
@@ -19,30 +19,109 @@ func GenerateMapping(vc *resource.VersionConfig) map[string]any {
 	}
 
 	for _, rel := range vc.Relations {
-		relProps := make(map[string]any, len(rel.Fields)+1)
-		relProps["id"] = map[string]any{"type": "keyword"}
-		for _, f := range rel.Fields {
-			relProps[f.Name] = map[string]any{
-				"type": f.ESType(),
-			}
+		properties[rel.Name()] = relationMapping(rel)
+	}
+
+	body := map[string]any{
+		"mappings": map[string]any{
+			"properties": properties,
+		},
+	}
+
+	if !vc.Analysis.IsEmpty() {
+		body["settings"] = map[string]any{
+			"analysis": analysisSettings(vc.Analysis),
 		}
+	}
+
+	return body
+}
+
+// relationMapping returns the mapping of a relation's objects, including the
+// objects of its own relations.
+func relationMapping(rel resource.RelationConfig) map[string]any {
+	relProps := make(map[string]any, len(rel.Fields)+len(rel.Relations)+1)
+	relProps["id"] = map[string]any{"type": "keyword"}
+	for _, f := range rel.Fields {
+		relProps[f.Name] = fieldMapping(f)
+	}
+	for _, child := range rel.Relations {
+		relProps[child.Name()] = relationMapping(child)
+	}
+
+	relType := "nested"
+	if !rel.IsMany() {
+		relType = "object"
+	}
+
+	return map[string]any{
+		"type":       relType,
+		"properties": relProps,
+	}
+}
 
-		relType := "nested"
-		if !rel.IsMany() {
-			relType = "object"
+// fieldMapping returns the mapping of a single field including its analysis
+// options and sub-fields, or the properties of an object or nested field.
+func fieldMapping(f resource.FieldConfig) map[string]any {
+	m := map[string]any{
+		"type": f.ESType(),
+	}
+
+	if f.IsObject() {
+		props := make(map[string]any, len(f.Fields))
+		for _, child := range f.Fields {
+			props[child.Name] = fieldMapping(child)
 		}
+		m["properties"] = props
+		return m
+	}
+	setAnalysisOptions(m, f.Analyzer, f.SearchAnalyzer, f.Normalizer)
 
-		properties[rel.Resource] = map[string]any{
-			"type":       relType,
-			"properties": relProps,
+	if len(f.SubFields) > 0 || f.Suggest {
+		subFields := make(map[string]any, len(f.SubFields)+1)
+		for _, sf := range f.SubFields {
+			sm := map[string]any{
+				"type": sf.ESType(),
+			}
+			setAnalysisOptions(sm, sf.Analyzer, sf.SearchAnalyzer, sf.Normalizer)
+			subFields[sf.Name] = sm
+		}
+		if f.Suggest {
+			subFields[resource.SuggestSubField] = map[string]any{"type": "search_as_you_type"}
 		}
+		m["fields"] = subFields
 	}
 
-	return map[string]any{
-		"mappings": map[string]any{
-			"properties": properties,
-		},
+	return m
+}
+
+func setAnalysisOptions(m map[string]any, analyzer, searchAnalyzer, normalizer string) {
+	if analyzer != "" {
+		m["analyzer"] = analyzer
+	}
+	if searchAnalyzer != "" {
+		m["search_analyzer"] = searchAnalyzer
+	}
+	if normalizer != "" {
+		m["normalizer"] = normalizer
+	}
+}
+
+// analysisSettings converts the analysis config to the ES index.analysis
+// settings.
+func analysisSettings(a resource.AnalysisConfig) map[string]any {
+	settings := map[string]any{}
+	add := func(key string, defs map[string]map[string]any) {
+		if len(defs) > 0 {
+			settings[key] = defs
+		}
 	}
+	add("analyzer", a.Analyzers)
+	add("normalizer", a.Normalizers)
+	add("tokenizer", a.Tokenizers)
+	add("filter", a.Filters)
+	add("char_filter", a.CharFilters)
+	return settings
 }
 
 // GenerateMappings builds ES index mappings for all resource configs.
@@ -0,0 +1,142 @@
+package es
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/theleeeo/indexer/resource"
+)
+
+func TestGenerateMapping_Analysis(t *testing.T) {
+	vc := &resource.VersionConfig{
+		Fields: []resource.FieldConfig{
+			{
+				Name:           "title",
+				Type:           "text",
+				Analyzer:       "autocomplete",
+				SearchAnalyzer: "standard",
+				SubFields: []resource.SubFieldConfig{
+					{Name: "sort", Normalizer: "lowercase"},
+				},
+			},
+		},
+		Relations: []resource.RelationConfig{
+			{Resource: "b", Cardinality: "one", Fields: []resource.FieldConfig{{Name: "code", Normalizer: "lowercase"}}},
+		},
+		Analysis: resource.AnalysisConfig{
+			Analyzers: map[string]map[string]any{
+				"autocomplete": {"tokenizer": "edge", "filter": []any{"lowercase"}},
+			},
+			Tokenizers: map[string]map[string]any{
+				"edge": {"type": "edge_ngram", "min_gram": 2, "max_gram": 10},
+			},
+		},
+	}
+
+	got := GenerateMapping(vc)
+
+	props := got["mappings"].(map[string]any)["properties"].(map[string]any)
+	require.Equal(t, map[string]any{
+		"type":            "text",
+		"analyzer":        "autocomplete",
+		"search_analyzer": "standard",
+		"fields": map[string]any{
+			"sort": map[string]any{"type": "keyword", "normalizer": "lowercase"},
+		},
+	}, props["fields"].(map[string]any)["properties"].(map[string]any)["title"])
+	require.Equal(t, map[string]any{"type": "keyword", "normalizer": "lowercase"},
+		props["b"].(map[string]any)["properties"].(map[string]any)["code"])
+
+	require.Equal(t, map[string]any{
+		"analysis": map[string]any{
+			"analyzer":  vc.Analysis.Analyzers,
+			"tokenizer": vc.Analysis.Tokenizers,
+		},
+	}, got["settings"])
+}
+
+func TestGenerateMapping_NoAnalysis(t *testing.T) {
+	got := GenerateMapping(&resource.VersionConfig{
+		Fields: []resource.FieldConfig{{Name: "title"}},
+	})
+	require.NotContains(t, got, "settings")
+}
+
+func TestGenerateMapping_Suggest(t *testing.T) {
+	got := GenerateMapping(&resource.VersionConfig{
+		Fields: []resource.FieldConfig{{Name: "title", Type: "text", Suggest: true}},
+	})
+
+	props := got["mappings"].(map[string]any)["properties"].(map[string]any)
+	require.Equal(t, map[string]any{
+		"type": "text",
+		"fields": map[string]any{
+			"suggest": map[string]any{"type": "search_as_you_type"},
+		},
+	}, props["fields"].(map[string]any)["properties"].(map[string]any)["title"])
+}
+
+func TestGenerateMapping_RelationsOfRelations(t *testing.T) {
+	vc := &resource.VersionConfig{
+		Relations: []resource.RelationConfig{
+			{
+				Resource: "lines",
+				Fields:   []resource.FieldConfig{{Name: "quantity", Type: "integer"}},
+				Relations: []resource.RelationConfig{
+					{Resource: "product", Cardinality: "one", Fields: []resource.FieldConfig{{Name: "name", Type: "text"}}},
+				},
+			},
+		},
+	}
+
+	props := GenerateMapping(vc)["mappings"].(map[string]any)["properties"].(map[string]any)
+	require.Equal(t, map[string]any{
+		"type": "nested",
+		"properties": map[string]any{
+			"id":       map[string]any{"type": "keyword"},
+			"quantity": map[string]any{"type": "integer"},
+			"product": map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"id":   map[string]any{"type": "keyword"},
+					"name": map[string]any{"type": "text"},
+				},
+			},
+		},
+	}, props["lines"])
+}
+
+func TestGenerateMapping_ObjectFields(t *testing.T) {
+	got := GenerateMapping(&resource.VersionConfig{
+		Fields: []resource.FieldConfig{
+			{Name: "address", Type: "object", Fields: []resource.FieldConfig{
+				{Name: "city", Type: "text"},
+			}},
+			{Name: "items", Type: "nested", Fields: []resource.FieldConfig{
+				{Name: "sku"},
+				{Name: "options", Type: "object", Fields: []resource.FieldConfig{{Name: "color"}}},
+			}},
+		},
+	})
+
+	props := got["mappings"].(map[string]any)["properties"].(map[string]any)
+	fields := props["fields"].(map[string]any)["properties"].(map[string]any)
+	require.Equal(t, map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"city": map[string]any{"type": "text"},
+		},
+	}, fields["address"])
+	require.Equal(t, map[string]any{
+		"type": "nested",
+		"properties": map[string]any{
+			"sku": map[string]any{"type": "keyword"},
+			"options": map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"color": map[string]any{"type": "keyword"},
+				},
+			},
+		},
+	}, fields["items"])
+}
@@ -0,0 +1,144 @@
+package es
+
+import (
+	"bytes"
+	"context"
+	"encoding/json/jsontext"
+	"encoding/json/v2"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+
+	"github.com/theleeeo/indexer/gen/search/v1"
+	"github.com/theleeeo/indexer/resource"
+)
+
+// MultiSearchTarget is a single resource queried by MultiSearch.
+type MultiSearchTarget struct {
+	Resource     string
+	IndexAlias   string
+	SearchFields []string
+	Mode         resource.QueryModeConfig
+	Filters      []*search.Filter
+	FilterGroup  *search.FilterGroup
+
+	// Size is the maximum number of hits taken from this resource.
+	Size int32
+
+	// Weight multiplies the scores of the resource's hits.
+	Weight float64
+}
+
+// MultiSearch queries the indices of all targets in a single _msearch request,
+// each with its own searchable fields and filters. The hits are merged by
+// weighted score and truncated to size.
+func (c *Client) MultiSearch(ctx context.Context, query string, targets []MultiSearchTarget, size int32, includeSource bool) (*search.MultiSearchResponse, error) {
+	var buf bytes.Buffer
+	enc := jsontext.NewEncoder(&buf)
+
+	for _, t := range targets {
+		q, err := buildQuery(query, t.SearchFields, t.Mode, t.Filters, t.FilterGroup)
+		if err != nil {
+			return nil, fmt.Errorf("resource %q: %w", t.Resource, err)
+		}
+
+		header := map[string]any{"index": t.IndexAlias}
+		body := map[string]any{
+			"query":   q,
+			"size":    t.Size,
+			"_source": includeSource,
+		}
+
+		if err := json.MarshalEncode(enc, header); err != nil {
+			return nil, fmt.Errorf("marshal msearch header: %w", err)
+		}
+		if err := json.MarshalEncode(enc, body); err != nil {
+			return nil, fmt.Errorf("marshal msearch body: %w", err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	res, err := c.es.Msearch(
+		bytes.NewReader(buf.Bytes()),
+		c.es.Msearch.WithContext(ctx),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		raw, _ := io.ReadAll(res.Body)
+		return nil, fmt.Errorf("es msearch error: %s %s", res.Status(), string(raw))
+	}
+
+	var decoded struct {
+		Responses []map[string]any `json:"responses"`
+	}
+	if err := json.UnmarshalRead(res.Body, &decoded); err != nil {
+		return nil, err
+	}
+
+	if len(decoded.Responses) != len(targets) {
+		return nil, fmt.Errorf("es msearch returned %d responses for %d searches", len(decoded.Responses), len(targets))
+	}
+
+	out := &search.MultiSearchResponse{Totals: make(map[string]int64, len(targets))}
+	for i, r := range decoded.Responses {
+		t := targets[i]
+
+		if errObj, ok := r["error"]; ok {
+			// index not found, the resource has no hits
+			if status, _ := r["status"].(float64); status == 404 {
+				out.Totals[t.Resource] = 0
+				continue
+			}
+			return nil, fmt.Errorf("es msearch error for resource %q: %v", t.Resource, errObj)
+		}
+
+		hitsObj, _ := r["hits"].(map[string]any)
+		if tot, ok := hitsObj["total"].(map[string]any); ok {
+			if v, ok := tot["value"].(float64); ok {
+				out.Totals[t.Resource] = int64(v)
+			}
+		}
+
+		rawHits, _ := hitsObj["hits"].([]any)
+		hits, _, err := parseHits(rawHits)
+		if err != nil {
+			return nil, fmt.Errorf("resource %q: %w", t.Resource, err)
+		}
+		for _, h := range hits {
+			h.Resource = t.Resource
+			h.Score *= t.Weight
+		}
+		out.Hits = append(out.Hits, hits...)
+	}
+
+	out.Hits = mergeHits(out.Hits, int(size))
+
+	return out, nil
+}
+
+// mergeHits orders hits by score, highest first, and keeps at most size of
+// them. Hits with equal scores keep their relative order.
+func mergeHits(hits []*search.SearchHit, size int) []*search.SearchHit {
+	slices.SortStableFunc(hits, func(a, b *search.SearchHit) int {
+		switch {
+		case a.Score > b.Score:
+			return -1
+		case a.Score < b.Score:
+			return 1
+		default:
+			return 0
+		}
+	})
+
+	if len(hits) > size {
+		hits = hits[:size]
+	}
+	return hits
+}
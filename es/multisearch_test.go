@@ -0,0 +1,26 @@
+package es
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/theleeeo/indexer/gen/search/v1"
+)
+
+func TestMergeHits(t *testing.T) {
+	hits := []*search.SearchHit{
+		{Id: "a1", Resource: "a", Score: 1},
+		{Id: "a2", Resource: "a", Score: 0.5},
+		{Id: "b1", Resource: "b", Score: 2},
+		{Id: "b2", Resource: "b", Score: 1},
+	}
+
+	got := mergeHits(hits, 3)
+
+	ids := make([]string, 0, len(got))
+	for _, h := range got {
+		ids = append(ids, h.Id)
+	}
+	// Equal scores keep the order of the resources in the request.
+	require.Equal(t, []string{"b1", "a1", "b2"}, ids)
+}
@@ -0,0 +1,169 @@
+package es
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json/v2"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/theleeeo/indexer/gen/search/v1"
+)
+
+// ErrInvalidPageToken is returned when a page token cannot be decoded, does
+// not match the request it is used with, or refers to an expired
+// point-in-time.
+var ErrInvalidPageToken = errors.New("invalid page token")
+
+// DocIDField is the document field holding a copy of the document ID. It is
+// used as the search_after tiebreaker since ES does not allow sorting on _id.
+const DocIDField = "id"
+
+// pitKeepAlive is how long a point-in-time is kept open between two pages.
+const pitKeepAlive = "1m"
+
+// pageToken is the decoded form of an opaque page token. It carries the sort
+// values of the last hit of the previous page, a fingerprint of the sort they
+// belong to, and the point-in-time the pages are read from, if any.
+type pageToken struct {
+	SearchAfter []any  `json:"a"`
+	Sort        string `json:"s"`
+	PitID       string `json:"p,omitempty"`
+}
+
+func encodePageToken(t pageToken) (string, error) {
+	b, err := json.Marshal(t)
+	if err != nil {
+		return "", fmt.Errorf("marshal page token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func decodePageToken(s string) (pageToken, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return pageToken{}, ErrInvalidPageToken
+	}
+	var t pageToken
+	if err := json.Unmarshal(b, &t); err != nil || len(t.SearchAfter) == 0 {
+		return pageToken{}, ErrInvalidPageToken
+	}
+	return t, nil
+}
+
+// buildSort builds the sort section of a search body. The requested sort is
+// always followed by a tiebreaker on the document ID so that search_after
+// pagination is stable. Without a requested sort, hits are ordered by score.
+func buildSort(sorts []*search.Sort) []any {
+	var out []any
+	for _, srt := range sorts {
+		if srt == nil || srt.Field == "" {
+			continue
+		}
+		order := "asc"
+		if srt.Desc {
+			order = "desc"
+		}
+		out = append(out, map[string]any{
+			srt.Field: map[string]any{"order": order},
+		})
+	}
+
+	if len(out) == 0 {
+		out = append(out, map[string]any{"_score": map[string]any{"order": "desc"}})
+	}
+
+	// unmapped_type keeps indices created before the ID field was added
+	// searchable until they are rebuilt.
+	return append(out, map[string]any{
+		DocIDField: map[string]any{"order": "asc", "unmapped_type": "keyword"},
+	})
+}
+
+// sortFingerprint identifies a requested sort so that page tokens cannot be
+// reused with a different one.
+func sortFingerprint(sorts []*search.Sort) string {
+	var parts []string
+	for _, srt := range sorts {
+		if srt == nil || srt.Field == "" {
+			continue
+		}
+		order := "asc"
+		if srt.Desc {
+			order = "desc"
+		}
+		parts = append(parts, srt.Field+":"+order)
+	}
+	return strings.Join(parts, ",")
+}
+
+// openPointInTime opens a point-in-time on the given index or alias and
+// returns its ID.
+func (c *Client) openPointInTime(ctx context.Context, indexAlias string) (string, error) {
+	res, err := c.es.OpenPointInTime(
+		[]string{indexAlias},
+		pitKeepAlive,
+		c.es.OpenPointInTime.WithContext(ctx),
+	)
+	if err != nil {
+		return "", fmt.Errorf("open point in time: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		if res.StatusCode == 404 {
+			return "", fmt.Errorf("open point in time: %w", errSearchNotFound)
+		}
+		raw, _ := io.ReadAll(res.Body)
+		return "", fmt.Errorf("open point in time error: %s %s", res.Status(), string(raw))
+	}
+
+	var decoded struct {
+		ID string `json:"id"`
+	}
+	if err := json.UnmarshalRead(res.Body, &decoded); err != nil {
+		return "", fmt.Errorf("decode point in time response: %w", err)
+	}
+
+	return decoded.ID, nil
+}
+
+// closePointInTime releases a point-in-time before its keep-alive expires.
+func (c *Client) closePointInTime(ctx context.Context, pitID string) error {
+	b, err := json.Marshal(map[string]any{"id": pitID})
+	if err != nil {
+		return fmt.Errorf("marshal point in time body: %w", err)
+	}
+
+	res, err := c.es.ClosePointInTime(
+		c.es.ClosePointInTime.WithContext(ctx),
+		c.es.ClosePointInTime.WithBody(bytes.NewReader(b)),
+	)
+	if err != nil {
+		return fmt.Errorf("close point in time: %w", err)
+	}
+	defer res.Body.Close()
+
+	// 404: already expired, nothing to release.
+	if res.IsError() && res.StatusCode != 404 {
+		raw, _ := io.ReadAll(res.Body)
+		return fmt.Errorf("close point in time error: %s %s", res.Status(), string(raw))
+	}
+
+	return nil
+}
+
+// releasePointInTime closes a point-in-time, even if ctx is cancelled. A
+// failure is only logged, the point-in-time then expires on its own.
+func (c *Client) releasePointInTime(ctx context.Context, pitID string) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+	if err := c.closePointInTime(ctx, pitID); err != nil {
+		slog.Warn("failed to close point in time", "error", err)
+	}
+}
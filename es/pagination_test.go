@@ -0,0 +1,58 @@
+package es
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/theleeeo/indexer/gen/search/v1"
+)
+
+func TestPageToken_RoundTrip(t *testing.T) {
+	in := pageToken{
+		SearchAfter: []any{12.5, "abc"},
+		Sort:        "fields.price:desc",
+		PitID:       "pit-1",
+	}
+
+	s, err := encodePageToken(in)
+	require.NoError(t, err)
+
+	out, err := decodePageToken(s)
+	require.NoError(t, err)
+	require.Equal(t, in, out)
+}
+
+func TestPageToken_Invalid(t *testing.T) {
+	for _, s := range []string{"not base64!", "bm90IGpzb24", "e30"} {
+		_, err := decodePageToken(s)
+		require.ErrorIs(t, err, ErrInvalidPageToken, "token %q", s)
+	}
+}
+
+func TestBuildSort(t *testing.T) {
+	tiebreaker := map[string]any{"id": map[string]any{"order": "asc", "unmapped_type": "keyword"}}
+
+	require.Equal(t, []any{
+		map[string]any{"_score": map[string]any{"order": "desc"}},
+		tiebreaker,
+	}, buildSort(nil))
+
+	require.Equal(t, []any{
+		map[string]any{"fields.price": map[string]any{"order": "desc"}},
+		map[string]any{"fields.title": map[string]any{"order": "asc"}},
+		tiebreaker,
+	}, buildSort([]*search.Sort{
+		{Field: "fields.price", Desc: true},
+		nil,
+		{Field: "fields.title"},
+	}))
+}
+
+func TestSortFingerprint(t *testing.T) {
+	require.Equal(t, "", sortFingerprint(nil))
+	require.Equal(t, "fields.price:desc,fields.title:asc", sortFingerprint([]*search.Sort{
+		{Field: "fields.price", Desc: true},
+		{Field: ""},
+		{Field: "fields.title"},
+	}))
+}
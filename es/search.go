@@ -4,74 +4,159 @@ import (
 	"bytes"
 	"context"
 	"encoding/json/v2"
+	"errors"
 	"fmt"
 	"io"
+	"strings"
 	"time"
 
 	"github.com/theleeeo/indexer/gen/search/v1"
+	"github.com/theleeeo/indexer/resource"
+
+	"github.com/elastic/go-elasticsearch/v8/esapi"
 
 	"google.golang.org/protobuf/types/known/structpb"
 )
 
-func (c *Client) Search(ctx context.Context, req *search.SearchRequest, indexAlias string, searchFields []string) (*search.SearchResponse, error) {
-	boolQ := map[string]any{
-		"must":   []any{},
-		"filter": []any{},
+func (c *Client) Search(ctx context.Context, req *search.SearchRequest, indexAlias string, searchFields []string, mode resource.QueryModeConfig) (_ *search.SearchResponse, err error) {
+	query, err := buildQuery(req.Query, searchFields, mode, req.Filters, req.FilterGroup)
+	if err != nil {
+		return nil, err
 	}
 
-	// Always tenant filter
-	// boolQ["filter"] = append(boolQ["filter"].([]any), map[string]any{
-	// 	"term": map[string]any{"tenant_id": req.TenantId},
-	// })
+	body := map[string]any{
+		"query": query,
+		"size":  req.PageSize,
+		"sort":  buildSort(req.Sort),
+	}
 
-	// Full-text query (optional)
-	if req.Query != "" {
-		boolQ["must"] = append(boolQ["must"].([]any), map[string]any{
-			"multi_match": map[string]any{
-				"query":  req.Query,
-				"fields": searchFields,
-			},
-		})
+	// Source projection
+	switch {
+	case len(req.Fields) > 0:
+		body["_source"] = map[string]any{"includes": req.Fields}
+	case !req.IncludeSource:
+		body["_source"] = false
 	}
 
-	// Structured filters
-	for _, f := range req.Filters {
-		if f == nil || f.Field == "" {
-			continue
-		}
-		filterClause, err := buildFilterClause(f)
+	// Pagination: either search_after from a page token or plain from/size.
+	sortKey := sortFingerprint(req.Sort)
+	var pitID string
+	if req.PageToken != "" {
+		token, err := decodePageToken(req.PageToken)
 		if err != nil {
 			return nil, err
 		}
-		boolQ["filter"] = append(boolQ["filter"].([]any), filterClause)
+		if token.Sort != sortKey {
+			return nil, fmt.Errorf("%w: token was issued for a different sort", ErrInvalidPageToken)
+		}
+		body["search_after"] = token.SearchAfter
+		pitID = token.PitID
+	} else {
+		body["from"] = req.Page * req.PageSize
 	}
 
-	body := map[string]any{
-		"query": map[string]any{"bool": boolQ},
-		"from":  req.Page * req.PageSize,
-		"size":  req.PageSize,
+	// Highlighting (optional)
+	if req.Highlight != nil {
+		body["highlight"] = buildHighlight(req.Highlight)
+	}
+
+	// Aggregations (optional)
+	if len(req.Aggregations) > 0 {
+		aggs, err := buildAggregations(req.Aggregations)
+		if err != nil {
+			return nil, err
+		}
+		body["aggs"] = aggs
 	}
 
-	// Sort (optional). If none provided, ES default scoring applies.
-	if len(req.Sort) > 0 {
-		var sorts []any
-		for _, srt := range req.Sort {
-			if srt == nil || srt.Field == "" {
-				continue
+	// Opened last, so that an invalid request does not open one. A
+	// point-in-time opened here is released again if the search fails.
+	if pitID == "" && req.PointInTime {
+		pitID, err = c.openPointInTime(ctx, indexAlias)
+		if err != nil {
+			return nil, err
+		}
+		defer func() {
+			if err != nil {
+				c.releasePointInTime(ctx, pitID)
 			}
-			order := "asc"
-			if srt.Desc {
-				order = "desc"
+		}()
+	}
+
+	decoded, err := c.doSearch(ctx, indexAlias, pitID, body)
+	if err != nil {
+		if errors.Is(err, errSearchNotFound) {
+			if pitID != "" {
+				return nil, fmt.Errorf("%w: point in time expired", ErrInvalidPageToken)
 			}
-			sorts = append(sorts, map[string]any{
-				srt.Field: map[string]any{"order": order},
-			})
+			// index not found, return empty result
+			return &search.SearchResponse{Total: 0, Hits: []*search.SearchHit{}}, nil
 		}
-		if len(sorts) > 0 {
-			body["sort"] = sorts
+		return nil, err
+	}
+
+	hitsObj, _ := decoded["hits"].(map[string]any)
+
+	// total: { "value": N, "relation": "eq" } (ES7+)
+	var total int64
+	if t, ok := hitsObj["total"].(map[string]any); ok {
+		if v, ok := t["value"].(float64); ok {
+			total = int64(v)
 		}
 	}
 
+	out := &search.SearchResponse{Total: total}
+
+	// ES may hand out a new ID for the point-in-time on every search.
+	if id, ok := decoded["pit_id"].(string); ok && id != "" {
+		pitID = id
+	}
+
+	if len(req.Aggregations) > 0 {
+		aggsObj, _ := decoded["aggregations"].(map[string]any)
+		out.Aggregations = parseAggregations(req.Aggregations, aggsObj)
+	}
+
+	hits, _ := hitsObj["hits"].([]any)
+	var lastSort []any
+	if out.Hits, lastSort, err = parseHits(hits); err != nil {
+		return nil, err
+	}
+
+	// A full page means there may be more hits after it.
+	if len(hits) > 0 && len(hits) == int(req.PageSize) && len(lastSort) > 0 {
+		out.NextPageToken, err = encodePageToken(pageToken{
+			SearchAfter: lastSort,
+			Sort:        sortKey,
+			PitID:       pitID,
+		})
+		if err != nil {
+			return nil, err
+		}
+	} else if pitID != "" {
+		c.releasePointInTime(ctx, pitID)
+	}
+
+	return out, nil
+}
+
+// errSearchNotFound is returned by doSearch when the index or the
+// point-in-time does not exist.
+var errSearchNotFound = errors.New("search target not found")
+
+// doSearch runs a search with the given body and returns the decoded
+// response. When pitID is set the search runs against that point-in-time
+// instead of the index, as ES requires.
+func (c *Client) doSearch(ctx context.Context, indexAlias, pitID string, body map[string]any) (map[string]any, error) {
+	searchOpts := []func(*esapi.SearchRequest){
+		c.es.Search.WithContext(ctx),
+	}
+	if pitID != "" {
+		body["pit"] = map[string]any{"id": pitID, "keep_alive": pitKeepAlive}
+	} else {
+		searchOpts = append(searchOpts, c.es.Search.WithIndex(indexAlias))
+	}
+
 	b, err := json.Marshal(body)
 	if err != nil {
 		return nil, err
@@ -80,12 +165,9 @@ func (c *Client) Search(ctx context.Context, req *search.SearchRequest, indexAli
 	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
-	res, err := c.es.Search(
-		c.es.Search.WithContext(ctx),
-		c.es.Search.WithIndex(indexAlias),
+	res, err := c.es.Search(append(searchOpts,
 		c.es.Search.WithBody(bytes.NewReader(b)),
-		// c.es.Search.WithSource("false"),
-	)
+	)...)
 	if err != nil {
 		return nil, err
 	}
@@ -93,8 +175,7 @@ func (c *Client) Search(ctx context.Context, req *search.SearchRequest, indexAli
 
 	if res.IsError() {
 		if res.StatusCode == 404 {
-			// index not found, return empty result
-			return &search.SearchResponse{Total: 0, Hits: []*search.SearchHit{}}, nil
+			return nil, errSearchNotFound
 		}
 
 		raw, _ := io.ReadAll(res.Body)
@@ -106,53 +187,162 @@ func (c *Client) Search(ctx context.Context, req *search.SearchRequest, indexAli
 		return nil, err
 	}
 
-	hitsObj, _ := decoded["hits"].(map[string]any)
+	return decoded, nil
+}
 
-	// total: { "value": N, "relation": "eq" } (ES7+)
-	var total int64
-	if t, ok := hitsObj["total"].(map[string]any); ok {
-		if v, ok := t["value"].(float64); ok {
-			total = int64(v)
+// buildQuery compiles the full-text query and filters into a bool query.
+func buildQuery(query string, searchFields []string, mode resource.QueryModeConfig, filters []*search.Filter, group *search.FilterGroup) (map[string]any, error) {
+	boolQ := map[string]any{
+		"must":   []any{},
+		"filter": []any{},
+	}
+
+	// Always tenant filter
+	// boolQ["filter"] = append(boolQ["filter"].([]any), map[string]any{
+	// 	"term": map[string]any{"tenant_id": req.TenantId},
+	// })
+
+	// Full-text query (optional)
+	if query != "" {
+		boolQ["must"] = append(boolQ["must"].([]any), map[string]any{
+			"multi_match": buildMultiMatch(query, searchFields, mode),
+		})
+	}
+
+	// Structured filters
+	for _, f := range filters {
+		if f == nil || f.Field == "" {
+			continue
+		}
+		filterClause, err := buildFilterClause(f)
+		if err != nil {
+			return nil, err
 		}
+		boolQ["filter"] = append(boolQ["filter"].([]any), filterClause)
 	}
 
-	out := &search.SearchResponse{Total: total}
-	for _, h := range hitsObj["hits"].([]any) {
+	// Boolean filter tree (optional)
+	if group != nil {
+		groupClause, err := buildFilterGroupClause(group)
+		if err != nil {
+			return nil, err
+		}
+		if groupClause != nil {
+			boolQ["filter"] = append(boolQ["filter"].([]any), groupClause)
+		}
+	}
+
+	return map[string]any{"bool": boolQ}, nil
+}
+
+// buildMultiMatch builds the full-text multi_match query using the configured
+// query mode. Unset options are left to the ES defaults.
+func buildMultiMatch(query string, searchFields []string, mode resource.QueryModeConfig) map[string]any {
+	mm := map[string]any{
+		"query":  query,
+		"fields": searchFields,
+	}
+	if mode.Type != "" {
+		mm["type"] = mode.Type
+	}
+	if mode.Operator != "" {
+		mm["operator"] = mode.Operator
+	}
+	if mode.MinimumShouldMatch != "" {
+		mm["minimum_should_match"] = mode.MinimumShouldMatch
+	}
+	if mode.Fuzziness != "" {
+		mm["fuzziness"] = mode.Fuzziness
+	}
+	return mm
+}
+
+// parseHits converts raw ES hits into search hits. It also returns the sort
+// values of the last hit, to be used as search_after for the next page.
+func parseHits(hits []any) ([]*search.SearchHit, []any, error) {
+	var out []*search.SearchHit
+	var lastSort []any
+	for _, h := range hits {
 		m, _ := h.(map[string]any)
+		lastSort, _ = m["sort"].([]any)
 		id, _ := m["_id"].(string)
 		score, _ := m["_score"].(float64)
-		src, _ := m["_source"].(map[string]any)
+		highlight, _ := m["highlight"].(map[string]any)
 
-		st, err := structpb.NewStruct(src)
-		if err != nil {
-			// if struct conversion fails, skip rather than fail the whole query
-			continue
+		hit := &search.SearchHit{
+			Id:         id,
+			Score:      score,
+			Highlights: parseHighlights(highlight),
 		}
-		out.Hits = append(out.Hits, &search.SearchHit{
-			Id:     id,
-			Score:  score,
-			Source: st,
-		})
-	}
 
-	return out, nil
+		// _source is missing when it was not requested.
+		if src, ok := m["_source"].(map[string]any); ok {
+			st, err := structpb.NewStruct(src)
+			if err != nil {
+				return nil, nil, fmt.Errorf("convert _source of hit %q: %w", id, err)
+			}
+			hit.Source = st
+		}
+
+		out = append(out, hit)
+	}
+	return out, lastSort, nil
 }
 
 func buildFilterClause(f *search.Filter) (any, error) {
 	var inner any
+	negate := false
 
 	switch f.Op {
-	case search.FilterOp_FILTER_OP_EQ:
+	case search.FilterOp_FILTER_OP_EQ, search.FilterOp_FILTER_OP_NOT_EQ:
 		if f.Value == "" {
-			return nil, fmt.Errorf("EQ filter requires value for field %q", f.Field)
+			return nil, fmt.Errorf("%s filter requires value for field %q", opName(f.Op), f.Field)
 		}
 		inner = map[string]any{"term": map[string]any{f.Field: f.Value}}
+		negate = f.Op == search.FilterOp_FILTER_OP_NOT_EQ
 
-	case search.FilterOp_FILTER_OP_IN:
+	case search.FilterOp_FILTER_OP_IN, search.FilterOp_FILTER_OP_NOT_IN:
 		if len(f.Values) == 0 {
-			return nil, fmt.Errorf("IN filter requires values for field %q", f.Field)
+			return nil, fmt.Errorf("%s filter requires values for field %q", opName(f.Op), f.Field)
 		}
 		inner = map[string]any{"terms": map[string]any{f.Field: f.Values}}
+		negate = f.Op == search.FilterOp_FILTER_OP_NOT_IN
+
+	case search.FilterOp_FILTER_OP_RANGE:
+		bounds := map[string]any{}
+		if r := f.Range; r != nil {
+			if r.Gt != "" {
+				bounds["gt"] = r.Gt
+			}
+			if r.Gte != "" {
+				bounds["gte"] = r.Gte
+			}
+			if r.Lt != "" {
+				bounds["lt"] = r.Lt
+			}
+			if r.Lte != "" {
+				bounds["lte"] = r.Lte
+			}
+		}
+		if len(bounds) == 0 {
+			return nil, fmt.Errorf("RANGE filter requires at least one bound for field %q", f.Field)
+		}
+		inner = map[string]any{"range": map[string]any{f.Field: bounds}}
+
+	case search.FilterOp_FILTER_OP_EXISTS:
+		inner = map[string]any{"exists": map[string]any{"field": f.Field}}
+
+	case search.FilterOp_FILTER_OP_PREFIX:
+		if f.Value == "" {
+			return nil, fmt.Errorf("PREFIX filter requires value for field %q", f.Field)
+		}
+		inner = map[string]any{"prefix": map[string]any{f.Field: f.Value}}
+
+	case search.FilterOp_FILTER_OP_WILDCARD:
+		if f.Value == "" {
+			return nil, fmt.Errorf("WILDCARD filter requires value for field %q", f.Field)
+		}
+		inner = map[string]any{"wildcard": map[string]any{f.Field: f.Value}}
 
 	default:
 		return nil, fmt.Errorf("unsupported filter op for field %q", f.Field)
@@ -160,13 +350,95 @@ func buildFilterClause(f *search.Filter) (any, error) {
 
 	// Nested wrapping (optional)
 	if f.NestedPath != "" {
-		return map[string]any{
+		inner = map[string]any{
 			"nested": map[string]any{
 				"path":  f.NestedPath,
 				"query": inner,
 			},
+		}
+	}
+
+	// Negation wraps the nested query rather than the other way around, so that
+	// NOT_EQ on a nested relation means "no related item matches" instead of
+	// "some related item does not match".
+	if negate {
+		return map[string]any{
+			"bool": map[string]any{
+				"must_not": []any{inner},
+			},
 		}, nil
 	}
 
 	return inner, nil
 }
+
+// buildFilterGroupClause compiles a filter group into a bool query, recursing
+// into child groups. Groups with a nested path are wrapped in a single nested
+// query. Empty groups compile to nil so that they can be skipped.
+func buildFilterGroupClause(g *search.FilterGroup) (any, error) {
+	var clauses []any
+
+	for _, f := range g.Filters {
+		if f == nil || f.Field == "" {
+			continue
+		}
+		clause, err := buildFilterClause(f)
+		if err != nil {
+			return nil, err
+		}
+		clauses = append(clauses, clause)
+	}
+
+	for _, child := range g.Groups {
+		if child == nil {
+			continue
+		}
+		clause, err := buildFilterGroupClause(child)
+		if err != nil {
+			return nil, err
+		}
+		if clause != nil {
+			clauses = append(clauses, clause)
+		}
+	}
+
+	if len(clauses) == 0 {
+		return nil, nil
+	}
+
+	var clause any
+	switch g.Op {
+	case search.GroupOp_GROUP_OP_UNSPECIFIED, search.GroupOp_GROUP_OP_AND:
+		clause = map[string]any{"bool": map[string]any{"must": clauses}}
+
+	case search.GroupOp_GROUP_OP_OR:
+		clause = map[string]any{"bool": map[string]any{
+			"should":               clauses,
+			"minimum_should_match": 1,
+		}}
+
+	case search.GroupOp_GROUP_OP_NOT:
+		clause = map[string]any{"bool": map[string]any{"must_not": clauses}}
+
+	default:
+		return nil, fmt.Errorf("unsupported filter group op %s", g.Op)
+	}
+
+	// A nested group evaluates all of its children against the same item.
+	if g.NestedPath != "" {
+		return map[string]any{
+			"nested": map[string]any{
+				"path":  g.NestedPath,
+				"query": clause,
+			},
+		}, nil
+	}
+
+	return clause, nil
+}
+
+// opName returns the short name of a filter op for use in error messages,
+// e.g. "NOT_EQ" for FILTER_OP_NOT_EQ.
+func opName(op search.FilterOp) string {
+	return strings.TrimPrefix(op.String(), "FILTER_OP_")
+}
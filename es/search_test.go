@@ -0,0 +1,255 @@
+package es
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/elastic/go-elasticsearch/v8"
+	"github.com/stretchr/testify/require"
+	"github.com/theleeeo/indexer/gen/search/v1"
+	"github.com/theleeeo/indexer/resource"
+)
+
+func TestBuildFilterClause(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter *search.Filter
+		want   any
+	}{
+		{
+			name:   "eq",
+			filter: &search.Filter{Field: "fields.status", Op: search.FilterOp_FILTER_OP_EQ, Value: "open"},
+			want:   map[string]any{"term": map[string]any{"fields.status": "open"}},
+		},
+		{
+			name:   "range",
+			filter: &search.Filter{Field: "fields.price", Op: search.FilterOp_FILTER_OP_RANGE, Range: &search.RangeBounds{Gte: "10", Lt: "50"}},
+			want:   map[string]any{"range": map[string]any{"fields.price": map[string]any{"gte": "10", "lt": "50"}}},
+		},
+		{
+			name:   "exists",
+			filter: &search.Filter{Field: "b.name", Op: search.FilterOp_FILTER_OP_EXISTS},
+			want:   map[string]any{"exists": map[string]any{"field": "b.name"}},
+		},
+		{
+			name:   "prefix",
+			filter: &search.Filter{Field: "fields.sku", Op: search.FilterOp_FILTER_OP_PREFIX, Value: "AB-"},
+			want:   map[string]any{"prefix": map[string]any{"fields.sku": "AB-"}},
+		},
+		{
+			name:   "wildcard",
+			filter: &search.Filter{Field: "fields.sku", Op: search.FilterOp_FILTER_OP_WILDCARD, Value: "AB-*-X"},
+			want:   map[string]any{"wildcard": map[string]any{"fields.sku": "AB-*-X"}},
+		},
+		{
+			name:   "not eq",
+			filter: &search.Filter{Field: "fields.status", Op: search.FilterOp_FILTER_OP_NOT_EQ, Value: "archived"},
+			want: map[string]any{"bool": map[string]any{"must_not": []any{
+				map[string]any{"term": map[string]any{"fields.status": "archived"}},
+			}}},
+		},
+		{
+			name:   "not in nested",
+			filter: &search.Filter{Field: "c.state", Op: search.FilterOp_FILTER_OP_NOT_IN, Values: []string{"a", "b"}, NestedPath: "c"},
+			want: map[string]any{"bool": map[string]any{"must_not": []any{
+				map[string]any{"nested": map[string]any{
+					"path":  "c",
+					"query": map[string]any{"terms": map[string]any{"c.state": []string{"a", "b"}}},
+				}},
+			}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := buildFilterClause(tt.filter)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestBuildFilterClause_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  *search.Filter
+		wantErr string
+	}{
+		{"not eq without value", &search.Filter{Field: "f", Op: search.FilterOp_FILTER_OP_NOT_EQ}, `NOT_EQ filter requires value for field "f"`},
+		{"range without bounds", &search.Filter{Field: "f", Op: search.FilterOp_FILTER_OP_RANGE, Range: &search.RangeBounds{}}, `RANGE filter requires at least one bound for field "f"`},
+		{"prefix without value", &search.Filter{Field: "f", Op: search.FilterOp_FILTER_OP_PREFIX}, `PREFIX filter requires value for field "f"`},
+		{"unspecified", &search.Filter{Field: "f"}, `unsupported filter op for field "f"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := buildFilterClause(tt.filter)
+			require.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestBuildFilterGroupClause(t *testing.T) {
+	// (status=open OR status=pending) AND NOT owner=x
+	group := &search.FilterGroup{
+		Op: search.GroupOp_GROUP_OP_AND,
+		Groups: []*search.FilterGroup{
+			{
+				Op: search.GroupOp_GROUP_OP_OR,
+				Filters: []*search.Filter{
+					{Field: "fields.status", Op: search.FilterOp_FILTER_OP_EQ, Value: "open"},
+					{Field: "fields.status", Op: search.FilterOp_FILTER_OP_EQ, Value: "pending"},
+				},
+			},
+			{
+				Op: search.GroupOp_GROUP_OP_NOT,
+				Filters: []*search.Filter{
+					{Field: "fields.owner", Op: search.FilterOp_FILTER_OP_EQ, Value: "x"},
+				},
+			},
+			// Empty groups are dropped.
+			{Op: search.GroupOp_GROUP_OP_OR},
+		},
+	}
+
+	got, err := buildFilterGroupClause(group)
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"bool": map[string]any{"must": []any{
+		map[string]any{"bool": map[string]any{
+			"should": []any{
+				map[string]any{"term": map[string]any{"fields.status": "open"}},
+				map[string]any{"term": map[string]any{"fields.status": "pending"}},
+			},
+			"minimum_should_match": 1,
+		}},
+		map[string]any{"bool": map[string]any{"must_not": []any{
+			map[string]any{"term": map[string]any{"fields.owner": "x"}},
+		}}},
+	}}}, got)
+}
+
+func TestBuildFilterGroupClause_Empty(t *testing.T) {
+	got, err := buildFilterGroupClause(&search.FilterGroup{Op: search.GroupOp_GROUP_OP_NOT})
+	require.NoError(t, err)
+	require.Nil(t, got)
+}
+
+func TestBuildFilterGroupClause_InvalidChild(t *testing.T) {
+	_, err := buildFilterGroupClause(&search.FilterGroup{
+		Groups: []*search.FilterGroup{
+			{Filters: []*search.Filter{{Field: "f", Op: search.FilterOp_FILTER_OP_EQ}}},
+		},
+	})
+	require.EqualError(t, err, `EQ filter requires value for field "f"`)
+}
+
+func TestBuildFilterGroupClause_NestedPath(t *testing.T) {
+	got, err := buildFilterGroupClause(&search.FilterGroup{
+		NestedPath: "c",
+		Filters: []*search.Filter{
+			{Field: "c.state", Op: search.FilterOp_FILTER_OP_EQ, Value: "active"},
+			{Field: "c.number", Op: search.FilterOp_FILTER_OP_EQ, Value: "5"},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"nested": map[string]any{
+		"path": "c",
+		"query": map[string]any{"bool": map[string]any{"must": []any{
+			map[string]any{"term": map[string]any{"c.state": "active"}},
+			map[string]any{"term": map[string]any{"c.number": "5"}},
+		}}},
+	}}, got)
+}
+
+func TestParseHits(t *testing.T) {
+	hits, lastSort, err := parseHits([]any{
+		map[string]any{
+			"_id":     "1",
+			"_score":  1.5,
+			"_source": map[string]any{"fields": map[string]any{"title": "a"}},
+			"sort":    []any{1.5, "1"},
+		},
+		// No _source when include_source is off.
+		map[string]any{
+			"_id":    "2",
+			"_score": 0.5,
+			"sort":   []any{0.5, "2"},
+		},
+	})
+	require.NoError(t, err)
+
+	require.Len(t, hits, 2)
+	require.Equal(t, "1", hits[0].Id)
+	require.Equal(t, "a", hits[0].Source.Fields["fields"].GetStructValue().Fields["title"].GetStringValue())
+	require.Equal(t, "2", hits[1].Id)
+	require.Nil(t, hits[1].Source)
+	require.Equal(t, []any{0.5, "2"}, lastSort)
+}
+
+func TestParseHits_InvalidSource(t *testing.T) {
+	hits, lastSort, err := parseHits([]any{
+		map[string]any{"_id": "1", "_source": map[string]any{"title": "ok"}, "sort": []any{"1"}},
+		map[string]any{"_id": "2", "_source": map[string]any{"title": "\xff"}, "sort": []any{"2"}},
+	})
+	// No partial page, the hits would not match the total or the cursor.
+	require.ErrorContains(t, err, `convert _source of hit "2"`)
+	require.Nil(t, hits)
+	require.Nil(t, lastSort)
+}
+
+func TestBuildMultiMatch(t *testing.T) {
+	fields := []string{"fields.title^2", "b.name"}
+
+	require.Equal(t, map[string]any{
+		"query":  "red shoe",
+		"fields": fields,
+	}, buildMultiMatch("red shoe", fields, resource.QueryModeConfig{}))
+
+	require.Equal(t, map[string]any{
+		"query":                "red shoe",
+		"fields":               fields,
+		"type":                 "most_fields",
+		"operator":             "and",
+		"minimum_should_match": "75%",
+		"fuzziness":            "AUTO",
+	}, buildMultiMatch("red shoe", fields, resource.QueryModeConfig{
+		Type:               resource.MultiMatchMostFields,
+		Operator:           "and",
+		MinimumShouldMatch: "75%",
+		Fuzziness:          "AUTO",
+	}))
+}
+
+func TestSearch_ReleasesPointInTimeOnError(t *testing.T) {
+	var mu sync.Mutex
+	var requests []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		mu.Unlock()
+
+		w.Header().Set("X-Elastic-Product", "Elasticsearch")
+		switch r.URL.Path {
+		case "/products/_pit":
+			w.Write([]byte(`{"id":"pit-1"}`))
+		case "/_search":
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"error":"boom"}`))
+		default:
+			w.Write([]byte(`{}`))
+		}
+	}))
+	defer srv.Close()
+
+	esClient, err := elasticsearch.NewClient(elasticsearch.Config{Addresses: []string{srv.URL}})
+	require.NoError(t, err)
+	c := New(esClient, false)
+
+	_, err = c.Search(context.Background(), &search.SearchRequest{PageSize: 10, PointInTime: true}, "products", nil, resource.QueryModeConfig{})
+	require.ErrorContains(t, err, "es search error")
+
+	require.Equal(t, []string{"POST /products/_pit", "POST /_search", "DELETE /_pit"}, requests)
+}
@@ -0,0 +1,131 @@
+package es
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/theleeeo/indexer/gen/search/v1"
+	"github.com/theleeeo/indexer/resource"
+)
+
+// Suggest completes the prefix of the request against the suggest sub-fields
+// of req.Fields and returns one suggestion per matching document, ordered by
+// relevance. req.Fields must be set.
+func (c *Client) Suggest(ctx context.Context, req *search.SuggestRequest, indexAlias string) (*search.SuggestResponse, error) {
+	var queryFields []string
+	highlightFields := make(map[string]any, len(req.Fields))
+	for _, f := range req.Fields {
+		sf := f + "." + resource.SuggestSubField
+		// The shingle sub-fields ES adds to search_as_you_type fields improve
+		// the ranking of multi-word prefixes.
+		queryFields = append(queryFields, sf, sf+"._2gram", sf+"._3gram")
+		highlightFields[sf] = map[string]any{}
+	}
+
+	query, err := buildQuery(req.Prefix, queryFields, resource.QueryModeConfig{Type: "bool_prefix"}, req.Filters, req.FilterGroup)
+	if err != nil {
+		return nil, err
+	}
+
+	body := map[string]any{
+		"query":   query,
+		"size":    req.Size,
+		"_source": map[string]any{"includes": req.Fields},
+		// The highlight tells which field matched. Without tags and fragments
+		// it holds the complete matching value.
+		"highlight": map[string]any{
+			"fields":              highlightFields,
+			"number_of_fragments": 0,
+			"pre_tags":            []string{""},
+			"post_tags":           []string{""},
+		},
+	}
+
+	decoded, err := c.doSearch(ctx, indexAlias, "", body)
+	if err != nil {
+		if errors.Is(err, errSearchNotFound) {
+			// index not found, return empty result
+			return &search.SuggestResponse{}, nil
+		}
+		return nil, err
+	}
+
+	hitsObj, _ := decoded["hits"].(map[string]any)
+	rawHits, _ := hitsObj["hits"].([]any)
+
+	out := &search.SuggestResponse{}
+	for _, h := range rawHits {
+		m, _ := h.(map[string]any)
+		id, _ := m["_id"].(string)
+		score, _ := m["_score"].(float64)
+		highlight, _ := m["highlight"].(map[string]any)
+		src, _ := m["_source"].(map[string]any)
+
+		field, text := suggestionText(req.Fields, highlight, src)
+		if text == "" {
+			continue
+		}
+
+		out.Suggestions = append(out.Suggestions, &search.Suggestion{
+			Text:  text,
+			Field: field,
+			Id:    id,
+			Score: score,
+		})
+	}
+
+	return out, nil
+}
+
+// suggestionText picks the matched value of a hit. It prefers the first
+// field, in request order, that has a highlight, and falls back to the first
+// field with a string value in the source.
+func suggestionText(fields []string, highlight, src map[string]any) (string, string) {
+	for _, f := range fields {
+		fragments, _ := highlight[f+"."+resource.SuggestSubField].([]any)
+		for _, frag := range fragments {
+			if s, ok := frag.(string); ok && s != "" {
+				return f, s
+			}
+		}
+	}
+
+	for _, f := range fields {
+		if s := sourceString(src, f); s != "" {
+			return f, s
+		}
+	}
+
+	return "", ""
+}
+
+// sourceString returns the first string value at a dotted path in a document
+// source, descending into arrays of objects.
+func sourceString(v any, path string) string {
+	switch t := v.(type) {
+	case map[string]any:
+		if path == "" {
+			return ""
+		}
+		head, rest, _ := strings.Cut(path, ".")
+		return sourceString(t[head], rest)
+
+	case []any:
+		for _, item := range t {
+			if s := sourceString(item, path); s != "" {
+				return s
+			}
+		}
+		return ""
+
+	case string:
+		if path != "" {
+			return ""
+		}
+		return t
+
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,47 @@
+package es
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSuggestionText(t *testing.T) {
+	fields := []string{"fields.title", "b.name"}
+	src := map[string]any{
+		"fields": map[string]any{"title": "Red shoe"},
+		"b":      []any{map[string]any{"name": "Acme"}},
+	}
+
+	t.Run("highlighted field", func(t *testing.T) {
+		field, text := suggestionText(fields, map[string]any{
+			"b.name.suggest": []any{"Acme"},
+		}, src)
+		require.Equal(t, "b.name", field)
+		require.Equal(t, "Acme", text)
+	})
+
+	t.Run("falls back to source", func(t *testing.T) {
+		field, text := suggestionText(fields, nil, src)
+		require.Equal(t, "fields.title", field)
+		require.Equal(t, "Red shoe", text)
+	})
+
+	t.Run("no value", func(t *testing.T) {
+		_, text := suggestionText(fields, nil, map[string]any{})
+		require.Empty(t, text)
+	})
+}
+
+func TestSourceString(t *testing.T) {
+	src := map[string]any{
+		"fields": map[string]any{"title": "Red shoe", "price": 10.0},
+		"b":      []any{map[string]any{"other": "x"}, map[string]any{"name": "Acme"}},
+	}
+
+	require.Equal(t, "Red shoe", sourceString(src, "fields.title"))
+	require.Equal(t, "Acme", sourceString(src, "b.name"))
+	require.Empty(t, sourceString(src, "fields.price"))
+	require.Empty(t, sourceString(src, "fields"))
+	require.Empty(t, sourceString(src, "fields.missing"))
+}
@@ -0,0 +1,415 @@
+// Package expr implements the expression language of computed fields.
+//
+// Expressions are side-effect free and always terminate. They are built from
+// literals ("text", 'text', 12.5, true, false, null), references to fields
+// (first_name, or orders.total for the totals of all items of a relation),
+// the operators + - * / == != < <= > >= && || ! and function calls, e.g.
+//
+//	concat(first_name, " ", last_name)
+//	count(orders, status == "open") > 0
+//	sum(lines, quantity * price)
+//	date_trunc("month", created_at)
+//
+// Values are null, booleans, numbers (float64), strings, lists and objects.
+// Operations on null yield null, so missing data does not fail evaluation.
+package expr
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Expr is a parsed expression.
+type Expr struct {
+	src  string
+	root node
+}
+
+// Parse parses an expression.
+func Parse(src string) (*Expr, error) {
+	if len(src) > maxLength {
+		return nil, fmt.Errorf("expression is longer than %d bytes", maxLength)
+	}
+
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	root, err := p.parseBinary(0)
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tokEOF {
+		return nil, p.unexpected()
+	}
+
+	return &Expr{src: src, root: root}, nil
+}
+
+// String returns the source of the expression.
+func (e *Expr) String() string {
+	return e.src
+}
+
+// Eval evaluates the expression with the given variables. Variables hold the
+// values of references, e.g. the fields of a resource and its relations as
+// lists of objects.
+func (e *Expr) Eval(vars map[string]any) (any, error) {
+	return e.root.eval(&scope{vars: vars})
+}
+
+// Type returns the Elasticsearch type of the expression's result, or "" if
+// it cannot be determined without evaluating it, e.g. for a plain reference.
+func (e *Expr) Type() string {
+	return e.root.typ()
+}
+
+// scope resolves references. Functions iterating over a list evaluate their
+// per-item argument in a child scope holding the item's fields.
+type scope struct {
+	vars   map[string]any
+	parent *scope
+}
+
+func (s *scope) lookup(name string) (any, bool) {
+	for ; s != nil; s = s.parent {
+		if v, ok := s.vars[name]; ok {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+type node interface {
+	eval(s *scope) (any, error)
+	typ() string
+}
+
+type literalNode struct {
+	value any
+}
+
+func (n *literalNode) eval(*scope) (any, error) {
+	return n.value, nil
+}
+
+func (n *literalNode) typ() string {
+	return typeOf(n.value)
+}
+
+// refNode references a variable, or a field of it with a dotted path. On a
+// list the path applies to every item and yields a list.
+type refNode struct {
+	path []string
+}
+
+func (n *refNode) eval(s *scope) (any, error) {
+	v, _ := s.lookup(n.path[0])
+	v = normalize(v)
+	for _, field := range n.path[1:] {
+		v = getField(v, field)
+	}
+	return v, nil
+}
+
+func (n *refNode) typ() string {
+	return ""
+}
+
+func getField(v any, field string) any {
+	switch v := v.(type) {
+	case map[string]any:
+		return normalize(v[field])
+	case []any:
+		var out []any
+		for _, item := range v {
+			switch fv := getField(item, field).(type) {
+			case nil:
+			case []any:
+				out = append(out, fv...)
+			default:
+				out = append(out, fv)
+			}
+		}
+		return out
+	default:
+		return nil
+	}
+}
+
+type unaryNode struct {
+	op      string
+	operand node
+}
+
+func (n *unaryNode) eval(s *scope) (any, error) {
+	v, err := n.operand.eval(s)
+	if err != nil {
+		return nil, err
+	}
+
+	if n.op == "!" {
+		return !truthy(v), nil
+	}
+	if v == nil {
+		return nil, nil
+	}
+	f, ok := v.(float64)
+	if !ok {
+		return nil, fmt.Errorf("cannot negate %s", describe(v))
+	}
+	return -f, nil
+}
+
+func (n *unaryNode) typ() string {
+	if n.op == "!" {
+		return "boolean"
+	}
+	return "double"
+}
+
+type binaryNode struct {
+	op          string
+	left, right node
+}
+
+func (n *binaryNode) eval(s *scope) (any, error) {
+	l, err := n.left.eval(s)
+	if err != nil {
+		return nil, err
+	}
+
+	// Logical operators short-circuit.
+	switch n.op {
+	case "&&":
+		if !truthy(l) {
+			return false, nil
+		}
+		r, err := n.right.eval(s)
+		return truthy(r), err
+	case "||":
+		if truthy(l) {
+			return true, nil
+		}
+		r, err := n.right.eval(s)
+		return truthy(r), err
+	}
+
+	r, err := n.right.eval(s)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "==":
+		return reflect.DeepEqual(l, r), nil
+	case "!=":
+		return !reflect.DeepEqual(l, r), nil
+	case "<", "<=", ">", ">=":
+		c, ok, err := compare(l, r)
+		if err != nil || !ok {
+			return false, err
+		}
+		switch n.op {
+		case "<":
+			return c < 0, nil
+		case "<=":
+			return c <= 0, nil
+		case ">":
+			return c > 0, nil
+		default:
+			return c >= 0, nil
+		}
+	}
+
+	if l == nil || r == nil {
+		return nil, nil
+	}
+
+	if n.op == "+" {
+		if ls, ok := l.(string); ok {
+			return ls + toString(r), nil
+		}
+		if rs, ok := r.(string); ok {
+			return toString(l) + rs, nil
+		}
+	}
+
+	lf, lok := l.(float64)
+	rf, rok := r.(float64)
+	if !lok || !rok {
+		return nil, fmt.Errorf("operator %s is not supported on %s and %s", n.op, describe(l), describe(r))
+	}
+	switch n.op {
+	case "+":
+		return lf + rf, nil
+	case "-":
+		return lf - rf, nil
+	case "*":
+		return lf * rf, nil
+	default:
+		if rf == 0 {
+			return nil, nil
+		}
+		return lf / rf, nil
+	}
+}
+
+func (n *binaryNode) typ() string {
+	switch n.op {
+	case "&&", "||", "==", "!=", "<", "<=", ">", ">=":
+		return "boolean"
+	case "+":
+		lt, rt := n.left.typ(), n.right.typ()
+		if lt == "keyword" || rt == "keyword" {
+			return "keyword"
+		}
+		if isNumber(lt) && isNumber(rt) {
+			return "double"
+		}
+		return ""
+	default:
+		return "double"
+	}
+}
+
+type callNode struct {
+	name string
+	fn   function
+	args []node
+}
+
+func (n *callNode) eval(s *scope) (any, error) {
+	v, err := n.fn.call(s, n.args)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", n.name, err)
+	}
+	return v, nil
+}
+
+func (n *callNode) typ() string {
+	if n.fn.typ != nil {
+		return n.fn.typ(n.args)
+	}
+	return n.fn.result
+}
+
+// normalize converts the numeric types of decoded data to float64 and typed
+// slices and maps to their generic form.
+func normalize(v any) any {
+	switch v := v.(type) {
+	case int:
+		return float64(v)
+	case int32:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case float32:
+		return float64(v)
+	case []map[string]any:
+		out := make([]any, len(v))
+		for i, m := range v {
+			out[i] = m
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+func truthy(v any) bool {
+	switch v := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case float64:
+		return v != 0
+	case string:
+		return v != ""
+	case []any:
+		return len(v) > 0
+	default:
+		return true
+	}
+}
+
+// compare compares two numbers or two strings. It reports false if either
+// is null.
+func compare(l, r any) (int, bool, error) {
+	if l == nil || r == nil {
+		return 0, false, nil
+	}
+	switch lv := l.(type) {
+	case float64:
+		if rv, ok := r.(float64); ok {
+			switch {
+			case lv < rv:
+				return -1, true, nil
+			case lv > rv:
+				return 1, true, nil
+			}
+			return 0, true, nil
+		}
+	case string:
+		if rv, ok := r.(string); ok {
+			switch {
+			case lv < rv:
+				return -1, true, nil
+			case lv > rv:
+				return 1, true, nil
+			}
+			return 0, true, nil
+		}
+	}
+	return 0, false, fmt.Errorf("cannot compare %s and %s", describe(l), describe(r))
+}
+
+func toString(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func describe(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "a boolean"
+	case float64:
+		return "a number"
+	case string:
+		return "a string"
+	case []any:
+		return "a list"
+	case map[string]any:
+		return "an object"
+	default:
+		return fmt.Sprintf("a %T", v)
+	}
+}
+
+func typeOf(v any) string {
+	switch v.(type) {
+	case bool:
+		return "boolean"
+	case float64:
+		return "double"
+	case string:
+		return "keyword"
+	default:
+		return ""
+	}
+}
+
+func isNumber(t string) bool {
+	return t == "double" || t == "long"
+}
@@ -0,0 +1,150 @@
+package expr
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEval(t *testing.T) {
+	vars := map[string]any{
+		"first_name": "Ada",
+		"last_name":  "Lovelace",
+		"nickname":   nil,
+		"age":        36,
+		"created_at": "2024-05-17T13:45:00Z",
+		"orders": []map[string]any{
+			{"status": "open", "total": 10.5, "created_at": "2024-03-01"},
+			{"status": "closed", "total": 4.5, "created_at": "2024-01-15"},
+			{"status": "open", "total": 5.0, "created_at": "2024-02-10"},
+		},
+		"lines": []any{
+			map[string]any{"quantity": 2.0, "price": 3.0},
+			map[string]any{"quantity": 1.0, "price": 4.0},
+		},
+	}
+
+	tests := []struct {
+		src  string
+		want any
+	}{
+		{`concat(first_name, " ", last_name)`, "Ada Lovelace"},
+		{`first_name + " " + last_name`, "Ada Lovelace"},
+		{`upper(first_name)`, "ADA"},
+		{`coalesce(nickname, first_name)`, "Ada"},
+		{`age + 1`, 37.0},
+		{`-age * 2 / 4`, -18.0},
+		{`(1 + 2) * 3`, 9.0},
+		{`age / 0`, nil},
+		{`nickname + 1`, nil},
+		{`count(orders)`, 3.0},
+		{`count(orders, status == "open")`, 2.0},
+		{`count(orders, status == "open") > 0`, true},
+		{`any(orders, total > 10 && status == 'open')`, true},
+		{`all(orders, total > 5)`, false},
+		{`sum(orders.total)`, 20.0},
+		{`sum(lines, quantity * price)`, 10.0},
+		{`min(orders.total)`, 4.5},
+		{`max(orders.created_at)`, "2024-03-01"},
+		{`avg(orders.total)`, 20.0 / 3},
+		{`max(missing)`, nil},
+		{`count(missing)`, 0.0},
+		{`if(age >= 18, "adult", "minor")`, "adult"},
+		{`!(age < 18) || false`, true},
+		{`nickname == null`, true},
+		{`date_trunc("month", created_at)`, "2024-05-01T00:00:00Z"},
+		{`date_trunc("week", created_at)`, "2024-05-13T00:00:00Z"},
+		{`date_trunc("day", "2024-05-17")`, "2024-05-17T00:00:00Z"},
+		{`date_trunc("day", nickname)`, nil},
+		{`number("12.5") + 1`, 13.5},
+		{`string(age)`, "36"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			e, err := Parse(tt.src)
+			require.NoError(t, err)
+			got, err := e.Eval(vars)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestEval_Errors(t *testing.T) {
+	vars := map[string]any{"name": "x", "tags": []any{"a"}}
+
+	tests := []struct {
+		src     string
+		wantErr string
+	}{
+		{`name * 2`, `operator * is not supported on a string and a number`},
+		{`name < 1`, `cannot compare a string and a number`},
+		{`sum(tags)`, `sum: expected numbers, got a string`},
+		{`date_trunc("decade", "2024-01-01")`, `date_trunc: unknown unit "decade", must be year, month, week, day, hour or minute`},
+		{`date_trunc("day", "yesterday")`, `date_trunc: invalid date "yesterday"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			e, err := Parse(tt.src)
+			require.NoError(t, err)
+			_, err = e.Eval(vars)
+			require.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		src     string
+		wantErr string
+	}{
+		{``, `unexpected end of expression`},
+		{`1 +`, `unexpected end of expression`},
+		{`a b`, `unexpected "b" at 2`},
+		{`(1`, `unexpected end of expression`},
+		{`a = 1`, `unexpected character '=' at 2`},
+		{`"open`, `unterminated string at 0`},
+		{`a..b`, `invalid reference "a..b" at 0`},
+		{`exec("rm")`, `unknown function "exec" at 0`},
+		{`lower(a, b)`, `function "lower" takes 1 arguments, got 2`},
+		{`if(a)`, `function "if" takes 3 arguments, got 1`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			_, err := Parse(tt.src)
+			require.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestType(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{`concat(a, b)`, "keyword"},
+		{`a + " "`, "keyword"},
+		{`count(orders)`, "long"},
+		{`sum(orders.total)`, "double"},
+		{`count(orders, status == "open") > 0`, "boolean"},
+		{`date_trunc("day", created_at)`, "date"},
+		{`if(a, 1, 2)`, "double"},
+		{`coalesce(a, "none")`, "keyword"},
+		{`a`, ""},
+		{`max(orders.total)`, ""},
+		{`max(orders, total * 2)`, "double"},
+		{`min(orders, date_trunc("day", created_at))`, "date"},
+		{`min(concat(a, b))`, "keyword"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			e, err := Parse(tt.src)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, e.Type())
+		})
+	}
+}
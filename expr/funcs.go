@@ -0,0 +1,376 @@
+package expr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type function struct {
+	minArgs, maxArgs int // maxArgs -1 for variadic
+
+	// call evaluates the function. Arguments are passed unevaluated so that
+	// functions can evaluate them lazily or once per list item.
+	call func(s *scope, args []node) (any, error)
+
+	// result is the Elasticsearch type of the result, or typ computes it
+	// from the arguments.
+	result string
+	typ    func(args []node) string
+}
+
+func (f function) arity() string {
+	switch {
+	case f.maxArgs < 0:
+		return fmt.Sprintf("at least %d arguments", f.minArgs)
+	case f.minArgs == f.maxArgs:
+		return fmt.Sprintf("%d arguments", f.minArgs)
+	default:
+		return fmt.Sprintf("%d to %d arguments", f.minArgs, f.maxArgs)
+	}
+}
+
+var functions map[string]function
+
+func init() {
+	// Assigned in init since the functions refer back to the evaluator.
+	functions = map[string]function{
+		"concat":   {minArgs: 1, maxArgs: -1, call: concat, result: "keyword"},
+		"lower":    {minArgs: 1, maxArgs: 1, call: stringFunc(strings.ToLower), result: "keyword"},
+		"upper":    {minArgs: 1, maxArgs: 1, call: stringFunc(strings.ToUpper), result: "keyword"},
+		"trim":     {minArgs: 1, maxArgs: 1, call: stringFunc(strings.TrimSpace), result: "keyword"},
+		"string":   {minArgs: 1, maxArgs: 1, call: toStringFunc, result: "keyword"},
+		"number":   {minArgs: 1, maxArgs: 1, call: toNumberFunc, result: "double"},
+		"coalesce": {minArgs: 1, maxArgs: -1, call: coalesce, typ: firstKnownType(0)},
+		"if":       {minArgs: 3, maxArgs: 3, call: ifFunc, typ: firstKnownType(1)},
+
+		"count": {minArgs: 1, maxArgs: 2, call: count, result: "long"},
+		"any":   {minArgs: 1, maxArgs: 2, call: anyFunc, result: "boolean"},
+		"all":   {minArgs: 1, maxArgs: 2, call: allFunc, result: "boolean"},
+		"sum":   {minArgs: 1, maxArgs: 2, call: sum, result: "double"},
+		"avg":   {minArgs: 1, maxArgs: 2, call: avg, result: "double"},
+		"min":   {minArgs: 1, maxArgs: 2, call: extreme(-1), typ: itemType},
+		"max":   {minArgs: 1, maxArgs: 2, call: extreme(1), typ: itemType},
+
+		"date_trunc": {minArgs: 2, maxArgs: 2, call: dateTrunc, result: "date"},
+	}
+}
+
+func evalArgs(s *scope, args []node) ([]any, error) {
+	values := make([]any, len(args))
+	for i, a := range args {
+		v, err := a.eval(s)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = v
+	}
+	return values, nil
+}
+
+func firstKnownType(from int) func([]node) string {
+	return func(args []node) string {
+		for _, a := range args[from:] {
+			if t := a.typ(); t != "" {
+				return t
+			}
+		}
+		return ""
+	}
+}
+
+// itemType returns the type of the item values of a list function: the type
+// of the expression evaluated per item, or else of the list itself.
+func itemType(args []node) string {
+	return args[len(args)-1].typ()
+}
+
+func concat(s *scope, args []node) (any, error) {
+	values, err := evalArgs(s, args)
+	if err != nil {
+		return nil, err
+	}
+	var sb strings.Builder
+	for _, v := range values {
+		sb.WriteString(toString(v))
+	}
+	return sb.String(), nil
+}
+
+func stringFunc(fn func(string) string) func(*scope, []node) (any, error) {
+	return func(s *scope, args []node) (any, error) {
+		v, err := args[0].eval(s)
+		if err != nil || v == nil {
+			return nil, err
+		}
+		str, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected a string, got %s", describe(v))
+		}
+		return fn(str), nil
+	}
+}
+
+func toStringFunc(s *scope, args []node) (any, error) {
+	v, err := args[0].eval(s)
+	if err != nil || v == nil {
+		return nil, err
+	}
+	if f, ok := v.(float64); ok {
+		return strconv.FormatFloat(f, 'f', -1, 64), nil
+	}
+	return toString(v), nil
+}
+
+func toNumberFunc(s *scope, args []node) (any, error) {
+	v, err := args[0].eval(s)
+	if err != nil {
+		return nil, err
+	}
+	switch v := v.(type) {
+	case float64:
+		return v, nil
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return nil, nil
+		}
+		return f, nil
+	case bool:
+		if v {
+			return 1.0, nil
+		}
+		return 0.0, nil
+	default:
+		return nil, nil
+	}
+}
+
+func coalesce(s *scope, args []node) (any, error) {
+	for _, a := range args {
+		v, err := a.eval(s)
+		if err != nil {
+			return nil, err
+		}
+		if v != nil {
+			return v, nil
+		}
+	}
+	return nil, nil
+}
+
+func ifFunc(s *scope, args []node) (any, error) {
+	cond, err := args[0].eval(s)
+	if err != nil {
+		return nil, err
+	}
+	if truthy(cond) {
+		return args[1].eval(s)
+	}
+	return args[2].eval(s)
+}
+
+// itemValues evaluates the list argument of an aggregate function. With a
+// second argument, that is evaluated once per item, with the item's fields in
+// scope, and its results are returned instead of the items.
+func itemValues(s *scope, args []node) ([]any, error) {
+	v, err := args[0].eval(s)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []any
+	switch v := v.(type) {
+	case nil:
+	case []any:
+		items = v
+	default:
+		items = []any{v}
+	}
+	if len(args) == 1 {
+		return items, nil
+	}
+
+	values := make([]any, len(items))
+	for i, item := range items {
+		vars, _ := item.(map[string]any)
+		values[i], err = args[1].eval(&scope{vars: vars, parent: s})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+func count(s *scope, args []node) (any, error) {
+	values, err := itemValues(s, args)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) == 1 {
+		return float64(len(values)), nil
+	}
+	var n float64
+	for _, v := range values {
+		if truthy(v) {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func anyFunc(s *scope, args []node) (any, error) {
+	values, err := itemValues(s, args)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range values {
+		if truthy(v) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func allFunc(s *scope, args []node) (any, error) {
+	values, err := itemValues(s, args)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range values {
+		if !truthy(v) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func numbers(values []any) ([]float64, error) {
+	nums := make([]float64, 0, len(values))
+	for _, v := range values {
+		switch v := v.(type) {
+		case nil:
+		case float64:
+			nums = append(nums, v)
+		default:
+			return nil, fmt.Errorf("expected numbers, got %s", describe(v))
+		}
+	}
+	return nums, nil
+}
+
+func sum(s *scope, args []node) (any, error) {
+	values, err := itemValues(s, args)
+	if err != nil {
+		return nil, err
+	}
+	nums, err := numbers(values)
+	if err != nil {
+		return nil, err
+	}
+	var total float64
+	for _, n := range nums {
+		total += n
+	}
+	return total, nil
+}
+
+func avg(s *scope, args []node) (any, error) {
+	values, err := itemValues(s, args)
+	if err != nil {
+		return nil, err
+	}
+	nums, err := numbers(values)
+	if err != nil || len(nums) == 0 {
+		return nil, err
+	}
+	var total float64
+	for _, n := range nums {
+		total += n
+	}
+	return total / float64(len(nums)), nil
+}
+
+// extreme returns min (sign -1) or max (sign 1) of numbers or strings, e.g.
+// dates. Nulls are ignored; the result of an empty list is null.
+func extreme(sign int) func(*scope, []node) (any, error) {
+	return func(s *scope, args []node) (any, error) {
+		values, err := itemValues(s, args)
+		if err != nil {
+			return nil, err
+		}
+		var best any
+		for _, v := range values {
+			if v == nil {
+				continue
+			}
+			if best == nil {
+				best = v
+				continue
+			}
+			c, _, err := compare(v, best)
+			if err != nil {
+				return nil, err
+			}
+			if c*sign > 0 {
+				best = v
+			}
+		}
+		return best, nil
+	}
+}
+
+// dateFormats are the formats accepted by date_trunc, besides epoch millis.
+var dateFormats = []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02"}
+
+func dateTrunc(s *scope, args []node) (any, error) {
+	values, err := evalArgs(s, args)
+	if err != nil {
+		return nil, err
+	}
+	unit, ok := values[0].(string)
+	if !ok {
+		return nil, fmt.Errorf("unit must be a string")
+	}
+
+	var t time.Time
+	switch v := values[1].(type) {
+	case nil:
+		return nil, nil
+	case float64:
+		t = time.UnixMilli(int64(v))
+	case string:
+		for _, layout := range dateFormats {
+			if t, err = time.Parse(layout, v); err == nil {
+				break
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid date %q", v)
+		}
+	default:
+		return nil, fmt.Errorf("expected a date, got %s", describe(v))
+	}
+	t = t.UTC()
+
+	switch unit {
+	case "year":
+		t = time.Date(t.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+	case "month":
+		t = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	case "week":
+		// Weeks start on Monday.
+		t = time.Date(t.Year(), t.Month(), t.Day()-(int(t.Weekday())+6)%7, 0, 0, 0, 0, time.UTC)
+	case "day":
+		t = t.Truncate(24 * time.Hour)
+	case "hour":
+		t = t.Truncate(time.Hour)
+	case "minute":
+		t = t.Truncate(time.Minute)
+	default:
+		return nil, fmt.Errorf("unknown unit %q, must be year, month, week, day, hour or minute", unit)
+	}
+
+	return t.Format(time.RFC3339), nil
+}
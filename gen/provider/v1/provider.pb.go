@@ -126,19 +126,130 @@ func (x *FetchResourceResponse) GetData() *structpb.Struct {
 	return nil
 }
 
-type FetchRelatedRequest struct {
+type FetchResourcesRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	ResourceType  string                 `protobuf:"bytes,1,opt,name=resource_type,json=resourceType,proto3" json:"resource_type,omitempty"`
-	Key           *ResourceKey           `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`
-	RootResource  *RootResource          `protobuf:"bytes,3,opt,name=root_resource,json=rootResource,proto3" json:"root_resource,omitempty"`
-	Metadata      map[string]string      `protobuf:"bytes,4,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	ResourceIds   []string               `protobuf:"bytes,2,rep,name=resource_ids,json=resourceIds,proto3" json:"resource_ids,omitempty"`
+	Metadata      map[string]string      `protobuf:"bytes,3,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FetchResourcesRequest) Reset() {
+	*x = FetchResourcesRequest{}
+	mi := &file_provider_v1_provider_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FetchResourcesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FetchResourcesRequest) ProtoMessage() {}
+
+func (x *FetchResourcesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_provider_v1_provider_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FetchResourcesRequest.ProtoReflect.Descriptor instead.
+func (*FetchResourcesRequest) Descriptor() ([]byte, []int) {
+	return file_provider_v1_provider_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *FetchResourcesRequest) GetResourceType() string {
+	if x != nil {
+		return x.ResourceType
+	}
+	return ""
+}
+
+func (x *FetchResourcesRequest) GetResourceIds() []string {
+	if x != nil {
+		return x.ResourceIds
+	}
+	return nil
+}
+
+func (x *FetchResourcesRequest) GetMetadata() map[string]string {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+type FetchResourcesResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The resources that exist, in any order. Resources that are left out are
+	// deleted from the index.
+	Resources     []*ResourceItem `protobuf:"bytes,1,rep,name=resources,proto3" json:"resources,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FetchResourcesResponse) Reset() {
+	*x = FetchResourcesResponse{}
+	mi := &file_provider_v1_provider_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FetchResourcesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FetchResourcesResponse) ProtoMessage() {}
+
+func (x *FetchResourcesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_provider_v1_provider_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FetchResourcesResponse.ProtoReflect.Descriptor instead.
+func (*FetchResourcesResponse) Descriptor() ([]byte, []int) {
+	return file_provider_v1_provider_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *FetchResourcesResponse) GetResources() []*ResourceItem {
+	if x != nil {
+		return x.Resources
+	}
+	return nil
+}
+
+type FetchRelatedRequest struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	ResourceType string                 `protobuf:"bytes,1,opt,name=resource_type,json=resourceType,proto3" json:"resource_type,omitempty"`
+	Key          *ResourceKey           `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`
+	RootResource *RootResource          `protobuf:"bytes,3,opt,name=root_resource,json=rootResource,proto3" json:"root_resource,omitempty"`
+	Metadata     map[string]string      `protobuf:"bytes,4,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// Which of the related resources the indexer embeds. The indexer applies
+	// the hints itself after fetching, so providers are free to ignore them.
+	// Providers that use them to return fewer resources should note that
+	// changes to the resources they leave out are not tracked.
+	Hints         *RelationHints `protobuf:"bytes,5,opt,name=hints,proto3" json:"hints,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *FetchRelatedRequest) Reset() {
 	*x = FetchRelatedRequest{}
-	mi := &file_provider_v1_provider_proto_msgTypes[2]
+	mi := &file_provider_v1_provider_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -150,7 +261,7 @@ func (x *FetchRelatedRequest) String() string {
 func (*FetchRelatedRequest) ProtoMessage() {}
 
 func (x *FetchRelatedRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_provider_v1_provider_proto_msgTypes[2]
+	mi := &file_provider_v1_provider_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -163,7 +274,7 @@ func (x *FetchRelatedRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use FetchRelatedRequest.ProtoReflect.Descriptor instead.
 func (*FetchRelatedRequest) Descriptor() ([]byte, []int) {
-	return file_provider_v1_provider_proto_rawDescGZIP(), []int{2}
+	return file_provider_v1_provider_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *FetchRelatedRequest) GetResourceType() string {
@@ -194,17 +305,149 @@ func (x *FetchRelatedRequest) GetMetadata() map[string]string {
 	return nil
 }
 
+func (x *FetchRelatedRequest) GetHints() *RelationHints {
+	if x != nil {
+		return x.Hints
+	}
+	return nil
+}
+
+type RelationHints struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// An expression in the indexer's expression language, e.g.
+	// `status == "open"`. Only resources it is true for are embedded.
+	Where string `protobuf:"bytes,1,opt,name=where,proto3" json:"where,omitempty"`
+	// The order of the resources, by the first entry and then by the next.
+	OrderBy []*OrderBy `protobuf:"bytes,2,rep,name=order_by,json=orderBy,proto3" json:"order_by,omitempty"`
+	// The maximum number of resources embedded, 0 for no limit.
+	Limit         int32 `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RelationHints) Reset() {
+	*x = RelationHints{}
+	mi := &file_provider_v1_provider_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RelationHints) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RelationHints) ProtoMessage() {}
+
+func (x *RelationHints) ProtoReflect() protoreflect.Message {
+	mi := &file_provider_v1_provider_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RelationHints.ProtoReflect.Descriptor instead.
+func (*RelationHints) Descriptor() ([]byte, []int) {
+	return file_provider_v1_provider_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *RelationHints) GetWhere() string {
+	if x != nil {
+		return x.Where
+	}
+	return ""
+}
+
+func (x *RelationHints) GetOrderBy() []*OrderBy {
+	if x != nil {
+		return x.OrderBy
+	}
+	return nil
+}
+
+func (x *RelationHints) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type OrderBy struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The path of the field, e.g. "created_at" or "address.city".
+	Field         string `protobuf:"bytes,1,opt,name=field,proto3" json:"field,omitempty"`
+	Descending    bool   `protobuf:"varint,2,opt,name=descending,proto3" json:"descending,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OrderBy) Reset() {
+	*x = OrderBy{}
+	mi := &file_provider_v1_provider_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrderBy) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrderBy) ProtoMessage() {}
+
+func (x *OrderBy) ProtoReflect() protoreflect.Message {
+	mi := &file_provider_v1_provider_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrderBy.ProtoReflect.Descriptor instead.
+func (*OrderBy) Descriptor() ([]byte, []int) {
+	return file_provider_v1_provider_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *OrderBy) GetField() string {
+	if x != nil {
+		return x.Field
+	}
+	return ""
+}
+
+func (x *OrderBy) GetDescending() bool {
+	if x != nil {
+		return x.Descending
+	}
+	return false
+}
+
+// ResourceKey identifies the related resources to fetch by one or more key
+// parts. All parts must match.
 type ResourceKey struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Field         string                 `protobuf:"bytes,1,opt,name=field,proto3" json:"field,omitempty"`
-	Value         string                 `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The first key part, with its value formatted as a string. Only set when
+	// that value is a scalar. Kept for providers that only support single-field
+	// string keys; use parts instead.
+	Field string `protobuf:"bytes,1,opt,name=field,proto3" json:"field,omitempty"`
+	Value string `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	// The key parts in the order they are configured. Composite keys, such as
+	// tenant_id and sku, have several parts.
+	Parts         []*KeyPart `protobuf:"bytes,3,rep,name=parts,proto3" json:"parts,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *ResourceKey) Reset() {
 	*x = ResourceKey{}
-	mi := &file_provider_v1_provider_proto_msgTypes[3]
+	mi := &file_provider_v1_provider_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -216,7 +459,7 @@ func (x *ResourceKey) String() string {
 func (*ResourceKey) ProtoMessage() {}
 
 func (x *ResourceKey) ProtoReflect() protoreflect.Message {
-	mi := &file_provider_v1_provider_proto_msgTypes[3]
+	mi := &file_provider_v1_provider_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -229,7 +472,7 @@ func (x *ResourceKey) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ResourceKey.ProtoReflect.Descriptor instead.
 func (*ResourceKey) Descriptor() ([]byte, []int) {
-	return file_provider_v1_provider_proto_rawDescGZIP(), []int{3}
+	return file_provider_v1_provider_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *ResourceKey) GetField() string {
@@ -246,6 +489,67 @@ func (x *ResourceKey) GetValue() string {
 	return ""
 }
 
+func (x *ResourceKey) GetParts() []*KeyPart {
+	if x != nil {
+		return x.Parts
+	}
+	return nil
+}
+
+type KeyPart struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Field string                 `protobuf:"bytes,1,opt,name=field,proto3" json:"field,omitempty"`
+	// A string, number or bool. Batched lookups of array-valued keys pass a
+	// list of them, matching any element.
+	Value         *structpb.Value `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *KeyPart) Reset() {
+	*x = KeyPart{}
+	mi := &file_provider_v1_provider_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *KeyPart) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*KeyPart) ProtoMessage() {}
+
+func (x *KeyPart) ProtoReflect() protoreflect.Message {
+	mi := &file_provider_v1_provider_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use KeyPart.ProtoReflect.Descriptor instead.
+func (*KeyPart) Descriptor() ([]byte, []int) {
+	return file_provider_v1_provider_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *KeyPart) GetField() string {
+	if x != nil {
+		return x.Field
+	}
+	return ""
+}
+
+func (x *KeyPart) GetValue() *structpb.Value {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
 type RootResource struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Type          string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
@@ -256,7 +560,7 @@ type RootResource struct {
 
 func (x *RootResource) Reset() {
 	*x = RootResource{}
-	mi := &file_provider_v1_provider_proto_msgTypes[4]
+	mi := &file_provider_v1_provider_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -268,7 +572,7 @@ func (x *RootResource) String() string {
 func (*RootResource) ProtoMessage() {}
 
 func (x *RootResource) ProtoReflect() protoreflect.Message {
-	mi := &file_provider_v1_provider_proto_msgTypes[4]
+	mi := &file_provider_v1_provider_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -281,7 +585,7 @@ func (x *RootResource) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RootResource.ProtoReflect.Descriptor instead.
 func (*RootResource) Descriptor() ([]byte, []int) {
-	return file_provider_v1_provider_proto_rawDescGZIP(), []int{4}
+	return file_provider_v1_provider_proto_rawDescGZIP(), []int{9}
 }
 
 func (x *RootResource) GetType() string {
@@ -307,7 +611,7 @@ type FetchRelatedResponse struct {
 
 func (x *FetchRelatedResponse) Reset() {
 	*x = FetchRelatedResponse{}
-	mi := &file_provider_v1_provider_proto_msgTypes[5]
+	mi := &file_provider_v1_provider_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -319,7 +623,7 @@ func (x *FetchRelatedResponse) String() string {
 func (*FetchRelatedResponse) ProtoMessage() {}
 
 func (x *FetchRelatedResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_provider_v1_provider_proto_msgTypes[5]
+	mi := &file_provider_v1_provider_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -332,7 +636,7 @@ func (x *FetchRelatedResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use FetchRelatedResponse.ProtoReflect.Descriptor instead.
 func (*FetchRelatedResponse) Descriptor() ([]byte, []int) {
-	return file_provider_v1_provider_proto_rawDescGZIP(), []int{5}
+	return file_provider_v1_provider_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *FetchRelatedResponse) GetData() []*structpb.Struct {
@@ -342,6 +646,218 @@ func (x *FetchRelatedResponse) GetData() []*structpb.Struct {
 	return nil
 }
 
+type FetchRelatedBatchRequest struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	ResourceType string                 `protobuf:"bytes,1,opt,name=resource_type,json=resourceType,proto3" json:"resource_type,omitempty"`
+	Lookups      []*RelatedLookup       `protobuf:"bytes,2,rep,name=lookups,proto3" json:"lookups,omitempty"`
+	Metadata     map[string]string      `protobuf:"bytes,3,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// The hints of the relation, as in FetchRelatedRequest.
+	Hints         *RelationHints `protobuf:"bytes,4,opt,name=hints,proto3" json:"hints,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FetchRelatedBatchRequest) Reset() {
+	*x = FetchRelatedBatchRequest{}
+	mi := &file_provider_v1_provider_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FetchRelatedBatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FetchRelatedBatchRequest) ProtoMessage() {}
+
+func (x *FetchRelatedBatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_provider_v1_provider_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FetchRelatedBatchRequest.ProtoReflect.Descriptor instead.
+func (*FetchRelatedBatchRequest) Descriptor() ([]byte, []int) {
+	return file_provider_v1_provider_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *FetchRelatedBatchRequest) GetResourceType() string {
+	if x != nil {
+		return x.ResourceType
+	}
+	return ""
+}
+
+func (x *FetchRelatedBatchRequest) GetLookups() []*RelatedLookup {
+	if x != nil {
+		return x.Lookups
+	}
+	return nil
+}
+
+func (x *FetchRelatedBatchRequest) GetMetadata() map[string]string {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *FetchRelatedBatchRequest) GetHints() *RelationHints {
+	if x != nil {
+		return x.Hints
+	}
+	return nil
+}
+
+// RelatedLookup is a single key of a FetchRelatedBatchRequest, with the
+// resource it was extracted for.
+type RelatedLookup struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           *ResourceKey           `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	RootResource  *RootResource          `protobuf:"bytes,2,opt,name=root_resource,json=rootResource,proto3" json:"root_resource,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RelatedLookup) Reset() {
+	*x = RelatedLookup{}
+	mi := &file_provider_v1_provider_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RelatedLookup) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RelatedLookup) ProtoMessage() {}
+
+func (x *RelatedLookup) ProtoReflect() protoreflect.Message {
+	mi := &file_provider_v1_provider_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RelatedLookup.ProtoReflect.Descriptor instead.
+func (*RelatedLookup) Descriptor() ([]byte, []int) {
+	return file_provider_v1_provider_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *RelatedLookup) GetKey() *ResourceKey {
+	if x != nil {
+		return x.Key
+	}
+	return nil
+}
+
+func (x *RelatedLookup) GetRootResource() *RootResource {
+	if x != nil {
+		return x.RootResource
+	}
+	return nil
+}
+
+type FetchRelatedBatchResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The related resources of each lookup, in the order of the lookups.
+	Results       []*RelatedResources `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FetchRelatedBatchResponse) Reset() {
+	*x = FetchRelatedBatchResponse{}
+	mi := &file_provider_v1_provider_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FetchRelatedBatchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FetchRelatedBatchResponse) ProtoMessage() {}
+
+func (x *FetchRelatedBatchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_provider_v1_provider_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FetchRelatedBatchResponse.ProtoReflect.Descriptor instead.
+func (*FetchRelatedBatchResponse) Descriptor() ([]byte, []int) {
+	return file_provider_v1_provider_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *FetchRelatedBatchResponse) GetResults() []*RelatedResources {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type RelatedResources struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Data          []*structpb.Struct     `protobuf:"bytes,1,rep,name=data,proto3" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RelatedResources) Reset() {
+	*x = RelatedResources{}
+	mi := &file_provider_v1_provider_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RelatedResources) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RelatedResources) ProtoMessage() {}
+
+func (x *RelatedResources) ProtoReflect() protoreflect.Message {
+	mi := &file_provider_v1_provider_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RelatedResources.ProtoReflect.Descriptor instead.
+func (*RelatedResources) Descriptor() ([]byte, []int) {
+	return file_provider_v1_provider_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *RelatedResources) GetData() []*structpb.Struct {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
 type ListResourcesRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	ResourceType  string                 `protobuf:"bytes,1,opt,name=resource_type,json=resourceType,proto3" json:"resource_type,omitempty"`
@@ -354,7 +870,7 @@ type ListResourcesRequest struct {
 
 func (x *ListResourcesRequest) Reset() {
 	*x = ListResourcesRequest{}
-	mi := &file_provider_v1_provider_proto_msgTypes[6]
+	mi := &file_provider_v1_provider_proto_msgTypes[15]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -366,7 +882,7 @@ func (x *ListResourcesRequest) String() string {
 func (*ListResourcesRequest) ProtoMessage() {}
 
 func (x *ListResourcesRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_provider_v1_provider_proto_msgTypes[6]
+	mi := &file_provider_v1_provider_proto_msgTypes[15]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -379,7 +895,7 @@ func (x *ListResourcesRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListResourcesRequest.ProtoReflect.Descriptor instead.
 func (*ListResourcesRequest) Descriptor() ([]byte, []int) {
-	return file_provider_v1_provider_proto_rawDescGZIP(), []int{6}
+	return file_provider_v1_provider_proto_rawDescGZIP(), []int{15}
 }
 
 func (x *ListResourcesRequest) GetResourceType() string {
@@ -420,7 +936,7 @@ type ListResourcesResponse struct {
 
 func (x *ListResourcesResponse) Reset() {
 	*x = ListResourcesResponse{}
-	mi := &file_provider_v1_provider_proto_msgTypes[7]
+	mi := &file_provider_v1_provider_proto_msgTypes[16]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -432,7 +948,7 @@ func (x *ListResourcesResponse) String() string {
 func (*ListResourcesResponse) ProtoMessage() {}
 
 func (x *ListResourcesResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_provider_v1_provider_proto_msgTypes[7]
+	mi := &file_provider_v1_provider_proto_msgTypes[16]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -445,7 +961,7 @@ func (x *ListResourcesResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListResourcesResponse.ProtoReflect.Descriptor instead.
 func (*ListResourcesResponse) Descriptor() ([]byte, []int) {
-	return file_provider_v1_provider_proto_rawDescGZIP(), []int{7}
+	return file_provider_v1_provider_proto_rawDescGZIP(), []int{16}
 }
 
 func (x *ListResourcesResponse) GetResources() []*ResourceItem {
@@ -472,7 +988,7 @@ type ResourceItem struct {
 
 func (x *ResourceItem) Reset() {
 	*x = ResourceItem{}
-	mi := &file_provider_v1_provider_proto_msgTypes[8]
+	mi := &file_provider_v1_provider_proto_msgTypes[17]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -484,7 +1000,7 @@ func (x *ResourceItem) String() string {
 func (*ResourceItem) ProtoMessage() {}
 
 func (x *ResourceItem) ProtoReflect() protoreflect.Message {
-	mi := &file_provider_v1_provider_proto_msgTypes[8]
+	mi := &file_provider_v1_provider_proto_msgTypes[17]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -497,7 +1013,7 @@ func (x *ResourceItem) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ResourceItem.ProtoReflect.Descriptor instead.
 func (*ResourceItem) Descriptor() ([]byte, []int) {
-	return file_provider_v1_provider_proto_rawDescGZIP(), []int{8}
+	return file_provider_v1_provider_proto_rawDescGZIP(), []int{17}
 }
 
 func (x *ResourceItem) GetResourceId() string {
@@ -528,22 +1044,60 @@ const file_provider_v1_provider_proto_rawDesc = "" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
 	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"D\n" +
 	"\x15FetchResourceResponse\x12+\n" +
-	"\x04data\x18\x01 \x01(\v2\x17.google.protobuf.StructR\x04data\"\xaf\x02\n" +
+	"\x04data\x18\x01 \x01(\v2\x17.google.protobuf.StructR\x04data\"\xea\x01\n" +
+	"\x15FetchResourcesRequest\x12#\n" +
+	"\rresource_type\x18\x01 \x01(\tR\fresourceType\x12!\n" +
+	"\fresource_ids\x18\x02 \x03(\tR\vresourceIds\x12L\n" +
+	"\bmetadata\x18\x03 \x03(\v20.provider.v1.FetchResourcesRequest.MetadataEntryR\bmetadata\x1a;\n" +
+	"\rMetadataEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"Q\n" +
+	"\x16FetchResourcesResponse\x127\n" +
+	"\tresources\x18\x01 \x03(\v2\x19.provider.v1.ResourceItemR\tresources\"\xe1\x02\n" +
 	"\x13FetchRelatedRequest\x12#\n" +
 	"\rresource_type\x18\x01 \x01(\tR\fresourceType\x12*\n" +
 	"\x03key\x18\x02 \x01(\v2\x18.provider.v1.ResourceKeyR\x03key\x12>\n" +
 	"\rroot_resource\x18\x03 \x01(\v2\x19.provider.v1.RootResourceR\frootResource\x12J\n" +
-	"\bmetadata\x18\x04 \x03(\v2..provider.v1.FetchRelatedRequest.MetadataEntryR\bmetadata\x1a;\n" +
+	"\bmetadata\x18\x04 \x03(\v2..provider.v1.FetchRelatedRequest.MetadataEntryR\bmetadata\x120\n" +
+	"\x05hints\x18\x05 \x01(\v2\x1a.provider.v1.RelationHintsR\x05hints\x1a;\n" +
 	"\rMetadataEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"9\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"l\n" +
+	"\rRelationHints\x12\x14\n" +
+	"\x05where\x18\x01 \x01(\tR\x05where\x12/\n" +
+	"\border_by\x18\x02 \x03(\v2\x14.provider.v1.OrderByR\aorderBy\x12\x14\n" +
+	"\x05limit\x18\x03 \x01(\x05R\x05limit\"?\n" +
+	"\aOrderBy\x12\x14\n" +
+	"\x05field\x18\x01 \x01(\tR\x05field\x12\x1e\n" +
+	"\n" +
+	"descending\x18\x02 \x01(\bR\n" +
+	"descending\"e\n" +
 	"\vResourceKey\x12\x14\n" +
 	"\x05field\x18\x01 \x01(\tR\x05field\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value\"2\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value\x12*\n" +
+	"\x05parts\x18\x03 \x03(\v2\x14.provider.v1.KeyPartR\x05parts\"M\n" +
+	"\aKeyPart\x12\x14\n" +
+	"\x05field\x18\x01 \x01(\tR\x05field\x12,\n" +
+	"\x05value\x18\x02 \x01(\v2\x16.google.protobuf.ValueR\x05value\"2\n" +
 	"\fRootResource\x12\x12\n" +
 	"\x04type\x18\x01 \x01(\tR\x04type\x12\x0e\n" +
 	"\x02id\x18\x02 \x01(\tR\x02id\"C\n" +
 	"\x14FetchRelatedResponse\x12+\n" +
+	"\x04data\x18\x01 \x03(\v2\x17.google.protobuf.StructR\x04data\"\xb5\x02\n" +
+	"\x18FetchRelatedBatchRequest\x12#\n" +
+	"\rresource_type\x18\x01 \x01(\tR\fresourceType\x124\n" +
+	"\alookups\x18\x02 \x03(\v2\x1a.provider.v1.RelatedLookupR\alookups\x12O\n" +
+	"\bmetadata\x18\x03 \x03(\v23.provider.v1.FetchRelatedBatchRequest.MetadataEntryR\bmetadata\x120\n" +
+	"\x05hints\x18\x04 \x01(\v2\x1a.provider.v1.RelationHintsR\x05hints\x1a;\n" +
+	"\rMetadataEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"{\n" +
+	"\rRelatedLookup\x12*\n" +
+	"\x03key\x18\x01 \x01(\v2\x18.provider.v1.ResourceKeyR\x03key\x12>\n" +
+	"\rroot_resource\x18\x02 \x01(\v2\x19.provider.v1.RootResourceR\frootResource\"T\n" +
+	"\x19FetchRelatedBatchResponse\x127\n" +
+	"\aresults\x18\x01 \x03(\v2\x1d.provider.v1.RelatedResourcesR\aresults\"?\n" +
+	"\x10RelatedResources\x12+\n" +
 	"\x04data\x18\x01 \x03(\v2\x17.google.protobuf.StructR\x04data\"\x81\x02\n" +
 	"\x14ListResourcesRequest\x12#\n" +
 	"\rresource_type\x18\x01 \x01(\tR\fresourceType\x12\x1d\n" +
@@ -560,10 +1114,12 @@ const file_provider_v1_provider_proto_rawDesc = "" +
 	"\fResourceItem\x12\x1f\n" +
 	"\vresource_id\x18\x01 \x01(\tR\n" +
 	"resourceId\x12+\n" +
-	"\x04data\x18\x02 \x01(\v2\x17.google.protobuf.StructR\x04data2\x96\x02\n" +
+	"\x04data\x18\x02 \x01(\v2\x17.google.protobuf.StructR\x04data2\xd5\x03\n" +
 	"\x0fProviderService\x12V\n" +
-	"\rFetchResource\x12!.provider.v1.FetchResourceRequest\x1a\".provider.v1.FetchResourceResponse\x12S\n" +
-	"\fFetchRelated\x12 .provider.v1.FetchRelatedRequest\x1a!.provider.v1.FetchRelatedResponse\x12V\n" +
+	"\rFetchResource\x12!.provider.v1.FetchResourceRequest\x1a\".provider.v1.FetchResourceResponse\x12Y\n" +
+	"\x0eFetchResources\x12\".provider.v1.FetchResourcesRequest\x1a#.provider.v1.FetchResourcesResponse\x12S\n" +
+	"\fFetchRelated\x12 .provider.v1.FetchRelatedRequest\x1a!.provider.v1.FetchRelatedResponse\x12b\n" +
+	"\x11FetchRelatedBatch\x12%.provider.v1.FetchRelatedBatchRequest\x1a&.provider.v1.FetchRelatedBatchResponse\x12V\n" +
 	"\rListResources\x12!.provider.v1.ListResourcesRequest\x1a\".provider.v1.ListResourcesResponseB\x8f\x01\n" +
 	"\x0fcom.provider.v1B\rProviderProtoP\x01Z indexer/gen/provider/v1;provider\xa2\x02\x03PXX\xaa\x02\vProvider.V1\xca\x02\vProvider\\V1\xe2\x02\x17Provider\\V1\\GPBMetadata\xea\x02\fProvider::V1b\x06proto3"
 
@@ -579,43 +1135,72 @@ func file_provider_v1_provider_proto_rawDescGZIP() []byte {
 	return file_provider_v1_provider_proto_rawDescData
 }
 
-var file_provider_v1_provider_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_provider_v1_provider_proto_msgTypes = make([]protoimpl.MessageInfo, 23)
 var file_provider_v1_provider_proto_goTypes = []any{
-	(*FetchResourceRequest)(nil),  // 0: provider.v1.FetchResourceRequest
-	(*FetchResourceResponse)(nil), // 1: provider.v1.FetchResourceResponse
-	(*FetchRelatedRequest)(nil),   // 2: provider.v1.FetchRelatedRequest
-	(*ResourceKey)(nil),           // 3: provider.v1.ResourceKey
-	(*RootResource)(nil),          // 4: provider.v1.RootResource
-	(*FetchRelatedResponse)(nil),  // 5: provider.v1.FetchRelatedResponse
-	(*ListResourcesRequest)(nil),  // 6: provider.v1.ListResourcesRequest
-	(*ListResourcesResponse)(nil), // 7: provider.v1.ListResourcesResponse
-	(*ResourceItem)(nil),          // 8: provider.v1.ResourceItem
-	nil,                           // 9: provider.v1.FetchResourceRequest.MetadataEntry
-	nil,                           // 10: provider.v1.FetchRelatedRequest.MetadataEntry
-	nil,                           // 11: provider.v1.ListResourcesRequest.MetadataEntry
-	(*structpb.Struct)(nil),       // 12: google.protobuf.Struct
+	(*FetchResourceRequest)(nil),      // 0: provider.v1.FetchResourceRequest
+	(*FetchResourceResponse)(nil),     // 1: provider.v1.FetchResourceResponse
+	(*FetchResourcesRequest)(nil),     // 2: provider.v1.FetchResourcesRequest
+	(*FetchResourcesResponse)(nil),    // 3: provider.v1.FetchResourcesResponse
+	(*FetchRelatedRequest)(nil),       // 4: provider.v1.FetchRelatedRequest
+	(*RelationHints)(nil),             // 5: provider.v1.RelationHints
+	(*OrderBy)(nil),                   // 6: provider.v1.OrderBy
+	(*ResourceKey)(nil),               // 7: provider.v1.ResourceKey
+	(*KeyPart)(nil),                   // 8: provider.v1.KeyPart
+	(*RootResource)(nil),              // 9: provider.v1.RootResource
+	(*FetchRelatedResponse)(nil),      // 10: provider.v1.FetchRelatedResponse
+	(*FetchRelatedBatchRequest)(nil),  // 11: provider.v1.FetchRelatedBatchRequest
+	(*RelatedLookup)(nil),             // 12: provider.v1.RelatedLookup
+	(*FetchRelatedBatchResponse)(nil), // 13: provider.v1.FetchRelatedBatchResponse
+	(*RelatedResources)(nil),          // 14: provider.v1.RelatedResources
+	(*ListResourcesRequest)(nil),      // 15: provider.v1.ListResourcesRequest
+	(*ListResourcesResponse)(nil),     // 16: provider.v1.ListResourcesResponse
+	(*ResourceItem)(nil),              // 17: provider.v1.ResourceItem
+	nil,                               // 18: provider.v1.FetchResourceRequest.MetadataEntry
+	nil,                               // 19: provider.v1.FetchResourcesRequest.MetadataEntry
+	nil,                               // 20: provider.v1.FetchRelatedRequest.MetadataEntry
+	nil,                               // 21: provider.v1.FetchRelatedBatchRequest.MetadataEntry
+	nil,                               // 22: provider.v1.ListResourcesRequest.MetadataEntry
+	(*structpb.Struct)(nil),           // 23: google.protobuf.Struct
+	(*structpb.Value)(nil),            // 24: google.protobuf.Value
 }
 var file_provider_v1_provider_proto_depIdxs = []int32{
-	9,  // 0: provider.v1.FetchResourceRequest.metadata:type_name -> provider.v1.FetchResourceRequest.MetadataEntry
-	12, // 1: provider.v1.FetchResourceResponse.data:type_name -> google.protobuf.Struct
-	3,  // 2: provider.v1.FetchRelatedRequest.key:type_name -> provider.v1.ResourceKey
-	4,  // 3: provider.v1.FetchRelatedRequest.root_resource:type_name -> provider.v1.RootResource
-	10, // 4: provider.v1.FetchRelatedRequest.metadata:type_name -> provider.v1.FetchRelatedRequest.MetadataEntry
-	12, // 5: provider.v1.FetchRelatedResponse.data:type_name -> google.protobuf.Struct
-	11, // 6: provider.v1.ListResourcesRequest.metadata:type_name -> provider.v1.ListResourcesRequest.MetadataEntry
-	8,  // 7: provider.v1.ListResourcesResponse.resources:type_name -> provider.v1.ResourceItem
-	12, // 8: provider.v1.ResourceItem.data:type_name -> google.protobuf.Struct
-	0,  // 9: provider.v1.ProviderService.FetchResource:input_type -> provider.v1.FetchResourceRequest
-	2,  // 10: provider.v1.ProviderService.FetchRelated:input_type -> provider.v1.FetchRelatedRequest
-	6,  // 11: provider.v1.ProviderService.ListResources:input_type -> provider.v1.ListResourcesRequest
-	1,  // 12: provider.v1.ProviderService.FetchResource:output_type -> provider.v1.FetchResourceResponse
-	5,  // 13: provider.v1.ProviderService.FetchRelated:output_type -> provider.v1.FetchRelatedResponse
-	7,  // 14: provider.v1.ProviderService.ListResources:output_type -> provider.v1.ListResourcesResponse
-	12, // [12:15] is the sub-list for method output_type
-	9,  // [9:12] is the sub-list for method input_type
-	9,  // [9:9] is the sub-list for extension type_name
-	9,  // [9:9] is the sub-list for extension extendee
-	0,  // [0:9] is the sub-list for field type_name
+	18, // 0: provider.v1.FetchResourceRequest.metadata:type_name -> provider.v1.FetchResourceRequest.MetadataEntry
+	23, // 1: provider.v1.FetchResourceResponse.data:type_name -> google.protobuf.Struct
+	19, // 2: provider.v1.FetchResourcesRequest.metadata:type_name -> provider.v1.FetchResourcesRequest.MetadataEntry
+	17, // 3: provider.v1.FetchResourcesResponse.resources:type_name -> provider.v1.ResourceItem
+	7,  // 4: provider.v1.FetchRelatedRequest.key:type_name -> provider.v1.ResourceKey
+	9,  // 5: provider.v1.FetchRelatedRequest.root_resource:type_name -> provider.v1.RootResource
+	20, // 6: provider.v1.FetchRelatedRequest.metadata:type_name -> provider.v1.FetchRelatedRequest.MetadataEntry
+	5,  // 7: provider.v1.FetchRelatedRequest.hints:type_name -> provider.v1.RelationHints
+	6,  // 8: provider.v1.RelationHints.order_by:type_name -> provider.v1.OrderBy
+	8,  // 9: provider.v1.ResourceKey.parts:type_name -> provider.v1.KeyPart
+	24, // 10: provider.v1.KeyPart.value:type_name -> google.protobuf.Value
+	23, // 11: provider.v1.FetchRelatedResponse.data:type_name -> google.protobuf.Struct
+	12, // 12: provider.v1.FetchRelatedBatchRequest.lookups:type_name -> provider.v1.RelatedLookup
+	21, // 13: provider.v1.FetchRelatedBatchRequest.metadata:type_name -> provider.v1.FetchRelatedBatchRequest.MetadataEntry
+	5,  // 14: provider.v1.FetchRelatedBatchRequest.hints:type_name -> provider.v1.RelationHints
+	7,  // 15: provider.v1.RelatedLookup.key:type_name -> provider.v1.ResourceKey
+	9,  // 16: provider.v1.RelatedLookup.root_resource:type_name -> provider.v1.RootResource
+	14, // 17: provider.v1.FetchRelatedBatchResponse.results:type_name -> provider.v1.RelatedResources
+	23, // 18: provider.v1.RelatedResources.data:type_name -> google.protobuf.Struct
+	22, // 19: provider.v1.ListResourcesRequest.metadata:type_name -> provider.v1.ListResourcesRequest.MetadataEntry
+	17, // 20: provider.v1.ListResourcesResponse.resources:type_name -> provider.v1.ResourceItem
+	23, // 21: provider.v1.ResourceItem.data:type_name -> google.protobuf.Struct
+	0,  // 22: provider.v1.ProviderService.FetchResource:input_type -> provider.v1.FetchResourceRequest
+	2,  // 23: provider.v1.ProviderService.FetchResources:input_type -> provider.v1.FetchResourcesRequest
+	4,  // 24: provider.v1.ProviderService.FetchRelated:input_type -> provider.v1.FetchRelatedRequest
+	11, // 25: provider.v1.ProviderService.FetchRelatedBatch:input_type -> provider.v1.FetchRelatedBatchRequest
+	15, // 26: provider.v1.ProviderService.ListResources:input_type -> provider.v1.ListResourcesRequest
+	1,  // 27: provider.v1.ProviderService.FetchResource:output_type -> provider.v1.FetchResourceResponse
+	3,  // 28: provider.v1.ProviderService.FetchResources:output_type -> provider.v1.FetchResourcesResponse
+	10, // 29: provider.v1.ProviderService.FetchRelated:output_type -> provider.v1.FetchRelatedResponse
+	13, // 30: provider.v1.ProviderService.FetchRelatedBatch:output_type -> provider.v1.FetchRelatedBatchResponse
+	16, // 31: provider.v1.ProviderService.ListResources:output_type -> provider.v1.ListResourcesResponse
+	27, // [27:32] is the sub-list for method output_type
+	22, // [22:27] is the sub-list for method input_type
+	22, // [22:22] is the sub-list for extension type_name
+	22, // [22:22] is the sub-list for extension extendee
+	0,  // [0:22] is the sub-list for field type_name
 }
 
 func init() { file_provider_v1_provider_proto_init() }
@@ -629,7 +1214,7 @@ func file_provider_v1_provider_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_provider_v1_provider_proto_rawDesc), len(file_provider_v1_provider_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   12,
+			NumMessages:   23,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
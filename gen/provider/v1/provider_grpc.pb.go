@@ -19,9 +19,11 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	ProviderService_FetchResource_FullMethodName = "/provider.v1.ProviderService/FetchResource"
-	ProviderService_FetchRelated_FullMethodName  = "/provider.v1.ProviderService/FetchRelated"
-	ProviderService_ListResources_FullMethodName = "/provider.v1.ProviderService/ListResources"
+	ProviderService_FetchResource_FullMethodName     = "/provider.v1.ProviderService/FetchResource"
+	ProviderService_FetchResources_FullMethodName    = "/provider.v1.ProviderService/FetchResources"
+	ProviderService_FetchRelated_FullMethodName      = "/provider.v1.ProviderService/FetchRelated"
+	ProviderService_FetchRelatedBatch_FullMethodName = "/provider.v1.ProviderService/FetchRelatedBatch"
+	ProviderService_ListResources_FullMethodName     = "/provider.v1.ProviderService/ListResources"
 )
 
 // ProviderServiceClient is the client API for ProviderService service.
@@ -34,9 +36,17 @@ type ProviderServiceClient interface {
 	// FetchResource returns the data for a single resource identified by type and
 	// ID.
 	FetchResource(ctx context.Context, in *FetchResourceRequest, opts ...grpc.CallOption) (*FetchResourceResponse, error)
+	// FetchResources returns the data of many resources of a type in one call.
+	// Optional: the indexer falls back to one FetchResource call per ID when it
+	// is unimplemented.
+	FetchResources(ctx context.Context, in *FetchResourcesRequest, opts ...grpc.CallOption) (*FetchResourcesResponse, error)
 	// FetchRelated returns a list of resources related to the given resource type
 	// and key.
 	FetchRelated(ctx context.Context, in *FetchRelatedRequest, opts ...grpc.CallOption) (*FetchRelatedResponse, error)
+	// FetchRelatedBatch returns the related resources of many keys in one call,
+	// grouped by key. Optional: the indexer falls back to one FetchRelated call
+	// per key when it is unimplemented.
+	FetchRelatedBatch(ctx context.Context, in *FetchRelatedBatchRequest, opts ...grpc.CallOption) (*FetchRelatedBatchResponse, error)
 	// ListResources returns a paginated list of all resources of a given type.
 	// Used for full index rebuilds.
 	ListResources(ctx context.Context, in *ListResourcesRequest, opts ...grpc.CallOption) (*ListResourcesResponse, error)
@@ -60,6 +70,16 @@ func (c *providerServiceClient) FetchResource(ctx context.Context, in *FetchReso
 	return out, nil
 }
 
+func (c *providerServiceClient) FetchResources(ctx context.Context, in *FetchResourcesRequest, opts ...grpc.CallOption) (*FetchResourcesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FetchResourcesResponse)
+	err := c.cc.Invoke(ctx, ProviderService_FetchResources_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *providerServiceClient) FetchRelated(ctx context.Context, in *FetchRelatedRequest, opts ...grpc.CallOption) (*FetchRelatedResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(FetchRelatedResponse)
@@ -70,6 +90,16 @@ func (c *providerServiceClient) FetchRelated(ctx context.Context, in *FetchRelat
 	return out, nil
 }
 
+func (c *providerServiceClient) FetchRelatedBatch(ctx context.Context, in *FetchRelatedBatchRequest, opts ...grpc.CallOption) (*FetchRelatedBatchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FetchRelatedBatchResponse)
+	err := c.cc.Invoke(ctx, ProviderService_FetchRelatedBatch_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *providerServiceClient) ListResources(ctx context.Context, in *ListResourcesRequest, opts ...grpc.CallOption) (*ListResourcesResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(ListResourcesResponse)
@@ -90,9 +120,17 @@ type ProviderServiceServer interface {
 	// FetchResource returns the data for a single resource identified by type and
 	// ID.
 	FetchResource(context.Context, *FetchResourceRequest) (*FetchResourceResponse, error)
+	// FetchResources returns the data of many resources of a type in one call.
+	// Optional: the indexer falls back to one FetchResource call per ID when it
+	// is unimplemented.
+	FetchResources(context.Context, *FetchResourcesRequest) (*FetchResourcesResponse, error)
 	// FetchRelated returns a list of resources related to the given resource type
 	// and key.
 	FetchRelated(context.Context, *FetchRelatedRequest) (*FetchRelatedResponse, error)
+	// FetchRelatedBatch returns the related resources of many keys in one call,
+	// grouped by key. Optional: the indexer falls back to one FetchRelated call
+	// per key when it is unimplemented.
+	FetchRelatedBatch(context.Context, *FetchRelatedBatchRequest) (*FetchRelatedBatchResponse, error)
 	// ListResources returns a paginated list of all resources of a given type.
 	// Used for full index rebuilds.
 	ListResources(context.Context, *ListResourcesRequest) (*ListResourcesResponse, error)
@@ -108,9 +146,15 @@ type UnimplementedProviderServiceServer struct{}
 func (UnimplementedProviderServiceServer) FetchResource(context.Context, *FetchResourceRequest) (*FetchResourceResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method FetchResource not implemented")
 }
+func (UnimplementedProviderServiceServer) FetchResources(context.Context, *FetchResourcesRequest) (*FetchResourcesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FetchResources not implemented")
+}
 func (UnimplementedProviderServiceServer) FetchRelated(context.Context, *FetchRelatedRequest) (*FetchRelatedResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method FetchRelated not implemented")
 }
+func (UnimplementedProviderServiceServer) FetchRelatedBatch(context.Context, *FetchRelatedBatchRequest) (*FetchRelatedBatchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FetchRelatedBatch not implemented")
+}
 func (UnimplementedProviderServiceServer) ListResources(context.Context, *ListResourcesRequest) (*ListResourcesResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ListResources not implemented")
 }
@@ -152,6 +196,24 @@ func _ProviderService_FetchResource_Handler(srv interface{}, ctx context.Context
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ProviderService_FetchResources_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FetchResourcesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProviderServiceServer).FetchResources(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ProviderService_FetchResources_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProviderServiceServer).FetchResources(ctx, req.(*FetchResourcesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _ProviderService_FetchRelated_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(FetchRelatedRequest)
 	if err := dec(in); err != nil {
@@ -170,6 +232,24 @@ func _ProviderService_FetchRelated_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ProviderService_FetchRelatedBatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FetchRelatedBatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProviderServiceServer).FetchRelatedBatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ProviderService_FetchRelatedBatch_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProviderServiceServer).FetchRelatedBatch(ctx, req.(*FetchRelatedBatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _ProviderService_ListResources_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(ListResourcesRequest)
 	if err := dec(in); err != nil {
@@ -199,10 +279,18 @@ var ProviderService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "FetchResource",
 			Handler:    _ProviderService_FetchResource_Handler,
 		},
+		{
+			MethodName: "FetchResources",
+			Handler:    _ProviderService_FetchResources_Handler,
+		},
 		{
 			MethodName: "FetchRelated",
 			Handler:    _ProviderService_FetchRelated_Handler,
 		},
+		{
+			MethodName: "FetchRelatedBatch",
+			Handler:    _ProviderService_FetchRelatedBatch_Handler,
+		},
 		{
 			MethodName: "ListResources",
 			Handler:    _ProviderService_ListResources_Handler,
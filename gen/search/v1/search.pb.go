@@ -28,6 +28,12 @@ const (
 	FilterOp_FILTER_OP_UNSPECIFIED FilterOp = 0
 	FilterOp_FILTER_OP_EQ          FilterOp = 1 // term query
 	FilterOp_FILTER_OP_IN          FilterOp = 2 // terms query
+	FilterOp_FILTER_OP_RANGE       FilterOp = 3 // range query (gt/gte/lt/lte)
+	FilterOp_FILTER_OP_EXISTS      FilterOp = 4 // exists query
+	FilterOp_FILTER_OP_PREFIX      FilterOp = 5 // prefix query
+	FilterOp_FILTER_OP_WILDCARD    FilterOp = 6 // wildcard query
+	FilterOp_FILTER_OP_NOT_EQ      FilterOp = 7 // negated term query
+	FilterOp_FILTER_OP_NOT_IN      FilterOp = 8 // negated terms query
 )
 
 // Enum value maps for FilterOp.
@@ -36,11 +42,23 @@ var (
 		0: "FILTER_OP_UNSPECIFIED",
 		1: "FILTER_OP_EQ",
 		2: "FILTER_OP_IN",
+		3: "FILTER_OP_RANGE",
+		4: "FILTER_OP_EXISTS",
+		5: "FILTER_OP_PREFIX",
+		6: "FILTER_OP_WILDCARD",
+		7: "FILTER_OP_NOT_EQ",
+		8: "FILTER_OP_NOT_IN",
 	}
 	FilterOp_value = map[string]int32{
 		"FILTER_OP_UNSPECIFIED": 0,
 		"FILTER_OP_EQ":          1,
 		"FILTER_OP_IN":          2,
+		"FILTER_OP_RANGE":       3,
+		"FILTER_OP_EXISTS":      4,
+		"FILTER_OP_PREFIX":      5,
+		"FILTER_OP_WILDCARD":    6,
+		"FILTER_OP_NOT_EQ":      7,
+		"FILTER_OP_NOT_IN":      8,
 	}
 )
 
@@ -71,38 +89,2024 @@ func (FilterOp) EnumDescriptor() ([]byte, []int) {
 	return file_search_v1_search_proto_rawDescGZIP(), []int{0}
 }
 
+type GroupOp int32
+
+const (
+	GroupOp_GROUP_OP_UNSPECIFIED GroupOp = 0 // treated as AND
+	GroupOp_GROUP_OP_AND         GroupOp = 1 // all children must match
+	GroupOp_GROUP_OP_OR          GroupOp = 2 // at least one child must match
+	GroupOp_GROUP_OP_NOT         GroupOp = 3 // no child may match
+)
+
+// Enum value maps for GroupOp.
+var (
+	GroupOp_name = map[int32]string{
+		0: "GROUP_OP_UNSPECIFIED",
+		1: "GROUP_OP_AND",
+		2: "GROUP_OP_OR",
+		3: "GROUP_OP_NOT",
+	}
+	GroupOp_value = map[string]int32{
+		"GROUP_OP_UNSPECIFIED": 0,
+		"GROUP_OP_AND":         1,
+		"GROUP_OP_OR":          2,
+		"GROUP_OP_NOT":         3,
+	}
+)
+
+func (x GroupOp) Enum() *GroupOp {
+	p := new(GroupOp)
+	*p = x
+	return p
+}
+
+func (x GroupOp) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (GroupOp) Descriptor() protoreflect.EnumDescriptor {
+	return file_search_v1_search_proto_enumTypes[1].Descriptor()
+}
+
+func (GroupOp) Type() protoreflect.EnumType {
+	return &file_search_v1_search_proto_enumTypes[1]
+}
+
+func (x GroupOp) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use GroupOp.Descriptor instead.
+func (GroupOp) EnumDescriptor() ([]byte, []int) {
+	return file_search_v1_search_proto_rawDescGZIP(), []int{1}
+}
+
+type MetricType int32
+
+const (
+	MetricType_METRIC_TYPE_UNSPECIFIED MetricType = 0
+	MetricType_METRIC_TYPE_MIN         MetricType = 1
+	MetricType_METRIC_TYPE_MAX         MetricType = 2
+	MetricType_METRIC_TYPE_AVG         MetricType = 3
+	MetricType_METRIC_TYPE_SUM         MetricType = 4
+)
+
+// Enum value maps for MetricType.
+var (
+	MetricType_name = map[int32]string{
+		0: "METRIC_TYPE_UNSPECIFIED",
+		1: "METRIC_TYPE_MIN",
+		2: "METRIC_TYPE_MAX",
+		3: "METRIC_TYPE_AVG",
+		4: "METRIC_TYPE_SUM",
+	}
+	MetricType_value = map[string]int32{
+		"METRIC_TYPE_UNSPECIFIED": 0,
+		"METRIC_TYPE_MIN":         1,
+		"METRIC_TYPE_MAX":         2,
+		"METRIC_TYPE_AVG":         3,
+		"METRIC_TYPE_SUM":         4,
+	}
+)
+
+func (x MetricType) Enum() *MetricType {
+	p := new(MetricType)
+	*p = x
+	return p
+}
+
+func (x MetricType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (MetricType) Descriptor() protoreflect.EnumDescriptor {
+	return file_search_v1_search_proto_enumTypes[2].Descriptor()
+}
+
+func (MetricType) Type() protoreflect.EnumType {
+	return &file_search_v1_search_proto_enumTypes[2]
+}
+
+func (x MetricType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use MetricType.Descriptor instead.
+func (MetricType) EnumDescriptor() ([]byte, []int) {
+	return file_search_v1_search_proto_rawDescGZIP(), []int{2}
+}
+
+type AggregationKind int32
+
+const (
+	AggregationKind_AGGREGATION_KIND_UNSPECIFIED    AggregationKind = 0
+	AggregationKind_AGGREGATION_KIND_TERMS          AggregationKind = 1
+	AggregationKind_AGGREGATION_KIND_RANGE          AggregationKind = 2
+	AggregationKind_AGGREGATION_KIND_DATE_HISTOGRAM AggregationKind = 3
+	AggregationKind_AGGREGATION_KIND_METRIC         AggregationKind = 4
+)
+
+// Enum value maps for AggregationKind.
+var (
+	AggregationKind_name = map[int32]string{
+		0: "AGGREGATION_KIND_UNSPECIFIED",
+		1: "AGGREGATION_KIND_TERMS",
+		2: "AGGREGATION_KIND_RANGE",
+		3: "AGGREGATION_KIND_DATE_HISTOGRAM",
+		4: "AGGREGATION_KIND_METRIC",
+	}
+	AggregationKind_value = map[string]int32{
+		"AGGREGATION_KIND_UNSPECIFIED":    0,
+		"AGGREGATION_KIND_TERMS":          1,
+		"AGGREGATION_KIND_RANGE":          2,
+		"AGGREGATION_KIND_DATE_HISTOGRAM": 3,
+		"AGGREGATION_KIND_METRIC":         4,
+	}
+)
+
+func (x AggregationKind) Enum() *AggregationKind {
+	p := new(AggregationKind)
+	*p = x
+	return p
+}
+
+func (x AggregationKind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (AggregationKind) Descriptor() protoreflect.EnumDescriptor {
+	return file_search_v1_search_proto_enumTypes[3].Descriptor()
+}
+
+func (AggregationKind) Type() protoreflect.EnumType {
+	return &file_search_v1_search_proto_enumTypes[3]
+}
+
+func (x AggregationKind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use AggregationKind.Descriptor instead.
+func (AggregationKind) EnumDescriptor() ([]byte, []int) {
+	return file_search_v1_search_proto_rawDescGZIP(), []int{3}
+}
+
 type SearchRequest struct {
 	state    protoimpl.MessageState `protogen:"open.v1"`
 	Resource string                 `protobuf:"bytes,1,opt,name=resource,proto3" json:"resource,omitempty"`
 	Query    string                 `protobuf:"bytes,2,opt,name=query,proto3" json:"query,omitempty"`
 	Filters  []*Filter              `protobuf:"bytes,3,rep,name=filters,proto3" json:"filters,omitempty"`
-	// Pagination (simple from/size)
-	// TODO: Support page tokens?
-	// TODO: Support pit:s?
+	// Pagination (simple from/size). Limited to the first 10k hits; use
+	// page_token to page beyond that.
 	Page     int32 `protobuf:"varint,4,opt,name=page,proto3" json:"page,omitempty"`
 	PageSize int32 `protobuf:"varint,5,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
 	// TODO: Multiple fields? Why?
-	Sort          []*Sort `protobuf:"bytes,6,rep,name=sort,proto3" json:"sort,omitempty"`
-	IncludeSource bool    `protobuf:"varint,7,opt,name=include_source,json=includeSource,proto3" json:"include_source,omitempty"`
+	Sort []*Sort `protobuf:"bytes,6,rep,name=sort,proto3" json:"sort,omitempty"`
+	// Whether hits carry the indexed document. Only the id and score of each
+	// hit are returned otherwise.
+	IncludeSource bool `protobuf:"varint,7,opt,name=include_source,json=includeSource,proto3" json:"include_source,omitempty"`
+	// Optional boolean filter tree. It is ANDed together with the flat filters
+	// above, so both can be used in the same request.
+	// Example: (status=open OR status=pending) AND NOT owner=x
+	FilterGroup *FilterGroup `protobuf:"bytes,8,opt,name=filter_group,json=filterGroup,proto3" json:"filter_group,omitempty"`
+	// Aggregations (facets) computed over all documents matching the query and
+	// filters, independent of pagination.
+	Aggregations []*Aggregation `protobuf:"bytes,9,rep,name=aggregations,proto3" json:"aggregations,omitempty"`
+	// Cursor-based pagination. Pass the next_page_token of the previous
+	// response to get the page after it. Requests using a page token must keep
+	// the same query, filters and sort, and leave page unset.
+	PageToken string `protobuf:"bytes,10,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	// When set on the first page, the pages are read from a point-in-time
+	// snapshot of the index so that concurrent writes don't shift results.
+	// The snapshot is carried in the page tokens and expires when no page has
+	// been requested for a minute.
+	PointInTime bool `protobuf:"varint,11,opt,name=point_in_time,json=pointInTime,proto3" json:"point_in_time,omitempty"`
+	// Source fields to return, e.g. "fields.title" or "b.name". Setting it
+	// implies include_source; the full source is returned when it is empty.
+	Fields []string `protobuf:"bytes,12,rep,name=fields,proto3" json:"fields,omitempty"`
+	// Highlighting of the parts of each hit that matched the query.
+	Highlight     *Highlight `protobuf:"bytes,13,opt,name=highlight,proto3" json:"highlight,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchRequest) Reset() {
+	*x = SearchRequest{}
+	mi := &file_search_v1_search_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchRequest) ProtoMessage() {}
+
+func (x *SearchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_search_v1_search_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchRequest.ProtoReflect.Descriptor instead.
+func (*SearchRequest) Descriptor() ([]byte, []int) {
+	return file_search_v1_search_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *SearchRequest) GetResource() string {
+	if x != nil {
+		return x.Resource
+	}
+	return ""
+}
+
+func (x *SearchRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *SearchRequest) GetFilters() []*Filter {
+	if x != nil {
+		return x.Filters
+	}
+	return nil
+}
+
+func (x *SearchRequest) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *SearchRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *SearchRequest) GetSort() []*Sort {
+	if x != nil {
+		return x.Sort
+	}
+	return nil
+}
+
+func (x *SearchRequest) GetIncludeSource() bool {
+	if x != nil {
+		return x.IncludeSource
+	}
+	return false
+}
+
+func (x *SearchRequest) GetFilterGroup() *FilterGroup {
+	if x != nil {
+		return x.FilterGroup
+	}
+	return nil
+}
+
+func (x *SearchRequest) GetAggregations() []*Aggregation {
+	if x != nil {
+		return x.Aggregations
+	}
+	return nil
+}
+
+func (x *SearchRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+func (x *SearchRequest) GetPointInTime() bool {
+	if x != nil {
+		return x.PointInTime
+	}
+	return false
+}
+
+func (x *SearchRequest) GetFields() []string {
+	if x != nil {
+		return x.Fields
+	}
+	return nil
+}
+
+func (x *SearchRequest) GetHighlight() *Highlight {
+	if x != nil {
+		return x.Highlight
+	}
+	return nil
+}
+
+type Highlight struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Fields to highlight, e.g. "fields.title" or "b.name". Defaults to the
+	// searchable fields of the resource.
+	Fields []string `protobuf:"bytes,1,rep,name=fields,proto3" json:"fields,omitempty"`
+	// Size of each fragment in characters. Defaults to 100.
+	FragmentSize int32 `protobuf:"varint,2,opt,name=fragment_size,json=fragmentSize,proto3" json:"fragment_size,omitempty"`
+	// Maximum number of fragments per field. Defaults to 5. With 0 the whole
+	// field value is highlighted.
+	NumberOfFragments *int32 `protobuf:"varint,3,opt,name=number_of_fragments,json=numberOfFragments,proto3,oneof" json:"number_of_fragments,omitempty"`
+	// Tags wrapped around each match. Default to <em> and </em>.
+	PreTag        string `protobuf:"bytes,4,opt,name=pre_tag,json=preTag,proto3" json:"pre_tag,omitempty"`
+	PostTag       string `protobuf:"bytes,5,opt,name=post_tag,json=postTag,proto3" json:"post_tag,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Highlight) Reset() {
+	*x = Highlight{}
+	mi := &file_search_v1_search_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Highlight) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Highlight) ProtoMessage() {}
+
+func (x *Highlight) ProtoReflect() protoreflect.Message {
+	mi := &file_search_v1_search_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Highlight.ProtoReflect.Descriptor instead.
+func (*Highlight) Descriptor() ([]byte, []int) {
+	return file_search_v1_search_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Highlight) GetFields() []string {
+	if x != nil {
+		return x.Fields
+	}
+	return nil
+}
+
+func (x *Highlight) GetFragmentSize() int32 {
+	if x != nil {
+		return x.FragmentSize
+	}
+	return 0
+}
+
+func (x *Highlight) GetNumberOfFragments() int32 {
+	if x != nil && x.NumberOfFragments != nil {
+		return *x.NumberOfFragments
+	}
+	return 0
+}
+
+func (x *Highlight) GetPreTag() string {
+	if x != nil {
+		return x.PreTag
+	}
+	return ""
+}
+
+func (x *Highlight) GetPostTag() string {
+	if x != nil {
+		return x.PostTag
+	}
+	return ""
+}
+
+type HighlightFragments struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Fragments     []string               `protobuf:"bytes,1,rep,name=fragments,proto3" json:"fragments,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HighlightFragments) Reset() {
+	*x = HighlightFragments{}
+	mi := &file_search_v1_search_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HighlightFragments) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HighlightFragments) ProtoMessage() {}
+
+func (x *HighlightFragments) ProtoReflect() protoreflect.Message {
+	mi := &file_search_v1_search_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HighlightFragments.ProtoReflect.Descriptor instead.
+func (*HighlightFragments) Descriptor() ([]byte, []int) {
+	return file_search_v1_search_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *HighlightFragments) GetFragments() []string {
+	if x != nil {
+		return x.Fragments
+	}
+	return nil
+}
+
+type SearchHit struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Score float64                `protobuf:"fixed64,2,opt,name=score,proto3" json:"score,omitempty"`
+	// The indexed document (source)
+	Source *structpb.Struct `protobuf:"bytes,3,opt,name=source,proto3" json:"source,omitempty"`
+	// Highlighted fragments keyed by field path. Only set when highlighting was
+	// requested and the field matched.
+	Highlights map[string]*HighlightFragments `protobuf:"bytes,4,rep,name=highlights,proto3" json:"highlights,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// The resource type of the hit. Only set by MultiSearch.
+	Resource      string `protobuf:"bytes,5,opt,name=resource,proto3" json:"resource,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchHit) Reset() {
+	*x = SearchHit{}
+	mi := &file_search_v1_search_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchHit) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchHit) ProtoMessage() {}
+
+func (x *SearchHit) ProtoReflect() protoreflect.Message {
+	mi := &file_search_v1_search_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchHit.ProtoReflect.Descriptor instead.
+func (*SearchHit) Descriptor() ([]byte, []int) {
+	return file_search_v1_search_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *SearchHit) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *SearchHit) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *SearchHit) GetSource() *structpb.Struct {
+	if x != nil {
+		return x.Source
+	}
+	return nil
+}
+
+func (x *SearchHit) GetHighlights() map[string]*HighlightFragments {
+	if x != nil {
+		return x.Highlights
+	}
+	return nil
+}
+
+func (x *SearchHit) GetResource() string {
+	if x != nil {
+		return x.Resource
+	}
+	return ""
+}
+
+type SearchResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Total int64                  `protobuf:"varint,1,opt,name=total,proto3" json:"total,omitempty"`
+	Hits  []*SearchHit           `protobuf:"bytes,2,rep,name=hits,proto3" json:"hits,omitempty"`
+	// One result per requested aggregation, in request order.
+	Aggregations []*AggregationResult `protobuf:"bytes,3,rep,name=aggregations,proto3" json:"aggregations,omitempty"`
+	// Token for the next page. Empty once a page has fewer hits than
+	// page_size.
+	NextPageToken string `protobuf:"bytes,4,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchResponse) Reset() {
+	*x = SearchResponse{}
+	mi := &file_search_v1_search_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchResponse) ProtoMessage() {}
+
+func (x *SearchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_search_v1_search_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchResponse.ProtoReflect.Descriptor instead.
+func (*SearchResponse) Descriptor() ([]byte, []int) {
+	return file_search_v1_search_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *SearchResponse) GetTotal() int64 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *SearchResponse) GetHits() []*SearchHit {
+	if x != nil {
+		return x.Hits
+	}
+	return nil
+}
+
+func (x *SearchResponse) GetAggregations() []*AggregationResult {
+	if x != nil {
+		return x.Aggregations
+	}
+	return nil
+}
+
+func (x *SearchResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+type MultiSearchRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Full-text query, matched against the searchable fields of each resource.
+	Query     string                 `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	Resources []*MultiSearchResource `protobuf:"bytes,2,rep,name=resources,proto3" json:"resources,omitempty"`
+	// Maximum number of hits in total. Defaults to 25, at most 100.
+	Size          int32 `protobuf:"varint,3,opt,name=size,proto3" json:"size,omitempty"`
+	IncludeSource bool  `protobuf:"varint,4,opt,name=include_source,json=includeSource,proto3" json:"include_source,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MultiSearchRequest) Reset() {
+	*x = MultiSearchRequest{}
+	mi := &file_search_v1_search_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MultiSearchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MultiSearchRequest) ProtoMessage() {}
+
+func (x *MultiSearchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_search_v1_search_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MultiSearchRequest.ProtoReflect.Descriptor instead.
+func (*MultiSearchRequest) Descriptor() ([]byte, []int) {
+	return file_search_v1_search_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *MultiSearchRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *MultiSearchRequest) GetResources() []*MultiSearchResource {
+	if x != nil {
+		return x.Resources
+	}
+	return nil
+}
+
+func (x *MultiSearchRequest) GetSize() int32 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *MultiSearchRequest) GetIncludeSource() bool {
+	if x != nil {
+		return x.IncludeSource
+	}
+	return false
+}
+
+type MultiSearchResource struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Resource string                 `protobuf:"bytes,1,opt,name=resource,proto3" json:"resource,omitempty"`
+	// Multiplies the scores of the resource's hits before they are merged.
+	// Scores of different resources are not directly comparable, so this can
+	// be used to favor one resource over another. Defaults to 1.
+	Weight float64 `protobuf:"fixed64,2,opt,name=weight,proto3" json:"weight,omitempty"`
+	// Maximum number of hits of this resource in the response. Defaults to
+	// the request size.
+	Quota int32 `protobuf:"varint,3,opt,name=quota,proto3" json:"quota,omitempty"`
+	// Filters restricting the hits of this resource only.
+	Filters       []*Filter    `protobuf:"bytes,4,rep,name=filters,proto3" json:"filters,omitempty"`
+	FilterGroup   *FilterGroup `protobuf:"bytes,5,opt,name=filter_group,json=filterGroup,proto3" json:"filter_group,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MultiSearchResource) Reset() {
+	*x = MultiSearchResource{}
+	mi := &file_search_v1_search_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MultiSearchResource) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MultiSearchResource) ProtoMessage() {}
+
+func (x *MultiSearchResource) ProtoReflect() protoreflect.Message {
+	mi := &file_search_v1_search_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MultiSearchResource.ProtoReflect.Descriptor instead.
+func (*MultiSearchResource) Descriptor() ([]byte, []int) {
+	return file_search_v1_search_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *MultiSearchResource) GetResource() string {
+	if x != nil {
+		return x.Resource
+	}
+	return ""
+}
+
+func (x *MultiSearchResource) GetWeight() float64 {
+	if x != nil {
+		return x.Weight
+	}
+	return 0
+}
+
+func (x *MultiSearchResource) GetQuota() int32 {
+	if x != nil {
+		return x.Quota
+	}
+	return 0
+}
+
+func (x *MultiSearchResource) GetFilters() []*Filter {
+	if x != nil {
+		return x.Filters
+	}
+	return nil
+}
+
+func (x *MultiSearchResource) GetFilterGroup() *FilterGroup {
+	if x != nil {
+		return x.FilterGroup
+	}
+	return nil
+}
+
+type MultiSearchResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Hits of all resources ordered by weighted score. Each hit carries its
+	// resource type.
+	Hits []*SearchHit `protobuf:"bytes,1,rep,name=hits,proto3" json:"hits,omitempty"`
+	// Total number of matches per resource.
+	Totals        map[string]int64 `protobuf:"bytes,2,rep,name=totals,proto3" json:"totals,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MultiSearchResponse) Reset() {
+	*x = MultiSearchResponse{}
+	mi := &file_search_v1_search_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MultiSearchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MultiSearchResponse) ProtoMessage() {}
+
+func (x *MultiSearchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_search_v1_search_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MultiSearchResponse.ProtoReflect.Descriptor instead.
+func (*MultiSearchResponse) Descriptor() ([]byte, []int) {
+	return file_search_v1_search_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *MultiSearchResponse) GetHits() []*SearchHit {
+	if x != nil {
+		return x.Hits
+	}
+	return nil
+}
+
+func (x *MultiSearchResponse) GetTotals() map[string]int64 {
+	if x != nil {
+		return x.Totals
+	}
+	return nil
+}
+
+type ExportRequest struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Resource    string                 `protobuf:"bytes,1,opt,name=resource,proto3" json:"resource,omitempty"`
+	Query       string                 `protobuf:"bytes,2,opt,name=query,proto3" json:"query,omitempty"`
+	Filters     []*Filter              `protobuf:"bytes,3,rep,name=filters,proto3" json:"filters,omitempty"`
+	FilterGroup *FilterGroup           `protobuf:"bytes,4,opt,name=filter_group,json=filterGroup,proto3" json:"filter_group,omitempty"`
+	Sort        []*Sort                `protobuf:"bytes,5,rep,name=sort,proto3" json:"sort,omitempty"`
+	// Source fields to return, e.g. "fields.title" or "b.name". The full
+	// source is returned when empty.
+	Fields []string `protobuf:"bytes,6,rep,name=fields,proto3" json:"fields,omitempty"`
+	// Number of hits per streamed batch. Defaults to 500, at most 5000.
+	BatchSize     int32 `protobuf:"varint,7,opt,name=batch_size,json=batchSize,proto3" json:"batch_size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExportRequest) Reset() {
+	*x = ExportRequest{}
+	mi := &file_search_v1_search_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportRequest) ProtoMessage() {}
+
+func (x *ExportRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_search_v1_search_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportRequest.ProtoReflect.Descriptor instead.
+func (*ExportRequest) Descriptor() ([]byte, []int) {
+	return file_search_v1_search_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ExportRequest) GetResource() string {
+	if x != nil {
+		return x.Resource
+	}
+	return ""
+}
+
+func (x *ExportRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *ExportRequest) GetFilters() []*Filter {
+	if x != nil {
+		return x.Filters
+	}
+	return nil
+}
+
+func (x *ExportRequest) GetFilterGroup() *FilterGroup {
+	if x != nil {
+		return x.FilterGroup
+	}
+	return nil
+}
+
+func (x *ExportRequest) GetSort() []*Sort {
+	if x != nil {
+		return x.Sort
+	}
+	return nil
+}
+
+func (x *ExportRequest) GetFields() []string {
+	if x != nil {
+		return x.Fields
+	}
+	return nil
+}
+
+func (x *ExportRequest) GetBatchSize() int32 {
+	if x != nil {
+		return x.BatchSize
+	}
+	return 0
+}
+
+type ExportResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Hits          []*SearchHit           `protobuf:"bytes,1,rep,name=hits,proto3" json:"hits,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExportResponse) Reset() {
+	*x = ExportResponse{}
+	mi := &file_search_v1_search_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportResponse) ProtoMessage() {}
+
+func (x *ExportResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_search_v1_search_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportResponse.ProtoReflect.Descriptor instead.
+func (*ExportResponse) Descriptor() ([]byte, []int) {
+	return file_search_v1_search_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ExportResponse) GetHits() []*SearchHit {
+	if x != nil {
+		return x.Hits
+	}
+	return nil
+}
+
+type SuggestRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Resource string                 `protobuf:"bytes,1,opt,name=resource,proto3" json:"resource,omitempty"`
+	// The text typed so far.
+	Prefix string `protobuf:"bytes,2,opt,name=prefix,proto3" json:"prefix,omitempty"`
+	// Fields to complete, e.g. "fields.title". Defaults to all fields with
+	// suggestions enabled.
+	Fields []string `protobuf:"bytes,3,rep,name=fields,proto3" json:"fields,omitempty"`
+	// Restrict suggestions to documents matching these filters.
+	Filters     []*Filter    `protobuf:"bytes,4,rep,name=filters,proto3" json:"filters,omitempty"`
+	FilterGroup *FilterGroup `protobuf:"bytes,5,opt,name=filter_group,json=filterGroup,proto3" json:"filter_group,omitempty"`
+	// Maximum number of suggestions. Defaults to 10, at most 50.
+	Size          int32 `protobuf:"varint,6,opt,name=size,proto3" json:"size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SuggestRequest) Reset() {
+	*x = SuggestRequest{}
+	mi := &file_search_v1_search_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SuggestRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SuggestRequest) ProtoMessage() {}
+
+func (x *SuggestRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_search_v1_search_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SuggestRequest.ProtoReflect.Descriptor instead.
+func (*SuggestRequest) Descriptor() ([]byte, []int) {
+	return file_search_v1_search_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *SuggestRequest) GetResource() string {
+	if x != nil {
+		return x.Resource
+	}
+	return ""
+}
+
+func (x *SuggestRequest) GetPrefix() string {
+	if x != nil {
+		return x.Prefix
+	}
+	return ""
+}
+
+func (x *SuggestRequest) GetFields() []string {
+	if x != nil {
+		return x.Fields
+	}
+	return nil
+}
+
+func (x *SuggestRequest) GetFilters() []*Filter {
+	if x != nil {
+		return x.Filters
+	}
+	return nil
+}
+
+func (x *SuggestRequest) GetFilterGroup() *FilterGroup {
+	if x != nil {
+		return x.FilterGroup
+	}
+	return nil
+}
+
+func (x *SuggestRequest) GetSize() int32 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+type Suggestion struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The completed field value.
+	Text string `protobuf:"bytes,1,opt,name=text,proto3" json:"text,omitempty"`
+	// The field the value belongs to, e.g. "fields.title".
+	Field string `protobuf:"bytes,2,opt,name=field,proto3" json:"field,omitempty"`
+	// ID of the document the value comes from.
+	Id            string  `protobuf:"bytes,3,opt,name=id,proto3" json:"id,omitempty"`
+	Score         float64 `protobuf:"fixed64,4,opt,name=score,proto3" json:"score,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Suggestion) Reset() {
+	*x = Suggestion{}
+	mi := &file_search_v1_search_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Suggestion) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Suggestion) ProtoMessage() {}
+
+func (x *Suggestion) ProtoReflect() protoreflect.Message {
+	mi := &file_search_v1_search_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Suggestion.ProtoReflect.Descriptor instead.
+func (*Suggestion) Descriptor() ([]byte, []int) {
+	return file_search_v1_search_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *Suggestion) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *Suggestion) GetField() string {
+	if x != nil {
+		return x.Field
+	}
+	return ""
+}
+
+func (x *Suggestion) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Suggestion) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+type SuggestResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Suggestions ordered by relevance, at most one per document.
+	Suggestions   []*Suggestion `protobuf:"bytes,1,rep,name=suggestions,proto3" json:"suggestions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SuggestResponse) Reset() {
+	*x = SuggestResponse{}
+	mi := &file_search_v1_search_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SuggestResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SuggestResponse) ProtoMessage() {}
+
+func (x *SuggestResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_search_v1_search_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SuggestResponse.ProtoReflect.Descriptor instead.
+func (*SuggestResponse) Descriptor() ([]byte, []int) {
+	return file_search_v1_search_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *SuggestResponse) GetSuggestions() []*Suggestion {
+	if x != nil {
+		return x.Suggestions
+	}
+	return nil
+}
+
+type Filter struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Example: "b.name.keyword" or "a_status" or "c.state"
+	Field string   `protobuf:"bytes,1,opt,name=field,proto3" json:"field,omitempty"`
+	Op    FilterOp `protobuf:"varint,2,opt,name=op,proto3,enum=search.v1.FilterOp" json:"op,omitempty"`
+	// For EQ, NOT_EQ, PREFIX and WILDCARD
+	Value string `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`
+	// For IN and NOT_IN
+	Values []string `protobuf:"bytes,4,rep,name=values,proto3" json:"values,omitempty"`
+	// The nested path of the field (e.g. c is mapped as "nested"):
+	// nested_path="c", field="c.state", op=EQ, value="active"
+	// Optional: when empty it is inferred from the resource config for fields
+	// of cardinality "many" relations.
+	NestedPath string `protobuf:"bytes,5,opt,name=nested_path,json=nestedPath,proto3" json:"nested_path,omitempty"`
+	// For RANGE
+	Range         *RangeBounds `protobuf:"bytes,6,opt,name=range,proto3" json:"range,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Filter) Reset() {
+	*x = Filter{}
+	mi := &file_search_v1_search_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Filter) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Filter) ProtoMessage() {}
+
+func (x *Filter) ProtoReflect() protoreflect.Message {
+	mi := &file_search_v1_search_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Filter.ProtoReflect.Descriptor instead.
+func (*Filter) Descriptor() ([]byte, []int) {
+	return file_search_v1_search_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *Filter) GetField() string {
+	if x != nil {
+		return x.Field
+	}
+	return ""
+}
+
+func (x *Filter) GetOp() FilterOp {
+	if x != nil {
+		return x.Op
+	}
+	return FilterOp_FILTER_OP_UNSPECIFIED
+}
+
+func (x *Filter) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+func (x *Filter) GetValues() []string {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
+
+func (x *Filter) GetNestedPath() string {
+	if x != nil {
+		return x.NestedPath
+	}
+	return ""
+}
+
+func (x *Filter) GetRange() *RangeBounds {
+	if x != nil {
+		return x.Range
+	}
+	return nil
+}
+
+// RangeBounds holds the bounds of a RANGE filter. Empty bounds are ignored,
+// but at least one must be set. Values are passed to Elasticsearch as-is, so
+// dates can use any format the field's mapping accepts.
+type RangeBounds struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Gt            string                 `protobuf:"bytes,1,opt,name=gt,proto3" json:"gt,omitempty"`
+	Gte           string                 `protobuf:"bytes,2,opt,name=gte,proto3" json:"gte,omitempty"`
+	Lt            string                 `protobuf:"bytes,3,opt,name=lt,proto3" json:"lt,omitempty"`
+	Lte           string                 `protobuf:"bytes,4,opt,name=lte,proto3" json:"lte,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RangeBounds) Reset() {
+	*x = RangeBounds{}
+	mi := &file_search_v1_search_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RangeBounds) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RangeBounds) ProtoMessage() {}
+
+func (x *RangeBounds) ProtoReflect() protoreflect.Message {
+	mi := &file_search_v1_search_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RangeBounds.ProtoReflect.Descriptor instead.
+func (*RangeBounds) Descriptor() ([]byte, []int) {
+	return file_search_v1_search_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *RangeBounds) GetGt() string {
+	if x != nil {
+		return x.Gt
+	}
+	return ""
+}
+
+func (x *RangeBounds) GetGte() string {
+	if x != nil {
+		return x.Gte
+	}
+	return ""
+}
+
+func (x *RangeBounds) GetLt() string {
+	if x != nil {
+		return x.Lt
+	}
+	return ""
+}
+
+func (x *RangeBounds) GetLte() string {
+	if x != nil {
+		return x.Lte
+	}
+	return ""
+}
+
+// FilterGroup combines filters and nested groups with a boolean operator.
+type FilterGroup struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Op      GroupOp                `protobuf:"varint,1,opt,name=op,proto3,enum=search.v1.GroupOp" json:"op,omitempty"`
+	Filters []*Filter              `protobuf:"bytes,2,rep,name=filters,proto3" json:"filters,omitempty"`
+	Groups  []*FilterGroup         `protobuf:"bytes,3,rep,name=groups,proto3" json:"groups,omitempty"`
+	// If set, the whole group is compiled into a single nested query on this
+	// path, so all of its filters are evaluated against the same related item.
+	// Example: nested_path="c" with c.state=active AND c.number=5 matches
+	// documents where one c is both active and has number 5.
+	// Filters inside the group must not set their own nested_path.
+	NestedPath    string `protobuf:"bytes,4,opt,name=nested_path,json=nestedPath,proto3" json:"nested_path,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FilterGroup) Reset() {
+	*x = FilterGroup{}
+	mi := &file_search_v1_search_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FilterGroup) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FilterGroup) ProtoMessage() {}
+
+func (x *FilterGroup) ProtoReflect() protoreflect.Message {
+	mi := &file_search_v1_search_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FilterGroup.ProtoReflect.Descriptor instead.
+func (*FilterGroup) Descriptor() ([]byte, []int) {
+	return file_search_v1_search_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *FilterGroup) GetOp() GroupOp {
+	if x != nil {
+		return x.Op
+	}
+	return GroupOp_GROUP_OP_UNSPECIFIED
+}
+
+func (x *FilterGroup) GetFilters() []*Filter {
+	if x != nil {
+		return x.Filters
+	}
+	return nil
+}
+
+func (x *FilterGroup) GetGroups() []*FilterGroup {
+	if x != nil {
+		return x.Groups
+	}
+	return nil
+}
+
+func (x *FilterGroup) GetNestedPath() string {
+	if x != nil {
+		return x.NestedPath
+	}
+	return ""
+}
+
+type Sort struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Example: "updated_at"
+	Field         string `protobuf:"bytes,1,opt,name=field,proto3" json:"field,omitempty"`
+	Desc          bool   `protobuf:"varint,2,opt,name=desc,proto3" json:"desc,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Sort) Reset() {
+	*x = Sort{}
+	mi := &file_search_v1_search_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Sort) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Sort) ProtoMessage() {}
+
+func (x *Sort) ProtoReflect() protoreflect.Message {
+	mi := &file_search_v1_search_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Sort.ProtoReflect.Descriptor instead.
+func (*Sort) Descriptor() ([]byte, []int) {
+	return file_search_v1_search_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *Sort) GetField() string {
+	if x != nil {
+		return x.Field
+	}
+	return ""
+}
+
+func (x *Sort) GetDesc() bool {
+	if x != nil {
+		return x.Desc
+	}
+	return false
+}
+
+// Aggregation requests a single named aggregation over a field.
+// Aggregations on fields of cardinality "many" relations are wrapped in a
+// nested aggregation. Bucket doc counts then still count root documents, not
+// related items.
+type Aggregation struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Name identifies the aggregation in the response. Must be unique within
+	// the request.
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// Types that are valid to be assigned to Kind:
+	//
+	//	*Aggregation_Terms
+	//	*Aggregation_Range
+	//	*Aggregation_DateHistogram
+	//	*Aggregation_Metric
+	Kind isAggregation_Kind `protobuf_oneof:"kind"`
+	// The nested path of the aggregated field. Optional: when empty it is
+	// inferred from the resource config, the same way as for filters.
+	NestedPath    string `protobuf:"bytes,6,opt,name=nested_path,json=nestedPath,proto3" json:"nested_path,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Aggregation) Reset() {
+	*x = Aggregation{}
+	mi := &file_search_v1_search_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Aggregation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Aggregation) ProtoMessage() {}
+
+func (x *Aggregation) ProtoReflect() protoreflect.Message {
+	mi := &file_search_v1_search_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Aggregation.ProtoReflect.Descriptor instead.
+func (*Aggregation) Descriptor() ([]byte, []int) {
+	return file_search_v1_search_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *Aggregation) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Aggregation) GetKind() isAggregation_Kind {
+	if x != nil {
+		return x.Kind
+	}
+	return nil
+}
+
+func (x *Aggregation) GetTerms() *TermsAggregation {
+	if x != nil {
+		if x, ok := x.Kind.(*Aggregation_Terms); ok {
+			return x.Terms
+		}
+	}
+	return nil
+}
+
+func (x *Aggregation) GetRange() *RangeAggregation {
+	if x != nil {
+		if x, ok := x.Kind.(*Aggregation_Range); ok {
+			return x.Range
+		}
+	}
+	return nil
+}
+
+func (x *Aggregation) GetDateHistogram() *DateHistogramAggregation {
+	if x != nil {
+		if x, ok := x.Kind.(*Aggregation_DateHistogram); ok {
+			return x.DateHistogram
+		}
+	}
+	return nil
+}
+
+func (x *Aggregation) GetMetric() *MetricAggregation {
+	if x != nil {
+		if x, ok := x.Kind.(*Aggregation_Metric); ok {
+			return x.Metric
+		}
+	}
+	return nil
+}
+
+func (x *Aggregation) GetNestedPath() string {
+	if x != nil {
+		return x.NestedPath
+	}
+	return ""
+}
+
+type isAggregation_Kind interface {
+	isAggregation_Kind()
+}
+
+type Aggregation_Terms struct {
+	Terms *TermsAggregation `protobuf:"bytes,2,opt,name=terms,proto3,oneof"`
+}
+
+type Aggregation_Range struct {
+	Range *RangeAggregation `protobuf:"bytes,3,opt,name=range,proto3,oneof"`
+}
+
+type Aggregation_DateHistogram struct {
+	DateHistogram *DateHistogramAggregation `protobuf:"bytes,4,opt,name=date_histogram,json=dateHistogram,proto3,oneof"`
+}
+
+type Aggregation_Metric struct {
+	Metric *MetricAggregation `protobuf:"bytes,5,opt,name=metric,proto3,oneof"`
+}
+
+func (*Aggregation_Terms) isAggregation_Kind() {}
+
+func (*Aggregation_Range) isAggregation_Kind() {}
+
+func (*Aggregation_DateHistogram) isAggregation_Kind() {}
+
+func (*Aggregation_Metric) isAggregation_Kind() {}
+
+// TermsAggregation buckets documents by the distinct values of a field.
+type TermsAggregation struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Field string                 `protobuf:"bytes,1,opt,name=field,proto3" json:"field,omitempty"`
+	// Maximum number of buckets to return. Defaults to 10.
+	Size          int32 `protobuf:"varint,2,opt,name=size,proto3" json:"size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TermsAggregation) Reset() {
+	*x = TermsAggregation{}
+	mi := &file_search_v1_search_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TermsAggregation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TermsAggregation) ProtoMessage() {}
+
+func (x *TermsAggregation) ProtoReflect() protoreflect.Message {
+	mi := &file_search_v1_search_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TermsAggregation.ProtoReflect.Descriptor instead.
+func (*TermsAggregation) Descriptor() ([]byte, []int) {
+	return file_search_v1_search_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *TermsAggregation) GetField() string {
+	if x != nil {
+		return x.Field
+	}
+	return ""
+}
+
+func (x *TermsAggregation) GetSize() int32 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+// RangeAggregation buckets documents by numeric ranges of a field.
+type RangeAggregation struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Field         string                 `protobuf:"bytes,1,opt,name=field,proto3" json:"field,omitempty"`
+	Ranges        []*AggregationRange    `protobuf:"bytes,2,rep,name=ranges,proto3" json:"ranges,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RangeAggregation) Reset() {
+	*x = RangeAggregation{}
+	mi := &file_search_v1_search_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RangeAggregation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RangeAggregation) ProtoMessage() {}
+
+func (x *RangeAggregation) ProtoReflect() protoreflect.Message {
+	mi := &file_search_v1_search_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RangeAggregation.ProtoReflect.Descriptor instead.
+func (*RangeAggregation) Descriptor() ([]byte, []int) {
+	return file_search_v1_search_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *RangeAggregation) GetField() string {
+	if x != nil {
+		return x.Field
+	}
+	return ""
+}
+
+func (x *RangeAggregation) GetRanges() []*AggregationRange {
+	if x != nil {
+		return x.Ranges
+	}
+	return nil
+}
+
+type AggregationRange struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Optional bucket key. Defaults to the key generated by Elasticsearch.
+	Key string `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	// Inclusive lower bound. Unbounded when unset.
+	From *float64 `protobuf:"fixed64,2,opt,name=from,proto3,oneof" json:"from,omitempty"`
+	// Exclusive upper bound. Unbounded when unset.
+	To            *float64 `protobuf:"fixed64,3,opt,name=to,proto3,oneof" json:"to,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AggregationRange) Reset() {
+	*x = AggregationRange{}
+	mi := &file_search_v1_search_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AggregationRange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AggregationRange) ProtoMessage() {}
+
+func (x *AggregationRange) ProtoReflect() protoreflect.Message {
+	mi := &file_search_v1_search_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AggregationRange.ProtoReflect.Descriptor instead.
+func (*AggregationRange) Descriptor() ([]byte, []int) {
+	return file_search_v1_search_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *AggregationRange) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *AggregationRange) GetFrom() float64 {
+	if x != nil && x.From != nil {
+		return *x.From
+	}
+	return 0
+}
+
+func (x *AggregationRange) GetTo() float64 {
+	if x != nil && x.To != nil {
+		return *x.To
+	}
+	return 0
+}
+
+// DateHistogramAggregation buckets documents by date intervals of a field.
+// Exactly one of calendar_interval and fixed_interval must be set.
+type DateHistogramAggregation struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Field string                 `protobuf:"bytes,1,opt,name=field,proto3" json:"field,omitempty"`
+	// Example: "day", "week", "month"
+	CalendarInterval string `protobuf:"bytes,2,opt,name=calendar_interval,json=calendarInterval,proto3" json:"calendar_interval,omitempty"`
+	// Example: "12h", "30m"
+	FixedInterval string `protobuf:"bytes,3,opt,name=fixed_interval,json=fixedInterval,proto3" json:"fixed_interval,omitempty"`
+	// Optional date format for bucket keys, e.g. "yyyy-MM-dd".
+	Format        string `protobuf:"bytes,4,opt,name=format,proto3" json:"format,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DateHistogramAggregation) Reset() {
+	*x = DateHistogramAggregation{}
+	mi := &file_search_v1_search_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DateHistogramAggregation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DateHistogramAggregation) ProtoMessage() {}
+
+func (x *DateHistogramAggregation) ProtoReflect() protoreflect.Message {
+	mi := &file_search_v1_search_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DateHistogramAggregation.ProtoReflect.Descriptor instead.
+func (*DateHistogramAggregation) Descriptor() ([]byte, []int) {
+	return file_search_v1_search_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *DateHistogramAggregation) GetField() string {
+	if x != nil {
+		return x.Field
+	}
+	return ""
+}
+
+func (x *DateHistogramAggregation) GetCalendarInterval() string {
+	if x != nil {
+		return x.CalendarInterval
+	}
+	return ""
+}
+
+func (x *DateHistogramAggregation) GetFixedInterval() string {
+	if x != nil {
+		return x.FixedInterval
+	}
+	return ""
+}
+
+func (x *DateHistogramAggregation) GetFormat() string {
+	if x != nil {
+		return x.Format
+	}
+	return ""
+}
+
+// MetricAggregation computes a single value over a field.
+type MetricAggregation struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Field         string                 `protobuf:"bytes,1,opt,name=field,proto3" json:"field,omitempty"`
+	Type          MetricType             `protobuf:"varint,2,opt,name=type,proto3,enum=search.v1.MetricType" json:"type,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MetricAggregation) Reset() {
+	*x = MetricAggregation{}
+	mi := &file_search_v1_search_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MetricAggregation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MetricAggregation) ProtoMessage() {}
+
+func (x *MetricAggregation) ProtoReflect() protoreflect.Message {
+	mi := &file_search_v1_search_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MetricAggregation.ProtoReflect.Descriptor instead.
+func (*MetricAggregation) Descriptor() ([]byte, []int) {
+	return file_search_v1_search_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *MetricAggregation) GetField() string {
+	if x != nil {
+		return x.Field
+	}
+	return ""
+}
+
+func (x *MetricAggregation) GetType() MetricType {
+	if x != nil {
+		return x.Type
+	}
+	return MetricType_METRIC_TYPE_UNSPECIFIED
+}
+
+type AggregationResult struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Name  string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// Types that are valid to be assigned to Result:
+	//
+	//	*AggregationResult_Terms
+	//	*AggregationResult_Range
+	//	*AggregationResult_DateHistogram
+	//	*AggregationResult_Metric
+	Result        isAggregationResult_Result `protobuf_oneof:"result"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AggregationResult) Reset() {
+	*x = AggregationResult{}
+	mi := &file_search_v1_search_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AggregationResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AggregationResult) ProtoMessage() {}
+
+func (x *AggregationResult) ProtoReflect() protoreflect.Message {
+	mi := &file_search_v1_search_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AggregationResult.ProtoReflect.Descriptor instead.
+func (*AggregationResult) Descriptor() ([]byte, []int) {
+	return file_search_v1_search_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *AggregationResult) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *AggregationResult) GetResult() isAggregationResult_Result {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+func (x *AggregationResult) GetTerms() *TermsResult {
+	if x != nil {
+		if x, ok := x.Result.(*AggregationResult_Terms); ok {
+			return x.Terms
+		}
+	}
+	return nil
+}
+
+func (x *AggregationResult) GetRange() *RangeResult {
+	if x != nil {
+		if x, ok := x.Result.(*AggregationResult_Range); ok {
+			return x.Range
+		}
+	}
+	return nil
+}
+
+func (x *AggregationResult) GetDateHistogram() *DateHistogramResult {
+	if x != nil {
+		if x, ok := x.Result.(*AggregationResult_DateHistogram); ok {
+			return x.DateHistogram
+		}
+	}
+	return nil
+}
+
+func (x *AggregationResult) GetMetric() *MetricResult {
+	if x != nil {
+		if x, ok := x.Result.(*AggregationResult_Metric); ok {
+			return x.Metric
+		}
+	}
+	return nil
+}
+
+type isAggregationResult_Result interface {
+	isAggregationResult_Result()
+}
+
+type AggregationResult_Terms struct {
+	Terms *TermsResult `protobuf:"bytes,2,opt,name=terms,proto3,oneof"`
+}
+
+type AggregationResult_Range struct {
+	Range *RangeResult `protobuf:"bytes,3,opt,name=range,proto3,oneof"`
+}
+
+type AggregationResult_DateHistogram struct {
+	DateHistogram *DateHistogramResult `protobuf:"bytes,4,opt,name=date_histogram,json=dateHistogram,proto3,oneof"`
+}
+
+type AggregationResult_Metric struct {
+	Metric *MetricResult `protobuf:"bytes,5,opt,name=metric,proto3,oneof"`
+}
+
+func (*AggregationResult_Terms) isAggregationResult_Result() {}
+
+func (*AggregationResult_Range) isAggregationResult_Result() {}
+
+func (*AggregationResult_DateHistogram) isAggregationResult_Result() {}
+
+func (*AggregationResult_Metric) isAggregationResult_Result() {}
+
+type TermsResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Buckets       []*TermsBucket         `protobuf:"bytes,1,rep,name=buckets,proto3" json:"buckets,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *SearchRequest) Reset() {
-	*x = SearchRequest{}
-	mi := &file_search_v1_search_proto_msgTypes[0]
+func (x *TermsResult) Reset() {
+	*x = TermsResult{}
+	mi := &file_search_v1_search_proto_msgTypes[24]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *SearchRequest) String() string {
+func (x *TermsResult) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SearchRequest) ProtoMessage() {}
+func (*TermsResult) ProtoMessage() {}
 
-func (x *SearchRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_search_v1_search_proto_msgTypes[0]
+func (x *TermsResult) ProtoReflect() protoreflect.Message {
+	mi := &file_search_v1_search_proto_msgTypes[24]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -113,85 +2117,139 @@ func (x *SearchRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use SearchRequest.ProtoReflect.Descriptor instead.
-func (*SearchRequest) Descriptor() ([]byte, []int) {
-	return file_search_v1_search_proto_rawDescGZIP(), []int{0}
+// Deprecated: Use TermsResult.ProtoReflect.Descriptor instead.
+func (*TermsResult) Descriptor() ([]byte, []int) {
+	return file_search_v1_search_proto_rawDescGZIP(), []int{24}
 }
 
-func (x *SearchRequest) GetResource() string {
+func (x *TermsResult) GetBuckets() []*TermsBucket {
 	if x != nil {
-		return x.Resource
+		return x.Buckets
 	}
-	return ""
+	return nil
 }
 
-func (x *SearchRequest) GetQuery() string {
-	if x != nil {
-		return x.Query
-	}
-	return ""
+type TermsBucket struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	DocCount      int64                  `protobuf:"varint,2,opt,name=doc_count,json=docCount,proto3" json:"doc_count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *SearchRequest) GetFilters() []*Filter {
+func (x *TermsBucket) Reset() {
+	*x = TermsBucket{}
+	mi := &file_search_v1_search_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TermsBucket) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TermsBucket) ProtoMessage() {}
+
+func (x *TermsBucket) ProtoReflect() protoreflect.Message {
+	mi := &file_search_v1_search_proto_msgTypes[25]
 	if x != nil {
-		return x.Filters
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return nil
+	return mi.MessageOf(x)
 }
 
-func (x *SearchRequest) GetPage() int32 {
+// Deprecated: Use TermsBucket.ProtoReflect.Descriptor instead.
+func (*TermsBucket) Descriptor() ([]byte, []int) {
+	return file_search_v1_search_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *TermsBucket) GetKey() string {
 	if x != nil {
-		return x.Page
+		return x.Key
 	}
-	return 0
+	return ""
 }
 
-func (x *SearchRequest) GetPageSize() int32 {
+func (x *TermsBucket) GetDocCount() int64 {
 	if x != nil {
-		return x.PageSize
+		return x.DocCount
 	}
 	return 0
 }
 
-func (x *SearchRequest) GetSort() []*Sort {
+type RangeResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Buckets       []*RangeBucket         `protobuf:"bytes,1,rep,name=buckets,proto3" json:"buckets,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RangeResult) Reset() {
+	*x = RangeResult{}
+	mi := &file_search_v1_search_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RangeResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RangeResult) ProtoMessage() {}
+
+func (x *RangeResult) ProtoReflect() protoreflect.Message {
+	mi := &file_search_v1_search_proto_msgTypes[26]
 	if x != nil {
-		return x.Sort
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return nil
+	return mi.MessageOf(x)
 }
 
-func (x *SearchRequest) GetIncludeSource() bool {
+// Deprecated: Use RangeResult.ProtoReflect.Descriptor instead.
+func (*RangeResult) Descriptor() ([]byte, []int) {
+	return file_search_v1_search_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *RangeResult) GetBuckets() []*RangeBucket {
 	if x != nil {
-		return x.IncludeSource
+		return x.Buckets
 	}
-	return false
+	return nil
 }
 
-type SearchHit struct {
-	state protoimpl.MessageState `protogen:"open.v1"`
-	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	Score float64                `protobuf:"fixed64,2,opt,name=score,proto3" json:"score,omitempty"`
-	// The indexed document (source)
-	Source        *structpb.Struct `protobuf:"bytes,3,opt,name=source,proto3" json:"source,omitempty"`
+type RangeBucket struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	From          *float64               `protobuf:"fixed64,2,opt,name=from,proto3,oneof" json:"from,omitempty"`
+	To            *float64               `protobuf:"fixed64,3,opt,name=to,proto3,oneof" json:"to,omitempty"`
+	DocCount      int64                  `protobuf:"varint,4,opt,name=doc_count,json=docCount,proto3" json:"doc_count,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *SearchHit) Reset() {
-	*x = SearchHit{}
-	mi := &file_search_v1_search_proto_msgTypes[1]
+func (x *RangeBucket) Reset() {
+	*x = RangeBucket{}
+	mi := &file_search_v1_search_proto_msgTypes[27]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *SearchHit) String() string {
+func (x *RangeBucket) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SearchHit) ProtoMessage() {}
+func (*RangeBucket) ProtoMessage() {}
 
-func (x *SearchHit) ProtoReflect() protoreflect.Message {
-	mi := &file_search_v1_search_proto_msgTypes[1]
+func (x *RangeBucket) ProtoReflect() protoreflect.Message {
+	mi := &file_search_v1_search_proto_msgTypes[27]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -202,55 +2260,61 @@ func (x *SearchHit) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use SearchHit.ProtoReflect.Descriptor instead.
-func (*SearchHit) Descriptor() ([]byte, []int) {
-	return file_search_v1_search_proto_rawDescGZIP(), []int{1}
+// Deprecated: Use RangeBucket.ProtoReflect.Descriptor instead.
+func (*RangeBucket) Descriptor() ([]byte, []int) {
+	return file_search_v1_search_proto_rawDescGZIP(), []int{27}
 }
 
-func (x *SearchHit) GetId() string {
+func (x *RangeBucket) GetKey() string {
 	if x != nil {
-		return x.Id
+		return x.Key
 	}
 	return ""
 }
 
-func (x *SearchHit) GetScore() float64 {
-	if x != nil {
-		return x.Score
+func (x *RangeBucket) GetFrom() float64 {
+	if x != nil && x.From != nil {
+		return *x.From
 	}
 	return 0
 }
 
-func (x *SearchHit) GetSource() *structpb.Struct {
+func (x *RangeBucket) GetTo() float64 {
+	if x != nil && x.To != nil {
+		return *x.To
+	}
+	return 0
+}
+
+func (x *RangeBucket) GetDocCount() int64 {
 	if x != nil {
-		return x.Source
+		return x.DocCount
 	}
-	return nil
+	return 0
 }
 
-type SearchResponse struct {
+type DateHistogramResult struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Total         int64                  `protobuf:"varint,1,opt,name=total,proto3" json:"total,omitempty"`
-	Hits          []*SearchHit           `protobuf:"bytes,2,rep,name=hits,proto3" json:"hits,omitempty"`
+	Buckets       []*DateHistogramBucket `protobuf:"bytes,1,rep,name=buckets,proto3" json:"buckets,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *SearchResponse) Reset() {
-	*x = SearchResponse{}
-	mi := &file_search_v1_search_proto_msgTypes[2]
+func (x *DateHistogramResult) Reset() {
+	*x = DateHistogramResult{}
+	mi := &file_search_v1_search_proto_msgTypes[28]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *SearchResponse) String() string {
+func (x *DateHistogramResult) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SearchResponse) ProtoMessage() {}
+func (*DateHistogramResult) ProtoMessage() {}
 
-func (x *SearchResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_search_v1_search_proto_msgTypes[2]
+func (x *DateHistogramResult) ProtoReflect() protoreflect.Message {
+	mi := &file_search_v1_search_proto_msgTypes[28]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -261,59 +2325,44 @@ func (x *SearchResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use SearchResponse.ProtoReflect.Descriptor instead.
-func (*SearchResponse) Descriptor() ([]byte, []int) {
-	return file_search_v1_search_proto_rawDescGZIP(), []int{2}
-}
-
-func (x *SearchResponse) GetTotal() int64 {
-	if x != nil {
-		return x.Total
-	}
-	return 0
+// Deprecated: Use DateHistogramResult.ProtoReflect.Descriptor instead.
+func (*DateHistogramResult) Descriptor() ([]byte, []int) {
+	return file_search_v1_search_proto_rawDescGZIP(), []int{28}
 }
 
-func (x *SearchResponse) GetHits() []*SearchHit {
+func (x *DateHistogramResult) GetBuckets() []*DateHistogramBucket {
 	if x != nil {
-		return x.Hits
+		return x.Buckets
 	}
 	return nil
 }
 
-type Filter struct {
+type DateHistogramBucket struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
-	// Example: "b.name.keyword" or "a_status" or "c.state"
-	Field string   `protobuf:"bytes,1,opt,name=field,proto3" json:"field,omitempty"`
-	Op    FilterOp `protobuf:"varint,2,opt,name=op,proto3,enum=search.v1.FilterOp" json:"op,omitempty"`
-	// For EQ
-	Value string `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`
-	// For IN
-	Values []string `protobuf:"bytes,4,rep,name=values,proto3" json:"values,omitempty"`
-	// If you need nested filtering (e.g. c is mapped as "nested"):
-	// nested_path="c", field="c.state", op=EQ, value="active"
-	// TODO: Can it be more ergonomic to specify nested filters? Maybe a separate
-	// message for nested filters? Or calculate on the fly based on the resource
-	// configured
-	NestedPath    string `protobuf:"bytes,5,opt,name=nested_path,json=nestedPath,proto3" json:"nested_path,omitempty"`
+	// The formatted bucket key.
+	Key string `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	// The bucket start in milliseconds since the epoch.
+	Timestamp     int64 `protobuf:"varint,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	DocCount      int64 `protobuf:"varint,3,opt,name=doc_count,json=docCount,proto3" json:"doc_count,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *Filter) Reset() {
-	*x = Filter{}
-	mi := &file_search_v1_search_proto_msgTypes[3]
+func (x *DateHistogramBucket) Reset() {
+	*x = DateHistogramBucket{}
+	mi := &file_search_v1_search_proto_msgTypes[29]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *Filter) String() string {
+func (x *DateHistogramBucket) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Filter) ProtoMessage() {}
+func (*DateHistogramBucket) ProtoMessage() {}
 
-func (x *Filter) ProtoReflect() protoreflect.Message {
-	mi := &file_search_v1_search_proto_msgTypes[3]
+func (x *DateHistogramBucket) ProtoReflect() protoreflect.Message {
+	mi := &file_search_v1_search_proto_msgTypes[29]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -324,70 +2373,55 @@ func (x *Filter) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Filter.ProtoReflect.Descriptor instead.
-func (*Filter) Descriptor() ([]byte, []int) {
-	return file_search_v1_search_proto_rawDescGZIP(), []int{3}
-}
-
-func (x *Filter) GetField() string {
-	if x != nil {
-		return x.Field
-	}
-	return ""
-}
-
-func (x *Filter) GetOp() FilterOp {
-	if x != nil {
-		return x.Op
-	}
-	return FilterOp_FILTER_OP_UNSPECIFIED
+// Deprecated: Use DateHistogramBucket.ProtoReflect.Descriptor instead.
+func (*DateHistogramBucket) Descriptor() ([]byte, []int) {
+	return file_search_v1_search_proto_rawDescGZIP(), []int{29}
 }
 
-func (x *Filter) GetValue() string {
+func (x *DateHistogramBucket) GetKey() string {
 	if x != nil {
-		return x.Value
+		return x.Key
 	}
 	return ""
 }
 
-func (x *Filter) GetValues() []string {
+func (x *DateHistogramBucket) GetTimestamp() int64 {
 	if x != nil {
-		return x.Values
+		return x.Timestamp
 	}
-	return nil
+	return 0
 }
 
-func (x *Filter) GetNestedPath() string {
+func (x *DateHistogramBucket) GetDocCount() int64 {
 	if x != nil {
-		return x.NestedPath
+		return x.DocCount
 	}
-	return ""
+	return 0
 }
 
-type Sort struct {
+type MetricResult struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
-	// Example: "updated_at"
-	Field         string `protobuf:"bytes,1,opt,name=field,proto3" json:"field,omitempty"`
-	Desc          bool   `protobuf:"varint,2,opt,name=desc,proto3" json:"desc,omitempty"`
+	// Unset when no document has a value for the field.
+	Value         *float64 `protobuf:"fixed64,1,opt,name=value,proto3,oneof" json:"value,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *Sort) Reset() {
-	*x = Sort{}
-	mi := &file_search_v1_search_proto_msgTypes[4]
+func (x *MetricResult) Reset() {
+	*x = MetricResult{}
+	mi := &file_search_v1_search_proto_msgTypes[30]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *Sort) String() string {
+func (x *MetricResult) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Sort) ProtoMessage() {}
+func (*MetricResult) ProtoMessage() {}
 
-func (x *Sort) ProtoReflect() protoreflect.Message {
-	mi := &file_search_v1_search_proto_msgTypes[4]
+func (x *MetricResult) ProtoReflect() protoreflect.Message {
+	mi := &file_search_v1_search_proto_msgTypes[30]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -398,23 +2432,16 @@ func (x *Sort) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Sort.ProtoReflect.Descriptor instead.
-func (*Sort) Descriptor() ([]byte, []int) {
-	return file_search_v1_search_proto_rawDescGZIP(), []int{4}
-}
-
-func (x *Sort) GetField() string {
-	if x != nil {
-		return x.Field
-	}
-	return ""
+// Deprecated: Use MetricResult.ProtoReflect.Descriptor instead.
+func (*MetricResult) Descriptor() ([]byte, []int) {
+	return file_search_v1_search_proto_rawDescGZIP(), []int{30}
 }
 
-func (x *Sort) GetDesc() bool {
-	if x != nil {
-		return x.Desc
+func (x *MetricResult) GetValue() float64 {
+	if x != nil && x.Value != nil {
+		return *x.Value
 	}
-	return false
+	return 0
 }
 
 type GetCapabilitiesRequest struct {
@@ -425,7 +2452,7 @@ type GetCapabilitiesRequest struct {
 
 func (x *GetCapabilitiesRequest) Reset() {
 	*x = GetCapabilitiesRequest{}
-	mi := &file_search_v1_search_proto_msgTypes[5]
+	mi := &file_search_v1_search_proto_msgTypes[31]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -437,7 +2464,7 @@ func (x *GetCapabilitiesRequest) String() string {
 func (*GetCapabilitiesRequest) ProtoMessage() {}
 
 func (x *GetCapabilitiesRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_search_v1_search_proto_msgTypes[5]
+	mi := &file_search_v1_search_proto_msgTypes[31]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -450,7 +2477,7 @@ func (x *GetCapabilitiesRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetCapabilitiesRequest.ProtoReflect.Descriptor instead.
 func (*GetCapabilitiesRequest) Descriptor() ([]byte, []int) {
-	return file_search_v1_search_proto_rawDescGZIP(), []int{5}
+	return file_search_v1_search_proto_rawDescGZIP(), []int{31}
 }
 
 type GetCapabilitiesResponse struct {
@@ -462,7 +2489,7 @@ type GetCapabilitiesResponse struct {
 
 func (x *GetCapabilitiesResponse) Reset() {
 	*x = GetCapabilitiesResponse{}
-	mi := &file_search_v1_search_proto_msgTypes[6]
+	mi := &file_search_v1_search_proto_msgTypes[32]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -474,7 +2501,7 @@ func (x *GetCapabilitiesResponse) String() string {
 func (*GetCapabilitiesResponse) ProtoMessage() {}
 
 func (x *GetCapabilitiesResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_search_v1_search_proto_msgTypes[6]
+	mi := &file_search_v1_search_proto_msgTypes[32]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -487,7 +2514,7 @@ func (x *GetCapabilitiesResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetCapabilitiesResponse.ProtoReflect.Descriptor instead.
 func (*GetCapabilitiesResponse) Descriptor() ([]byte, []int) {
-	return file_search_v1_search_proto_rawDescGZIP(), []int{6}
+	return file_search_v1_search_proto_rawDescGZIP(), []int{32}
 }
 
 func (x *GetCapabilitiesResponse) GetResources() []*ResourceCapability {
@@ -507,7 +2534,7 @@ type ResourceCapability struct {
 
 func (x *ResourceCapability) Reset() {
 	*x = ResourceCapability{}
-	mi := &file_search_v1_search_proto_msgTypes[7]
+	mi := &file_search_v1_search_proto_msgTypes[33]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -519,7 +2546,7 @@ func (x *ResourceCapability) String() string {
 func (*ResourceCapability) ProtoMessage() {}
 
 func (x *ResourceCapability) ProtoReflect() protoreflect.Message {
-	mi := &file_search_v1_search_proto_msgTypes[7]
+	mi := &file_search_v1_search_proto_msgTypes[33]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -532,7 +2559,7 @@ func (x *ResourceCapability) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ResourceCapability.ProtoReflect.Descriptor instead.
 func (*ResourceCapability) Descriptor() ([]byte, []int) {
-	return file_search_v1_search_proto_rawDescGZIP(), []int{7}
+	return file_search_v1_search_proto_rawDescGZIP(), []int{33}
 }
 
 func (x *ResourceCapability) GetResource() string {
@@ -560,14 +2587,23 @@ type FieldCapability struct {
 	// Whether this field is included in full-text search queries
 	Searchable bool `protobuf:"varint,4,opt,name=searchable,proto3" json:"searchable,omitempty"`
 	// Whether this field can be used for sorting
-	Sortable      bool `protobuf:"varint,5,opt,name=sortable,proto3" json:"sortable,omitempty"`
+	Sortable bool `protobuf:"varint,5,opt,name=sortable,proto3" json:"sortable,omitempty"`
+	// Which aggregation kinds are supported on this field
+	Aggregations []AggregationKind `protobuf:"varint,6,rep,packed,name=aggregations,proto3,enum=search.v1.AggregationKind" json:"aggregations,omitempty"`
+	// Whether this field can be used with Suggest
+	Suggestable bool `protobuf:"varint,7,opt,name=suggestable,proto3" json:"suggestable,omitempty"`
+	// The nested path of the field, e.g. "c" for "c.name" if c is a
+	// cardinality "many" relation. Filters on the field are resolved to it
+	// automatically, and a nested filter group must use it to match several
+	// of the field's conditions on the same object. Empty if not nested.
+	NestedPath    string `protobuf:"bytes,8,opt,name=nested_path,json=nestedPath,proto3" json:"nested_path,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *FieldCapability) Reset() {
 	*x = FieldCapability{}
-	mi := &file_search_v1_search_proto_msgTypes[8]
+	mi := &file_search_v1_search_proto_msgTypes[34]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -579,7 +2615,7 @@ func (x *FieldCapability) String() string {
 func (*FieldCapability) ProtoMessage() {}
 
 func (x *FieldCapability) ProtoReflect() protoreflect.Message {
-	mi := &file_search_v1_search_proto_msgTypes[8]
+	mi := &file_search_v1_search_proto_msgTypes[34]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -592,7 +2628,7 @@ func (x *FieldCapability) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use FieldCapability.ProtoReflect.Descriptor instead.
 func (*FieldCapability) Descriptor() ([]byte, []int) {
-	return file_search_v1_search_proto_rawDescGZIP(), []int{8}
+	return file_search_v1_search_proto_rawDescGZIP(), []int{34}
 }
 
 func (x *FieldCapability) GetField() string {
@@ -630,11 +2666,32 @@ func (x *FieldCapability) GetSortable() bool {
 	return false
 }
 
+func (x *FieldCapability) GetAggregations() []AggregationKind {
+	if x != nil {
+		return x.Aggregations
+	}
+	return nil
+}
+
+func (x *FieldCapability) GetSuggestable() bool {
+	if x != nil {
+		return x.Suggestable
+	}
+	return false
+}
+
+func (x *FieldCapability) GetNestedPath() string {
+	if x != nil {
+		return x.NestedPath
+	}
+	return ""
+}
+
 var File_search_v1_search_proto protoreflect.FileDescriptor
 
 const file_search_v1_search_proto_rawDesc = "" +
 	"\n" +
-	"\x16search/v1/search.proto\x12\tsearch.v1\x1a\x1cgoogle/protobuf/struct.proto\"\xeb\x01\n" +
+	"\x16search/v1/search.proto\x12\tsearch.v1\x1a\x1cgoogle/protobuf/struct.proto\"\xf1\x03\n" +
 	"\rSearchRequest\x12\x1a\n" +
 	"\bresource\x18\x01 \x01(\tR\bresource\x12\x14\n" +
 	"\x05query\x18\x02 \x01(\tR\x05query\x12+\n" +
@@ -642,30 +2699,170 @@ const file_search_v1_search_proto_rawDesc = "" +
 	"\x04page\x18\x04 \x01(\x05R\x04page\x12\x1b\n" +
 	"\tpage_size\x18\x05 \x01(\x05R\bpageSize\x12#\n" +
 	"\x04sort\x18\x06 \x03(\v2\x0f.search.v1.SortR\x04sort\x12%\n" +
-	"\x0einclude_source\x18\a \x01(\bR\rincludeSource\"b\n" +
+	"\x0einclude_source\x18\a \x01(\bR\rincludeSource\x129\n" +
+	"\ffilter_group\x18\b \x01(\v2\x16.search.v1.FilterGroupR\vfilterGroup\x12:\n" +
+	"\faggregations\x18\t \x03(\v2\x16.search.v1.AggregationR\faggregations\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\n" +
+	" \x01(\tR\tpageToken\x12\"\n" +
+	"\rpoint_in_time\x18\v \x01(\bR\vpointInTime\x12\x16\n" +
+	"\x06fields\x18\f \x03(\tR\x06fields\x122\n" +
+	"\thighlight\x18\r \x01(\v2\x14.search.v1.HighlightR\thighlight\"\xc9\x01\n" +
+	"\tHighlight\x12\x16\n" +
+	"\x06fields\x18\x01 \x03(\tR\x06fields\x12#\n" +
+	"\rfragment_size\x18\x02 \x01(\x05R\ffragmentSize\x123\n" +
+	"\x13number_of_fragments\x18\x03 \x01(\x05H\x00R\x11numberOfFragments\x88\x01\x01\x12\x17\n" +
+	"\apre_tag\x18\x04 \x01(\tR\x06preTag\x12\x19\n" +
+	"\bpost_tag\x18\x05 \x01(\tR\apostTagB\x16\n" +
+	"\x14_number_of_fragments\"2\n" +
+	"\x12HighlightFragments\x12\x1c\n" +
+	"\tfragments\x18\x01 \x03(\tR\tfragments\"\xa2\x02\n" +
 	"\tSearchHit\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x14\n" +
 	"\x05score\x18\x02 \x01(\x01R\x05score\x12/\n" +
-	"\x06source\x18\x03 \x01(\v2\x17.google.protobuf.StructR\x06source\"P\n" +
+	"\x06source\x18\x03 \x01(\v2\x17.google.protobuf.StructR\x06source\x12D\n" +
+	"\n" +
+	"highlights\x18\x04 \x03(\v2$.search.v1.SearchHit.HighlightsEntryR\n" +
+	"highlights\x12\x1a\n" +
+	"\bresource\x18\x05 \x01(\tR\bresource\x1a\\\n" +
+	"\x0fHighlightsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x123\n" +
+	"\x05value\x18\x02 \x01(\v2\x1d.search.v1.HighlightFragmentsR\x05value:\x028\x01\"\xba\x01\n" +
 	"\x0eSearchResponse\x12\x14\n" +
 	"\x05total\x18\x01 \x01(\x03R\x05total\x12(\n" +
-	"\x04hits\x18\x02 \x03(\v2\x14.search.v1.SearchHitR\x04hits\"\x92\x01\n" +
+	"\x04hits\x18\x02 \x03(\v2\x14.search.v1.SearchHitR\x04hits\x12@\n" +
+	"\faggregations\x18\x03 \x03(\v2\x1c.search.v1.AggregationResultR\faggregations\x12&\n" +
+	"\x0fnext_page_token\x18\x04 \x01(\tR\rnextPageToken\"\xa3\x01\n" +
+	"\x12MultiSearchRequest\x12\x14\n" +
+	"\x05query\x18\x01 \x01(\tR\x05query\x12<\n" +
+	"\tresources\x18\x02 \x03(\v2\x1e.search.v1.MultiSearchResourceR\tresources\x12\x12\n" +
+	"\x04size\x18\x03 \x01(\x05R\x04size\x12%\n" +
+	"\x0einclude_source\x18\x04 \x01(\bR\rincludeSource\"\xc7\x01\n" +
+	"\x13MultiSearchResource\x12\x1a\n" +
+	"\bresource\x18\x01 \x01(\tR\bresource\x12\x16\n" +
+	"\x06weight\x18\x02 \x01(\x01R\x06weight\x12\x14\n" +
+	"\x05quota\x18\x03 \x01(\x05R\x05quota\x12+\n" +
+	"\afilters\x18\x04 \x03(\v2\x11.search.v1.FilterR\afilters\x129\n" +
+	"\ffilter_group\x18\x05 \x01(\v2\x16.search.v1.FilterGroupR\vfilterGroup\"\xbe\x01\n" +
+	"\x13MultiSearchResponse\x12(\n" +
+	"\x04hits\x18\x01 \x03(\v2\x14.search.v1.SearchHitR\x04hits\x12B\n" +
+	"\x06totals\x18\x02 \x03(\v2*.search.v1.MultiSearchResponse.TotalsEntryR\x06totals\x1a9\n" +
+	"\vTotalsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x03R\x05value:\x028\x01\"\x85\x02\n" +
+	"\rExportRequest\x12\x1a\n" +
+	"\bresource\x18\x01 \x01(\tR\bresource\x12\x14\n" +
+	"\x05query\x18\x02 \x01(\tR\x05query\x12+\n" +
+	"\afilters\x18\x03 \x03(\v2\x11.search.v1.FilterR\afilters\x129\n" +
+	"\ffilter_group\x18\x04 \x01(\v2\x16.search.v1.FilterGroupR\vfilterGroup\x12#\n" +
+	"\x04sort\x18\x05 \x03(\v2\x0f.search.v1.SortR\x04sort\x12\x16\n" +
+	"\x06fields\x18\x06 \x03(\tR\x06fields\x12\x1d\n" +
+	"\n" +
+	"batch_size\x18\a \x01(\x05R\tbatchSize\":\n" +
+	"\x0eExportResponse\x12(\n" +
+	"\x04hits\x18\x01 \x03(\v2\x14.search.v1.SearchHitR\x04hits\"\xd8\x01\n" +
+	"\x0eSuggestRequest\x12\x1a\n" +
+	"\bresource\x18\x01 \x01(\tR\bresource\x12\x16\n" +
+	"\x06prefix\x18\x02 \x01(\tR\x06prefix\x12\x16\n" +
+	"\x06fields\x18\x03 \x03(\tR\x06fields\x12+\n" +
+	"\afilters\x18\x04 \x03(\v2\x11.search.v1.FilterR\afilters\x129\n" +
+	"\ffilter_group\x18\x05 \x01(\v2\x16.search.v1.FilterGroupR\vfilterGroup\x12\x12\n" +
+	"\x04size\x18\x06 \x01(\x05R\x04size\"\\\n" +
+	"\n" +
+	"Suggestion\x12\x12\n" +
+	"\x04text\x18\x01 \x01(\tR\x04text\x12\x14\n" +
+	"\x05field\x18\x02 \x01(\tR\x05field\x12\x0e\n" +
+	"\x02id\x18\x03 \x01(\tR\x02id\x12\x14\n" +
+	"\x05score\x18\x04 \x01(\x01R\x05score\"J\n" +
+	"\x0fSuggestResponse\x127\n" +
+	"\vsuggestions\x18\x01 \x03(\v2\x15.search.v1.SuggestionR\vsuggestions\"\xc0\x01\n" +
 	"\x06Filter\x12\x14\n" +
 	"\x05field\x18\x01 \x01(\tR\x05field\x12#\n" +
 	"\x02op\x18\x02 \x01(\x0e2\x13.search.v1.FilterOpR\x02op\x12\x14\n" +
 	"\x05value\x18\x03 \x01(\tR\x05value\x12\x16\n" +
 	"\x06values\x18\x04 \x03(\tR\x06values\x12\x1f\n" +
 	"\vnested_path\x18\x05 \x01(\tR\n" +
+	"nestedPath\x12,\n" +
+	"\x05range\x18\x06 \x01(\v2\x16.search.v1.RangeBoundsR\x05range\"Q\n" +
+	"\vRangeBounds\x12\x0e\n" +
+	"\x02gt\x18\x01 \x01(\tR\x02gt\x12\x10\n" +
+	"\x03gte\x18\x02 \x01(\tR\x03gte\x12\x0e\n" +
+	"\x02lt\x18\x03 \x01(\tR\x02lt\x12\x10\n" +
+	"\x03lte\x18\x04 \x01(\tR\x03lte\"\xaf\x01\n" +
+	"\vFilterGroup\x12\"\n" +
+	"\x02op\x18\x01 \x01(\x0e2\x12.search.v1.GroupOpR\x02op\x12+\n" +
+	"\afilters\x18\x02 \x03(\v2\x11.search.v1.FilterR\afilters\x12.\n" +
+	"\x06groups\x18\x03 \x03(\v2\x16.search.v1.FilterGroupR\x06groups\x12\x1f\n" +
+	"\vnested_path\x18\x04 \x01(\tR\n" +
 	"nestedPath\"0\n" +
 	"\x04Sort\x12\x14\n" +
 	"\x05field\x18\x01 \x01(\tR\x05field\x12\x12\n" +
-	"\x04desc\x18\x02 \x01(\bR\x04desc\"\x18\n" +
+	"\x04desc\x18\x02 \x01(\bR\x04desc\"\xba\x02\n" +
+	"\vAggregation\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x123\n" +
+	"\x05terms\x18\x02 \x01(\v2\x1b.search.v1.TermsAggregationH\x00R\x05terms\x123\n" +
+	"\x05range\x18\x03 \x01(\v2\x1b.search.v1.RangeAggregationH\x00R\x05range\x12L\n" +
+	"\x0edate_histogram\x18\x04 \x01(\v2#.search.v1.DateHistogramAggregationH\x00R\rdateHistogram\x126\n" +
+	"\x06metric\x18\x05 \x01(\v2\x1c.search.v1.MetricAggregationH\x00R\x06metric\x12\x1f\n" +
+	"\vnested_path\x18\x06 \x01(\tR\n" +
+	"nestedPathB\x06\n" +
+	"\x04kind\"<\n" +
+	"\x10TermsAggregation\x12\x14\n" +
+	"\x05field\x18\x01 \x01(\tR\x05field\x12\x12\n" +
+	"\x04size\x18\x02 \x01(\x05R\x04size\"]\n" +
+	"\x10RangeAggregation\x12\x14\n" +
+	"\x05field\x18\x01 \x01(\tR\x05field\x123\n" +
+	"\x06ranges\x18\x02 \x03(\v2\x1b.search.v1.AggregationRangeR\x06ranges\"b\n" +
+	"\x10AggregationRange\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x17\n" +
+	"\x04from\x18\x02 \x01(\x01H\x00R\x04from\x88\x01\x01\x12\x13\n" +
+	"\x02to\x18\x03 \x01(\x01H\x01R\x02to\x88\x01\x01B\a\n" +
+	"\x05_fromB\x05\n" +
+	"\x03_to\"\x9c\x01\n" +
+	"\x18DateHistogramAggregation\x12\x14\n" +
+	"\x05field\x18\x01 \x01(\tR\x05field\x12+\n" +
+	"\x11calendar_interval\x18\x02 \x01(\tR\x10calendarInterval\x12%\n" +
+	"\x0efixed_interval\x18\x03 \x01(\tR\rfixedInterval\x12\x16\n" +
+	"\x06format\x18\x04 \x01(\tR\x06format\"T\n" +
+	"\x11MetricAggregation\x12\x14\n" +
+	"\x05field\x18\x01 \x01(\tR\x05field\x12)\n" +
+	"\x04type\x18\x02 \x01(\x0e2\x15.search.v1.MetricTypeR\x04type\"\x8d\x02\n" +
+	"\x11AggregationResult\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12.\n" +
+	"\x05terms\x18\x02 \x01(\v2\x16.search.v1.TermsResultH\x00R\x05terms\x12.\n" +
+	"\x05range\x18\x03 \x01(\v2\x16.search.v1.RangeResultH\x00R\x05range\x12G\n" +
+	"\x0edate_histogram\x18\x04 \x01(\v2\x1e.search.v1.DateHistogramResultH\x00R\rdateHistogram\x121\n" +
+	"\x06metric\x18\x05 \x01(\v2\x17.search.v1.MetricResultH\x00R\x06metricB\b\n" +
+	"\x06result\"?\n" +
+	"\vTermsResult\x120\n" +
+	"\abuckets\x18\x01 \x03(\v2\x16.search.v1.TermsBucketR\abuckets\"<\n" +
+	"\vTermsBucket\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x1b\n" +
+	"\tdoc_count\x18\x02 \x01(\x03R\bdocCount\"?\n" +
+	"\vRangeResult\x120\n" +
+	"\abuckets\x18\x01 \x03(\v2\x16.search.v1.RangeBucketR\abuckets\"z\n" +
+	"\vRangeBucket\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x17\n" +
+	"\x04from\x18\x02 \x01(\x01H\x00R\x04from\x88\x01\x01\x12\x13\n" +
+	"\x02to\x18\x03 \x01(\x01H\x01R\x02to\x88\x01\x01\x12\x1b\n" +
+	"\tdoc_count\x18\x04 \x01(\x03R\bdocCountB\a\n" +
+	"\x05_fromB\x05\n" +
+	"\x03_to\"O\n" +
+	"\x13DateHistogramResult\x128\n" +
+	"\abuckets\x18\x01 \x03(\v2\x1e.search.v1.DateHistogramBucketR\abuckets\"b\n" +
+	"\x13DateHistogramBucket\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x1c\n" +
+	"\ttimestamp\x18\x02 \x01(\x03R\ttimestamp\x12\x1b\n" +
+	"\tdoc_count\x18\x03 \x01(\x03R\bdocCount\"3\n" +
+	"\fMetricResult\x12\x19\n" +
+	"\x05value\x18\x01 \x01(\x01H\x00R\x05value\x88\x01\x01B\b\n" +
+	"\x06_value\"\x18\n" +
 	"\x16GetCapabilitiesRequest\"V\n" +
 	"\x17GetCapabilitiesResponse\x12;\n" +
 	"\tresources\x18\x01 \x03(\v2\x1d.search.v1.ResourceCapabilityR\tresources\"d\n" +
 	"\x12ResourceCapability\x12\x1a\n" +
 	"\bresource\x18\x01 \x01(\tR\bresource\x122\n" +
-	"\x06fields\x18\x02 \x03(\v2\x1a.search.v1.FieldCapabilityR\x06fields\"\xab\x01\n" +
+	"\x06fields\x18\x02 \x03(\v2\x1a.search.v1.FieldCapabilityR\x06fields\"\xae\x02\n" +
 	"\x0fFieldCapability\x12\x14\n" +
 	"\x05field\x18\x01 \x01(\tR\x05field\x12\x12\n" +
 	"\x04type\x18\x02 \x01(\tR\x04type\x122\n" +
@@ -674,14 +2871,45 @@ const file_search_v1_search_proto_rawDesc = "" +
 	"\n" +
 	"searchable\x18\x04 \x01(\bR\n" +
 	"searchable\x12\x1a\n" +
-	"\bsortable\x18\x05 \x01(\bR\bsortable*I\n" +
+	"\bsortable\x18\x05 \x01(\bR\bsortable\x12>\n" +
+	"\faggregations\x18\x06 \x03(\x0e2\x1a.search.v1.AggregationKindR\faggregations\x12 \n" +
+	"\vsuggestable\x18\a \x01(\bR\vsuggestable\x12\x1f\n" +
+	"\vnested_path\x18\b \x01(\tR\n" +
+	"nestedPath*\xce\x01\n" +
 	"\bFilterOp\x12\x19\n" +
 	"\x15FILTER_OP_UNSPECIFIED\x10\x00\x12\x10\n" +
 	"\fFILTER_OP_EQ\x10\x01\x12\x10\n" +
-	"\fFILTER_OP_IN\x10\x022\xa8\x01\n" +
+	"\fFILTER_OP_IN\x10\x02\x12\x13\n" +
+	"\x0fFILTER_OP_RANGE\x10\x03\x12\x14\n" +
+	"\x10FILTER_OP_EXISTS\x10\x04\x12\x14\n" +
+	"\x10FILTER_OP_PREFIX\x10\x05\x12\x16\n" +
+	"\x12FILTER_OP_WILDCARD\x10\x06\x12\x14\n" +
+	"\x10FILTER_OP_NOT_EQ\x10\a\x12\x14\n" +
+	"\x10FILTER_OP_NOT_IN\x10\b*X\n" +
+	"\aGroupOp\x12\x18\n" +
+	"\x14GROUP_OP_UNSPECIFIED\x10\x00\x12\x10\n" +
+	"\fGROUP_OP_AND\x10\x01\x12\x0f\n" +
+	"\vGROUP_OP_OR\x10\x02\x12\x10\n" +
+	"\fGROUP_OP_NOT\x10\x03*}\n" +
+	"\n" +
+	"MetricType\x12\x1b\n" +
+	"\x17METRIC_TYPE_UNSPECIFIED\x10\x00\x12\x13\n" +
+	"\x0fMETRIC_TYPE_MIN\x10\x01\x12\x13\n" +
+	"\x0fMETRIC_TYPE_MAX\x10\x02\x12\x13\n" +
+	"\x0fMETRIC_TYPE_AVG\x10\x03\x12\x13\n" +
+	"\x0fMETRIC_TYPE_SUM\x10\x04*\xad\x01\n" +
+	"\x0fAggregationKind\x12 \n" +
+	"\x1cAGGREGATION_KIND_UNSPECIFIED\x10\x00\x12\x1a\n" +
+	"\x16AGGREGATION_KIND_TERMS\x10\x01\x12\x1a\n" +
+	"\x16AGGREGATION_KIND_RANGE\x10\x02\x12#\n" +
+	"\x1fAGGREGATION_KIND_DATE_HISTOGRAM\x10\x03\x12\x1b\n" +
+	"\x17AGGREGATION_KIND_METRIC\x10\x042\xf9\x02\n" +
 	"\rSearchService\x12=\n" +
 	"\x06Search\x12\x18.search.v1.SearchRequest\x1a\x19.search.v1.SearchResponse\x12X\n" +
-	"\x0fGetCapabilities\x12!.search.v1.GetCapabilitiesRequest\x1a\".search.v1.GetCapabilitiesResponseB\x81\x01\n" +
+	"\x0fGetCapabilities\x12!.search.v1.GetCapabilitiesRequest\x1a\".search.v1.GetCapabilitiesResponse\x12?\n" +
+	"\x06Export\x12\x18.search.v1.ExportRequest\x1a\x19.search.v1.ExportResponse0\x01\x12@\n" +
+	"\aSuggest\x12\x19.search.v1.SuggestRequest\x1a\x1a.search.v1.SuggestResponse\x12L\n" +
+	"\vMultiSearch\x12\x1d.search.v1.MultiSearchRequest\x1a\x1e.search.v1.MultiSearchResponseB\x81\x01\n" +
 	"\rcom.search.v1B\vSearchProtoP\x01Z\x1eindexer/gen/searcher/v1;search\xa2\x02\x03SXX\xaa\x02\tSearch.V1\xca\x02\tSearch\\V1\xe2\x02\x15Search\\V1\\GPBMetadata\xea\x02\n" +
 	"Search::V1b\x06proto3"
 
@@ -697,39 +2925,112 @@ func file_search_v1_search_proto_rawDescGZIP() []byte {
 	return file_search_v1_search_proto_rawDescData
 }
 
-var file_search_v1_search_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_search_v1_search_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_search_v1_search_proto_enumTypes = make([]protoimpl.EnumInfo, 4)
+var file_search_v1_search_proto_msgTypes = make([]protoimpl.MessageInfo, 37)
 var file_search_v1_search_proto_goTypes = []any{
-	(FilterOp)(0),                   // 0: search.v1.FilterOp
-	(*SearchRequest)(nil),           // 1: search.v1.SearchRequest
-	(*SearchHit)(nil),               // 2: search.v1.SearchHit
-	(*SearchResponse)(nil),          // 3: search.v1.SearchResponse
-	(*Filter)(nil),                  // 4: search.v1.Filter
-	(*Sort)(nil),                    // 5: search.v1.Sort
-	(*GetCapabilitiesRequest)(nil),  // 6: search.v1.GetCapabilitiesRequest
-	(*GetCapabilitiesResponse)(nil), // 7: search.v1.GetCapabilitiesResponse
-	(*ResourceCapability)(nil),      // 8: search.v1.ResourceCapability
-	(*FieldCapability)(nil),         // 9: search.v1.FieldCapability
-	(*structpb.Struct)(nil),         // 10: google.protobuf.Struct
+	(FilterOp)(0),                    // 0: search.v1.FilterOp
+	(GroupOp)(0),                     // 1: search.v1.GroupOp
+	(MetricType)(0),                  // 2: search.v1.MetricType
+	(AggregationKind)(0),             // 3: search.v1.AggregationKind
+	(*SearchRequest)(nil),            // 4: search.v1.SearchRequest
+	(*Highlight)(nil),                // 5: search.v1.Highlight
+	(*HighlightFragments)(nil),       // 6: search.v1.HighlightFragments
+	(*SearchHit)(nil),                // 7: search.v1.SearchHit
+	(*SearchResponse)(nil),           // 8: search.v1.SearchResponse
+	(*MultiSearchRequest)(nil),       // 9: search.v1.MultiSearchRequest
+	(*MultiSearchResource)(nil),      // 10: search.v1.MultiSearchResource
+	(*MultiSearchResponse)(nil),      // 11: search.v1.MultiSearchResponse
+	(*ExportRequest)(nil),            // 12: search.v1.ExportRequest
+	(*ExportResponse)(nil),           // 13: search.v1.ExportResponse
+	(*SuggestRequest)(nil),           // 14: search.v1.SuggestRequest
+	(*Suggestion)(nil),               // 15: search.v1.Suggestion
+	(*SuggestResponse)(nil),          // 16: search.v1.SuggestResponse
+	(*Filter)(nil),                   // 17: search.v1.Filter
+	(*RangeBounds)(nil),              // 18: search.v1.RangeBounds
+	(*FilterGroup)(nil),              // 19: search.v1.FilterGroup
+	(*Sort)(nil),                     // 20: search.v1.Sort
+	(*Aggregation)(nil),              // 21: search.v1.Aggregation
+	(*TermsAggregation)(nil),         // 22: search.v1.TermsAggregation
+	(*RangeAggregation)(nil),         // 23: search.v1.RangeAggregation
+	(*AggregationRange)(nil),         // 24: search.v1.AggregationRange
+	(*DateHistogramAggregation)(nil), // 25: search.v1.DateHistogramAggregation
+	(*MetricAggregation)(nil),        // 26: search.v1.MetricAggregation
+	(*AggregationResult)(nil),        // 27: search.v1.AggregationResult
+	(*TermsResult)(nil),              // 28: search.v1.TermsResult
+	(*TermsBucket)(nil),              // 29: search.v1.TermsBucket
+	(*RangeResult)(nil),              // 30: search.v1.RangeResult
+	(*RangeBucket)(nil),              // 31: search.v1.RangeBucket
+	(*DateHistogramResult)(nil),      // 32: search.v1.DateHistogramResult
+	(*DateHistogramBucket)(nil),      // 33: search.v1.DateHistogramBucket
+	(*MetricResult)(nil),             // 34: search.v1.MetricResult
+	(*GetCapabilitiesRequest)(nil),   // 35: search.v1.GetCapabilitiesRequest
+	(*GetCapabilitiesResponse)(nil),  // 36: search.v1.GetCapabilitiesResponse
+	(*ResourceCapability)(nil),       // 37: search.v1.ResourceCapability
+	(*FieldCapability)(nil),          // 38: search.v1.FieldCapability
+	nil,                              // 39: search.v1.SearchHit.HighlightsEntry
+	nil,                              // 40: search.v1.MultiSearchResponse.TotalsEntry
+	(*structpb.Struct)(nil),          // 41: google.protobuf.Struct
 }
 var file_search_v1_search_proto_depIdxs = []int32{
-	4,  // 0: search.v1.SearchRequest.filters:type_name -> search.v1.Filter
-	5,  // 1: search.v1.SearchRequest.sort:type_name -> search.v1.Sort
-	10, // 2: search.v1.SearchHit.source:type_name -> google.protobuf.Struct
-	2,  // 3: search.v1.SearchResponse.hits:type_name -> search.v1.SearchHit
-	0,  // 4: search.v1.Filter.op:type_name -> search.v1.FilterOp
-	8,  // 5: search.v1.GetCapabilitiesResponse.resources:type_name -> search.v1.ResourceCapability
-	9,  // 6: search.v1.ResourceCapability.fields:type_name -> search.v1.FieldCapability
-	0,  // 7: search.v1.FieldCapability.filter_ops:type_name -> search.v1.FilterOp
-	1,  // 8: search.v1.SearchService.Search:input_type -> search.v1.SearchRequest
-	6,  // 9: search.v1.SearchService.GetCapabilities:input_type -> search.v1.GetCapabilitiesRequest
-	3,  // 10: search.v1.SearchService.Search:output_type -> search.v1.SearchResponse
-	7,  // 11: search.v1.SearchService.GetCapabilities:output_type -> search.v1.GetCapabilitiesResponse
-	10, // [10:12] is the sub-list for method output_type
-	8,  // [8:10] is the sub-list for method input_type
-	8,  // [8:8] is the sub-list for extension type_name
-	8,  // [8:8] is the sub-list for extension extendee
-	0,  // [0:8] is the sub-list for field type_name
+	17, // 0: search.v1.SearchRequest.filters:type_name -> search.v1.Filter
+	20, // 1: search.v1.SearchRequest.sort:type_name -> search.v1.Sort
+	19, // 2: search.v1.SearchRequest.filter_group:type_name -> search.v1.FilterGroup
+	21, // 3: search.v1.SearchRequest.aggregations:type_name -> search.v1.Aggregation
+	5,  // 4: search.v1.SearchRequest.highlight:type_name -> search.v1.Highlight
+	41, // 5: search.v1.SearchHit.source:type_name -> google.protobuf.Struct
+	39, // 6: search.v1.SearchHit.highlights:type_name -> search.v1.SearchHit.HighlightsEntry
+	7,  // 7: search.v1.SearchResponse.hits:type_name -> search.v1.SearchHit
+	27, // 8: search.v1.SearchResponse.aggregations:type_name -> search.v1.AggregationResult
+	10, // 9: search.v1.MultiSearchRequest.resources:type_name -> search.v1.MultiSearchResource
+	17, // 10: search.v1.MultiSearchResource.filters:type_name -> search.v1.Filter
+	19, // 11: search.v1.MultiSearchResource.filter_group:type_name -> search.v1.FilterGroup
+	7,  // 12: search.v1.MultiSearchResponse.hits:type_name -> search.v1.SearchHit
+	40, // 13: search.v1.MultiSearchResponse.totals:type_name -> search.v1.MultiSearchResponse.TotalsEntry
+	17, // 14: search.v1.ExportRequest.filters:type_name -> search.v1.Filter
+	19, // 15: search.v1.ExportRequest.filter_group:type_name -> search.v1.FilterGroup
+	20, // 16: search.v1.ExportRequest.sort:type_name -> search.v1.Sort
+	7,  // 17: search.v1.ExportResponse.hits:type_name -> search.v1.SearchHit
+	17, // 18: search.v1.SuggestRequest.filters:type_name -> search.v1.Filter
+	19, // 19: search.v1.SuggestRequest.filter_group:type_name -> search.v1.FilterGroup
+	15, // 20: search.v1.SuggestResponse.suggestions:type_name -> search.v1.Suggestion
+	0,  // 21: search.v1.Filter.op:type_name -> search.v1.FilterOp
+	18, // 22: search.v1.Filter.range:type_name -> search.v1.RangeBounds
+	1,  // 23: search.v1.FilterGroup.op:type_name -> search.v1.GroupOp
+	17, // 24: search.v1.FilterGroup.filters:type_name -> search.v1.Filter
+	19, // 25: search.v1.FilterGroup.groups:type_name -> search.v1.FilterGroup
+	22, // 26: search.v1.Aggregation.terms:type_name -> search.v1.TermsAggregation
+	23, // 27: search.v1.Aggregation.range:type_name -> search.v1.RangeAggregation
+	25, // 28: search.v1.Aggregation.date_histogram:type_name -> search.v1.DateHistogramAggregation
+	26, // 29: search.v1.Aggregation.metric:type_name -> search.v1.MetricAggregation
+	24, // 30: search.v1.RangeAggregation.ranges:type_name -> search.v1.AggregationRange
+	2,  // 31: search.v1.MetricAggregation.type:type_name -> search.v1.MetricType
+	28, // 32: search.v1.AggregationResult.terms:type_name -> search.v1.TermsResult
+	30, // 33: search.v1.AggregationResult.range:type_name -> search.v1.RangeResult
+	32, // 34: search.v1.AggregationResult.date_histogram:type_name -> search.v1.DateHistogramResult
+	34, // 35: search.v1.AggregationResult.metric:type_name -> search.v1.MetricResult
+	29, // 36: search.v1.TermsResult.buckets:type_name -> search.v1.TermsBucket
+	31, // 37: search.v1.RangeResult.buckets:type_name -> search.v1.RangeBucket
+	33, // 38: search.v1.DateHistogramResult.buckets:type_name -> search.v1.DateHistogramBucket
+	37, // 39: search.v1.GetCapabilitiesResponse.resources:type_name -> search.v1.ResourceCapability
+	38, // 40: search.v1.ResourceCapability.fields:type_name -> search.v1.FieldCapability
+	0,  // 41: search.v1.FieldCapability.filter_ops:type_name -> search.v1.FilterOp
+	3,  // 42: search.v1.FieldCapability.aggregations:type_name -> search.v1.AggregationKind
+	6,  // 43: search.v1.SearchHit.HighlightsEntry.value:type_name -> search.v1.HighlightFragments
+	4,  // 44: search.v1.SearchService.Search:input_type -> search.v1.SearchRequest
+	35, // 45: search.v1.SearchService.GetCapabilities:input_type -> search.v1.GetCapabilitiesRequest
+	12, // 46: search.v1.SearchService.Export:input_type -> search.v1.ExportRequest
+	14, // 47: search.v1.SearchService.Suggest:input_type -> search.v1.SuggestRequest
+	9,  // 48: search.v1.SearchService.MultiSearch:input_type -> search.v1.MultiSearchRequest
+	8,  // 49: search.v1.SearchService.Search:output_type -> search.v1.SearchResponse
+	36, // 50: search.v1.SearchService.GetCapabilities:output_type -> search.v1.GetCapabilitiesResponse
+	13, // 51: search.v1.SearchService.Export:output_type -> search.v1.ExportResponse
+	16, // 52: search.v1.SearchService.Suggest:output_type -> search.v1.SuggestResponse
+	11, // 53: search.v1.SearchService.MultiSearch:output_type -> search.v1.MultiSearchResponse
+	49, // [49:54] is the sub-list for method output_type
+	44, // [44:49] is the sub-list for method input_type
+	44, // [44:44] is the sub-list for extension type_name
+	44, // [44:44] is the sub-list for extension extendee
+	0,  // [0:44] is the sub-list for field type_name
 }
 
 func init() { file_search_v1_search_proto_init() }
@@ -737,13 +3038,29 @@ func file_search_v1_search_proto_init() {
 	if File_search_v1_search_proto != nil {
 		return
 	}
+	file_search_v1_search_proto_msgTypes[1].OneofWrappers = []any{}
+	file_search_v1_search_proto_msgTypes[17].OneofWrappers = []any{
+		(*Aggregation_Terms)(nil),
+		(*Aggregation_Range)(nil),
+		(*Aggregation_DateHistogram)(nil),
+		(*Aggregation_Metric)(nil),
+	}
+	file_search_v1_search_proto_msgTypes[20].OneofWrappers = []any{}
+	file_search_v1_search_proto_msgTypes[23].OneofWrappers = []any{
+		(*AggregationResult_Terms)(nil),
+		(*AggregationResult_Range)(nil),
+		(*AggregationResult_DateHistogram)(nil),
+		(*AggregationResult_Metric)(nil),
+	}
+	file_search_v1_search_proto_msgTypes[27].OneofWrappers = []any{}
+	file_search_v1_search_proto_msgTypes[30].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_search_v1_search_proto_rawDesc), len(file_search_v1_search_proto_rawDesc)),
-			NumEnums:      1,
-			NumMessages:   9,
+			NumEnums:      4,
+			NumMessages:   37,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -21,6 +21,9 @@ const _ = grpc.SupportPackageIsVersion9
 const (
 	SearchService_Search_FullMethodName          = "/search.v1.SearchService/Search"
 	SearchService_GetCapabilities_FullMethodName = "/search.v1.SearchService/GetCapabilities"
+	SearchService_Export_FullMethodName          = "/search.v1.SearchService/Export"
+	SearchService_Suggest_FullMethodName         = "/search.v1.SearchService/Suggest"
+	SearchService_MultiSearch_FullMethodName     = "/search.v1.SearchService/MultiSearch"
 )
 
 // SearchServiceClient is the client API for SearchService service.
@@ -29,6 +32,14 @@ const (
 type SearchServiceClient interface {
 	Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error)
 	GetCapabilities(ctx context.Context, in *GetCapabilitiesRequest, opts ...grpc.CallOption) (*GetCapabilitiesResponse, error)
+	// Export streams every document matching the query and filters, in
+	// batches. It reads from a point-in-time snapshot of the index.
+	Export(ctx context.Context, in *ExportRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ExportResponse], error)
+	// Suggest returns type-ahead completions for a partially typed query.
+	Suggest(ctx context.Context, in *SuggestRequest, opts ...grpc.CallOption) (*SuggestResponse, error)
+	// MultiSearch runs one query against several resources at once and returns
+	// their hits merged into a single ranked list.
+	MultiSearch(ctx context.Context, in *MultiSearchRequest, opts ...grpc.CallOption) (*MultiSearchResponse, error)
 }
 
 type searchServiceClient struct {
@@ -59,12 +70,59 @@ func (c *searchServiceClient) GetCapabilities(ctx context.Context, in *GetCapabi
 	return out, nil
 }
 
+func (c *searchServiceClient) Export(ctx context.Context, in *ExportRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ExportResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &SearchService_ServiceDesc.Streams[0], SearchService_Export_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ExportRequest, ExportResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SearchService_ExportClient = grpc.ServerStreamingClient[ExportResponse]
+
+func (c *searchServiceClient) Suggest(ctx context.Context, in *SuggestRequest, opts ...grpc.CallOption) (*SuggestResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SuggestResponse)
+	err := c.cc.Invoke(ctx, SearchService_Suggest_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *searchServiceClient) MultiSearch(ctx context.Context, in *MultiSearchRequest, opts ...grpc.CallOption) (*MultiSearchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(MultiSearchResponse)
+	err := c.cc.Invoke(ctx, SearchService_MultiSearch_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // SearchServiceServer is the server API for SearchService service.
 // All implementations should embed UnimplementedSearchServiceServer
 // for forward compatibility.
 type SearchServiceServer interface {
 	Search(context.Context, *SearchRequest) (*SearchResponse, error)
 	GetCapabilities(context.Context, *GetCapabilitiesRequest) (*GetCapabilitiesResponse, error)
+	// Export streams every document matching the query and filters, in
+	// batches. It reads from a point-in-time snapshot of the index.
+	Export(*ExportRequest, grpc.ServerStreamingServer[ExportResponse]) error
+	// Suggest returns type-ahead completions for a partially typed query.
+	Suggest(context.Context, *SuggestRequest) (*SuggestResponse, error)
+	// MultiSearch runs one query against several resources at once and returns
+	// their hits merged into a single ranked list.
+	MultiSearch(context.Context, *MultiSearchRequest) (*MultiSearchResponse, error)
 }
 
 // UnimplementedSearchServiceServer should be embedded to have
@@ -80,6 +138,15 @@ func (UnimplementedSearchServiceServer) Search(context.Context, *SearchRequest)
 func (UnimplementedSearchServiceServer) GetCapabilities(context.Context, *GetCapabilitiesRequest) (*GetCapabilitiesResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetCapabilities not implemented")
 }
+func (UnimplementedSearchServiceServer) Export(*ExportRequest, grpc.ServerStreamingServer[ExportResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method Export not implemented")
+}
+func (UnimplementedSearchServiceServer) Suggest(context.Context, *SuggestRequest) (*SuggestResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Suggest not implemented")
+}
+func (UnimplementedSearchServiceServer) MultiSearch(context.Context, *MultiSearchRequest) (*MultiSearchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MultiSearch not implemented")
+}
 func (UnimplementedSearchServiceServer) testEmbeddedByValue() {}
 
 // UnsafeSearchServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -136,6 +203,53 @@ func _SearchService_GetCapabilities_Handler(srv interface{}, ctx context.Context
 	return interceptor(ctx, in, info, handler)
 }
 
+func _SearchService_Export_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExportRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SearchServiceServer).Export(m, &grpc.GenericServerStream[ExportRequest, ExportResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SearchService_ExportServer = grpc.ServerStreamingServer[ExportResponse]
+
+func _SearchService_Suggest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SuggestRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SearchServiceServer).Suggest(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SearchService_Suggest_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SearchServiceServer).Suggest(ctx, req.(*SuggestRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SearchService_MultiSearch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MultiSearchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SearchServiceServer).MultiSearch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SearchService_MultiSearch_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SearchServiceServer).MultiSearch(ctx, req.(*MultiSearchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // SearchService_ServiceDesc is the grpc.ServiceDesc for SearchService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -151,7 +265,21 @@ var SearchService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetCapabilities",
 			Handler:    _SearchService_GetCapabilities_Handler,
 		},
+		{
+			MethodName: "Suggest",
+			Handler:    _SearchService_Suggest_Handler,
+		},
+		{
+			MethodName: "MultiSearch",
+			Handler:    _SearchService_MultiSearch_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Export",
+			Handler:       _SearchService_Export_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "search/v1/search.proto",
 }
@@ -2,15 +2,20 @@ package projection
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
 	"github.com/theleeeo/indexer/aggregation"
 	"github.com/theleeeo/indexer/model"
 )
 
-// BuildRequest is the request parameter for the aggregation plan.
+// BuildRequest is the request parameter for the aggregation plan. The plan
+// builds the resource of ResourceID, or those of ResourceIDs in pages, or
+// every resource of the type if neither is set.
 type BuildRequest struct {
 	ResourceType string
 	ResourceID   string
+	ResourceIDs  []string
 	Metadata     map[string]string
 }
 
@@ -24,6 +29,40 @@ type BuildDoc struct {
 	Resolved map[string][]map[string]any
 
 	Relations []model.Resource
+
+	// Violations holds the values that could not be converted to the type
+	// of their field. The resource's coercion policy decides whether they
+	// fail the build.
+	Violations []Violation
+}
+
+// Violation is a provider value that could not be converted to the ES type
+// of its field.
+type Violation struct {
+	// Field is the document path of the field, e.g. "fields.age" or
+	// "lines.quantity".
+	Field  string
+	Type   string
+	Value  any
+	Reason string
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("%s: cannot convert %v to %s: %s", v.Field, v.Value, v.Type, v.Reason)
+}
+
+// ViolationError fails the build of a resource with violations.
+type ViolationError struct {
+	Root       model.Resource
+	Violations []Violation
+}
+
+func (e *ViolationError) Error() string {
+	msgs := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		msgs[i] = v.String()
+	}
+	return fmt.Sprintf("%s/%s has %d invalid values: %s", e.Root.Type, e.Root.Id, len(e.Violations), strings.Join(msgs, "; "))
 }
 
 // TODO: NewPlan builder
@@ -1,8 +1,12 @@
 package resource
 
 import (
-	"fmt"
+	"slices"
 	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/theleeeo/indexer/expr"
 )
 
 type Configs []*Config
@@ -21,39 +25,185 @@ type VersionConfig struct {
 	Version   int              `yaml:"version"`
 	Fields    []FieldConfig    `yaml:"fields"`
 	Relations []RelationConfig `yaml:"relations"`
+
+	// Analysis holds the custom analysis components of the version's index.
+	Analysis AnalysisConfig `yaml:"analysis"`
+}
+
+// AnalysisConfig defines custom analysis components for an index, keyed by
+// name. Each definition is passed to ES as-is, so any ES option can be used,
+// e.g. a synonym filter:
+//
+//	filters:
+//	  my_synonyms:
+//	    type: synonym
+//	    synonyms: ["tv, television"]
+type AnalysisConfig struct {
+	Analyzers   map[string]map[string]any `yaml:"analyzers"`
+	Normalizers map[string]map[string]any `yaml:"normalizers"`
+	Tokenizers  map[string]map[string]any `yaml:"tokenizers"`
+	Filters     map[string]map[string]any `yaml:"filters"`
+	CharFilters map[string]map[string]any `yaml:"charFilters"`
+}
+
+// IsEmpty reports whether no analysis components are defined.
+func (a AnalysisConfig) IsEmpty() bool {
+	return len(a.Analyzers) == 0 && len(a.Normalizers) == 0 && len(a.Tokenizers) == 0 &&
+		len(a.Filters) == 0 && len(a.CharFilters) == 0
 }
 
 // GetSearchableFields returns the list of ES field paths that are included
-// in multi_match full-text search for this version.
+// in multi_match full-text search for this version. Boosted fields carry
+// their boost in ES notation, e.g. "fields.title^2".
 func (vc *VersionConfig) GetSearchableFields() []string {
 	var fields []string
-	for _, f := range vc.Fields {
-		if f.Query.Search == nil || *f.Query.Search {
-			fields = append(fields, "fields."+f.Name)
+	for _, f := range vc.DocumentFields() {
+		if !f.Field.IsObject() && f.Field.Query.Searchable() {
+			fields = append(fields, f.Field.Query.boosted(f.Path))
 		}
 	}
+	return fields
+}
+
+// GetSuggestFields returns the ES field paths of the fields with suggest
+// enabled, e.g. "fields.title". The suggest sub-field of each is
+// "<path>.suggest".
+func (vc *VersionConfig) GetSuggestFields() []string {
+	var fields []string
+	for _, f := range vc.DocumentFields() {
+		if f.Field.Suggest {
+			fields = append(fields, f.Path)
+		}
+	}
+	return fields
+}
+
+// DocumentField is a field together with where it is in the document.
+type DocumentField struct {
+	Field *FieldConfig
+
+	// Path is the document path of the field, e.g. "fields.title",
+	// "fields.address.city" or "lines.sku".
+	Path string
+
+	// NestedPath is the innermost ES nested path containing the field, or ""
+	// if there is none.
+	NestedPath string
+}
+
+// DocumentFields returns all fields of the version: the root's fields
+// followed by the fields of each embedded relation. The fields of object and
+// nested fields follow the field they belong to.
+func (vc *VersionConfig) DocumentFields() []DocumentField {
+	var fields []DocumentField
 
-	for _, r := range vc.Relations {
-		for _, f := range r.Fields {
-			if f.Query.Search == nil || *f.Query.Search {
-				fields = append(fields, fmt.Sprintf("%s.%s", r.Resource, f.Name))
+	var walk func(fs []FieldConfig, prefix, nestedPath string)
+	walk = func(fs []FieldConfig, prefix, nestedPath string) {
+		for i := range fs {
+			f := &fs[i]
+			path := prefix + "." + f.Name
+			fields = append(fields, DocumentField{Field: f, Path: path, NestedPath: nestedPath})
+			if f.IsObject() {
+				np := nestedPath
+				if f.ESType() == "nested" {
+					np = path
+				}
+				walk(f.Fields, path, np)
 			}
 		}
 	}
+	walk(vc.Fields, "fields", "")
+	for _, r := range vc.EmbeddedRelations() {
+		walk(r.Relation.Fields, r.Path, r.NestedPath)
+	}
 
 	return fields
 }
 
-// GetRelation returns the relation config for the given resource name, or nil.
-func (vc *VersionConfig) GetRelation(resource string) *RelationConfig {
-	for _, r := range vc.Relations {
-		if r.Resource == resource {
-			return &r
+// EmbeddedRelation is a relation together with where its objects are
+// embedded in the document.
+type EmbeddedRelation struct {
+	Relation *RelationConfig
+
+	// Path is the document path of the relation's objects, e.g. "lines" for
+	// a relation of the root or "lines.product" for a relation of "lines".
+	Path string
+
+	// NestedPath is the innermost ES nested path containing the relation's
+	// objects, or "" if neither it nor any of its parents is of cardinality
+	// "many".
+	NestedPath string
+}
+
+// EmbeddedRelations returns all relations of the version including the
+// relations of relations, each parent before its children.
+func (vc *VersionConfig) EmbeddedRelations() []EmbeddedRelation {
+	var embedded []EmbeddedRelation
+
+	var walk func(rels []RelationConfig, prefix, nestedPath string)
+	walk = func(rels []RelationConfig, prefix, nestedPath string) {
+		for i := range rels {
+			rel := &rels[i]
+			path := prefix + rel.Name()
+			np := nestedPath
+			if rel.IsMany() {
+				np = path
+			}
+			embedded = append(embedded, EmbeddedRelation{Relation: rel, Path: path, NestedPath: np})
+			walk(rel.Relations, path+".", np)
+		}
+	}
+	walk(vc.Relations, "", "")
+
+	return embedded
+}
+
+// GetRelation returns the relation config embedded at the given path, e.g.
+// "lines" or "lines.product", or nil.
+func (vc *VersionConfig) GetRelation(path string) *RelationConfig {
+	for _, r := range vc.EmbeddedRelations() {
+		if r.Path == path {
+			return r.Relation
 		}
 	}
 	return nil
 }
 
+// NestedPath returns the ES nested path for a document field path such as
+// "c.state", "c.d.name" or "fields.lines.sku", or "" if the field does not
+// belong to a cardinality "many" relation or a nested field (which are the
+// ones mapped as "nested"). If there are several it is the innermost one.
+func (vc *VersionConfig) NestedPath(field string) string {
+	var nestedPath string
+	for _, p := range vc.nestedPaths() {
+		if strings.HasPrefix(field, p+".") && len(p) > len(nestedPath) {
+			nestedPath = p
+		}
+	}
+	return nestedPath
+}
+
+// IsNestedPath reports whether path is the path of a cardinality "many"
+// relation or of a nested field.
+func (vc *VersionConfig) IsNestedPath(path string) bool {
+	return slices.Contains(vc.nestedPaths(), path)
+}
+
+func (vc *VersionConfig) nestedPaths() []string {
+	var paths []string
+	for _, r := range vc.EmbeddedRelations() {
+		if r.Relation.IsMany() {
+			paths = append(paths, r.Path)
+		}
+	}
+	for _, f := range vc.DocumentFields() {
+		if f.Field.ESType() == "nested" {
+			paths = append(paths, f.Path)
+		}
+	}
+	return paths
+}
+
 type Config struct {
 	Resource string `yaml:"resource"`
 
@@ -63,6 +213,71 @@ type Config struct {
 	// ReadVersion is the version whose index the read alias points to.
 	// Must match one of the Versions entries. Defaults to the lowest version.
 	ReadVersion int `yaml:"readVersion"`
+
+	// Query configures how full-text queries are run against the resource.
+	Query QueryModeConfig `yaml:"query"`
+
+	// Coercion configures how values that cannot be converted to the type of
+	// their field are handled when building the resource's documents.
+	Coercion CoercionConfig `yaml:"coercion"`
+
+	// Concurrency is the number of relation fetches running at once when
+	// building the resource, across the documents of a page and the relations
+	// fetched together. Defaults to 1, one at a time.
+	Concurrency int `yaml:"concurrency"`
+}
+
+// Coercion policies supported in CoercionConfig.Policy.
+const (
+	CoercionFail = "fail"
+	CoercionDrop = "drop"
+	CoercionNull = "null"
+)
+
+// CoercionConfig configures the conversion of provider values to the ES
+// types of their fields, e.g. "12" to 12 for an integer field.
+type CoercionConfig struct {
+	// Policy decides what happens to a document with a value that cannot be
+	// converted: "fail" fails its build, "drop" leaves the field out and
+	// "null" indexes it as null. Every such value is reported as a
+	// violation. Without a policy values are not converted, they are
+	// indexed as provided and ES converts or rejects them.
+	Policy string `yaml:"policy"`
+}
+
+// Enabled reports whether values are converted at all.
+func (c CoercionConfig) Enabled() bool {
+	return c.Policy != ""
+}
+
+// Fails reports whether a violation fails the build.
+func (c CoercionConfig) Fails() bool {
+	return c.Policy == CoercionFail
+}
+
+// Multi-match query types supported in QueryModeConfig.Type.
+const (
+	MultiMatchBestFields   = "best_fields"
+	MultiMatchMostFields   = "most_fields"
+	MultiMatchCrossFields  = "cross_fields"
+	MultiMatchPhrasePrefix = "phrase_prefix"
+)
+
+// QueryModeConfig selects the ES multi_match mode used for full-text search.
+// Zero values use the ES defaults.
+type QueryModeConfig struct {
+	// Type is the multi_match type; defaults to "best_fields".
+	Type string `yaml:"type"`
+
+	// Operator combines the terms of the query, "or" (default) or "and".
+	Operator string `yaml:"operator"`
+
+	// MinimumShouldMatch is the ES minimum_should_match, e.g. "2" or "75%".
+	MinimumShouldMatch string `yaml:"minimumShouldMatch"`
+
+	// Fuzziness is the ES fuzziness, e.g. "AUTO" or "1". Not supported by
+	// the cross_fields and phrase_prefix types.
+	Fuzziness string `yaml:"fuzziness"`
 }
 
 // SortedVersions returns the version numbers in ascending order.
@@ -91,11 +306,11 @@ func (c *Config) ReadVersionConfig() *VersionConfig {
 }
 
 // HasRelationTo reports whether any version of this resource has a relation
-// to the given resource type.
+// to the given resource type, directly or through a relation of a relation.
 func (c *Config) HasRelationTo(resourceType string) bool {
 	for _, vc := range c.Versions {
-		for _, rel := range vc.Relations {
-			if rel.Resource == resourceType {
+		for _, rel := range vc.EmbeddedRelations() {
+			if rel.Relation.Resource == resourceType {
 				return true
 			}
 		}
@@ -109,15 +324,155 @@ func (c *Config) ApplyDefaults() {
 	if c.ReadVersion == 0 {
 		c.ReadVersion = c.SortedVersions()[0]
 	}
+	for i := range c.Versions {
+		for _, f := range c.Versions[i].DocumentFields() {
+			f.Field.parse()
+		}
+	}
 }
 
 type FieldConfig struct {
-	Name  string      `yaml:"name"`
-	Type  string      `yaml:"type"` // ES field type; defaults to "keyword"
+	// Name is the name the field is indexed under.
+	Name string `yaml:"name"`
+	Type string `yaml:"type"` // ES field type; defaults to "keyword"
+
+	// Source is the path of the field's value in the provider data, e.g.
+	// "address.city", "$.address.city" or "tags[0]", see ParseFieldPath.
+	// Defaults to the name.
+	Source string `yaml:"source"`
+
 	Query QueryConfig `yaml:"query"`
+
+	// Analyzer and SearchAnalyzer apply to text fields, Normalizer to keyword
+	// fields. They name a built-in or a custom component from the version's
+	// analysis config.
+	Analyzer       string `yaml:"analyzer"`
+	SearchAnalyzer string `yaml:"searchAnalyzer"`
+	Normalizer     string `yaml:"normalizer"`
+
+	// SubFields index the same value in additional ways, e.g. a "keyword"
+	// sub-field of a text field for sorting. They are addressed as
+	// "<field>.<sub-field>".
+	SubFields []SubFieldConfig `yaml:"subFields"`
+
+	// Suggest enables type-ahead suggestions on the field. It adds a
+	// search_as_you_type sub-field named SuggestSubField.
+	Suggest bool `yaml:"suggest"`
+
+	// Formats are the accepted formats of a date field's values, tried in
+	// order. Each is a Go time layout or one of DateFormatStrictDateOptionalTime,
+	// DateFormatRFC3339, DateFormatEpochMillis and DateFormatEpochSecond.
+	// Defaults to strict_date_optional_time and epoch millis, the formats ES
+	// accepts by default. Values in those formats are indexed as provided,
+	// others are converted to RFC 3339.
+	Formats []string `yaml:"formats"`
+
+	// Fields are the fields of an "object" or "nested" field, i.e. of an
+	// embedded object or list of objects, e.g. the street and city of an
+	// address. Objects of a "nested" field are matched by filters one by
+	// one, like the objects of a cardinality "many" relation. Not to be
+	// confused with SubFields, which index a single value in more ways.
+	Fields []FieldConfig `yaml:"fields"`
+
+	// Computed derives the field's value from an expression over the root's
+	// fields and relations instead of copying it from the provider data,
+	// e.g. `concat(first_name, " ", last_name)` or
+	// `count(orders, status == "open") > 0`. See package expr for the
+	// language. Only supported on fields of the root resource. The type
+	// defaults to the type of the expression, and is required if that
+	// cannot be derived, e.g. for `max(orders.total)`.
+	Computed string `yaml:"computed"`
+
+	// sourcePath is the parsed Source and computed the parsed Computed, set
+	// by Config.ApplyDefaults. computedErr is the parse error of Computed.
+	sourcePath  FieldPath
+	computed    *expr.Expr
+	computedErr error
 }
 
+// parse parses the field's Source and Computed once, so that they need not
+// be parsed for every document. An invalid Source is left unparsed for
+// validation to report.
+func (f *FieldConfig) parse() {
+	if f.Source != "" {
+		f.sourcePath, _ = ParseFieldPath(f.Source)
+	}
+	if f.Computed != "" {
+		f.computed, f.computedErr = expr.Parse(f.Computed)
+	}
+}
+
+// ComputedExpr returns the parsed Computed expression of the field, or nil if
+// it is not a computed field.
+func (f FieldConfig) ComputedExpr() (*expr.Expr, error) {
+	if f.Computed == "" || f.computed != nil || f.computedErr != nil {
+		return f.computed, f.computedErr
+	}
+	// Not set by Config.ApplyDefaults, e.g. for a config built in code.
+	return expr.Parse(f.Computed)
+}
+
+// Value returns the field's value in the provider data of a resource, or false
+// if it has none.
+func (f FieldConfig) Value(data map[string]any) (any, bool) {
+	if f.Source == "" {
+		v, ok := data[f.Name]
+		return v, ok
+	}
+	path := f.sourcePath
+	if path == nil {
+		// Not set by Config.ApplyDefaults, e.g. for a config built in code.
+		var err error
+		if path, err = ParseFieldPath(f.Source); err != nil {
+			return nil, false
+		}
+	}
+	return path.Get(data)
+}
+
+// Named date formats supported in FieldConfig.Formats.
+const (
+	// DateFormatStrictDateOptionalTime is the ES format of the same name,
+	// e.g. "2024-01-02", "2024-01-02T10:00" or "2024-01-02T10:00:00.5+01:00".
+	DateFormatStrictDateOptionalTime = "strict_date_optional_time"
+
+	DateFormatRFC3339     = "rfc3339"
+	DateFormatEpochMillis = "epoch_millis"
+	DateFormatEpochSecond = "epoch_second"
+)
+
+// SuggestSubField is the name of the sub-field added to fields with suggest
+// enabled.
+const SuggestSubField = "suggest"
+
+// IsObject reports whether the field is an "object" or "nested" field.
+func (f FieldConfig) IsObject() bool {
+	return f.Type == "object" || f.Type == "nested"
+}
+
+// ESType returns the ES field type. Computed fields without a type default
+// to the type the expression evaluates to, if it is known. An invalid
+// expression is reported by Validate, not here.
 func (f FieldConfig) ESType() string {
+	if f.Type != "" {
+		return f.Type
+	}
+	if e, err := f.ComputedExpr(); err == nil && e != nil && e.Type() != "" {
+		return e.Type()
+	}
+	return "keyword"
+}
+
+// SubFieldConfig is an ES multi-field of a FieldConfig.
+type SubFieldConfig struct {
+	Name           string `yaml:"name"`
+	Type           string `yaml:"type"` // ES field type; defaults to "keyword"
+	Analyzer       string `yaml:"analyzer"`
+	SearchAnalyzer string `yaml:"searchAnalyzer"`
+	Normalizer     string `yaml:"normalizer"`
+}
+
+func (f SubFieldConfig) ESType() string {
 	if f.Type == "" {
 		return "keyword"
 	}
@@ -127,26 +482,185 @@ func (f FieldConfig) ESType() string {
 type QueryConfig struct {
 	// Default true
 	Search *bool `yaml:"search"`
+
+	// Boost multiplies the relevance of matches in this field. Defaults to 1.
+	Boost float64 `yaml:"boost"`
+}
+
+// Searchable reports whether the field is included in full-text search.
+func (q QueryConfig) Searchable() bool {
+	return q.Search == nil || *q.Search
+}
+
+// boosted appends the boost of the field to path if one is set.
+func (q QueryConfig) boosted(path string) string {
+	if q.Boost == 0 || q.Boost == 1 {
+		return path
+	}
+	return path + "^" + strconv.FormatFloat(q.Boost, 'f', -1, 64)
 }
 
 type KeyConfig struct {
 	// Source is the name of the resource to extract the lookup key from.
 	// Use the root resource's own name to extract from root data,
-	// or a sibling relation name to extract from its resolved data.
+	// or a sibling relation name (its alias, if set) to extract from its
+	// resolved data.
 	Source string `yaml:"source"`
 
 	// Field is the field name to extract from the source resource's data.
-	// The extracted value is passed to the provider.
+	// The extracted value is passed to the provider. Strings, numbers and
+	// booleans are supported, as well as arrays of them, e.g. tag_ids.
 	Field string `yaml:"field"`
+
+	// Fields extracts a composite key of several fields instead, e.g.
+	// tenant_id and sku. Mutually exclusive with Field. The values of a
+	// composite key must not be arrays.
+	Fields []string `yaml:"fields"`
+
+	// Array selects how array values of Field are looked up: "each"
+	// (default) fetches the related resources of every element separately,
+	// "batch" fetches them in one lookup with the elements as a list value.
+	// Keys from a source relation with several items are looked up the same
+	// way.
+	Array string `yaml:"array"`
+}
+
+// Lookup modes of array-valued keys, see KeyConfig.Array.
+const (
+	KeyArrayEach  = "each"
+	KeyArrayBatch = "batch"
+)
+
+// KeyFields returns the fields making up the key.
+func (k KeyConfig) KeyFields() []string {
+	if len(k.Fields) > 0 {
+		return k.Fields
+	}
+	if k.Field != "" {
+		return []string{k.Field}
+	}
+	return nil
+}
+
+// IsZero reports whether no part of the key is configured.
+func (k KeyConfig) IsZero() bool {
+	return k.Source == "" && k.Field == "" && len(k.Fields) == 0 && k.Array == ""
 }
 
 type RelationConfig struct {
-	Resource    string        `yaml:"resource"`
+	Resource string `yaml:"resource"`
+
+	// As names the relation in the document, e.g. "assignee" and "reporter"
+	// for two relations to "user". Defaults to the resource name.
+	As string `yaml:"as"`
+
 	Key         KeyConfig     `yaml:"key"`
 	Cardinality string        `yaml:"cardinality"` // "one" or "many"; defaults to "many"
 	Fields      []FieldConfig `yaml:"fields"`
+
+	// Reverse makes the relation embed the resources that point to the root
+	// instead of following a key from the root. Key must be empty when set.
+	Reverse *ReverseConfig `yaml:"reverse"`
+
+	// Relations are relations of the related resource. They are resolved for
+	// each related item and embedded in its object, e.g. the product of each
+	// line of an order as "lines.product". Their key source is this relation,
+	// and reverse relations match the ID of the item.
+	Relations []RelationConfig `yaml:"relations"`
+
+	// Where, OrderBy and Limit select which of the related items are
+	// embedded, e.g. only the five most recent open orders. They are applied
+	// after fetching and passed to the provider as hints. Changes to items
+	// that are left out still rebuild the root, as they may enter the
+	// selection.
+	//
+	// Where is an expression over the fields of an item, see package expr,
+	// e.g. `status == "open"`. Items it does not evaluate to true for are
+	// left out.
+	Where string `yaml:"where"`
+
+	// OrderBy sorts the items, by the first entry and then by the next.
+	OrderBy []OrderByConfig `yaml:"orderBy"`
+
+	// Limit is the maximum number of items embedded. 0 means no limit.
+	Limit int `yaml:"limit"`
+}
+
+// Sort orders supported in OrderByConfig.Order.
+const (
+	OrderAsc  = "asc"
+	OrderDesc = "desc"
+)
+
+// OrderByConfig sorts related items by a field.
+type OrderByConfig struct {
+	// Field is the path of the field in the item's data, see ParseFieldPath.
+	Field string `yaml:"field"`
+
+	// Order is "asc" (default) or "desc". Items without the field are
+	// sorted last either way.
+	Order string `yaml:"order"`
 }
 
 func (r RelationConfig) IsMany() bool {
 	return r.Cardinality != "one"
 }
+
+// Name returns the name the relation's objects are embedded under in the
+// document. It is also what key sources of other relations refer to.
+func (r RelationConfig) Name() string {
+	if r.As != "" {
+		return r.As
+	}
+	return r.Resource
+}
+
+// Sources of the related set of a reverse relation.
+const (
+	ReverseSourceProvider = "provider"
+	ReverseSourceStore    = "store"
+)
+
+// ReverseConfig configures a reverse relation, which embeds all resources of
+// the relation's type that reference the root through Field.
+type ReverseConfig struct {
+	// Field is the field of the related resource that holds the root's ID.
+	Field string `yaml:"field"`
+
+	// Source selects where the related set comes from. "provider" (default)
+	// calls FetchRelated with Field and the root ID as key. "store" reads the
+	// related resources that referenced the root when they were last built
+	// from the relation graph, and fetches each of them by ID.
+	Source string `yaml:"source"`
+}
+
+// ReverseRef is a reverse relation as seen from the resource it embeds.
+type ReverseRef struct {
+	// Resource is the resource type that has the reverse relation.
+	Resource string
+
+	// Field is the field of the embedded resource holding the ID of the
+	// embedding resource.
+	Field string
+}
+
+// ReverseRefs returns the reverse relations of all resources and versions
+// that embed resources of the given type. Only relations of the root are
+// considered, relations of relations have no reverse references to record.
+func (c Configs) ReverseRefs(resourceType string) []ReverseRef {
+	var refs []ReverseRef
+	for _, rc := range c {
+		for _, vc := range rc.Versions {
+			for _, rel := range vc.Relations {
+				if rel.Reverse == nil || rel.Resource != resourceType {
+					continue
+				}
+				ref := ReverseRef{Resource: rc.Resource, Field: rel.Reverse.Field}
+				if !slices.Contains(refs, ref) {
+					refs = append(refs, ref)
+				}
+			}
+		}
+	}
+	return refs
+}
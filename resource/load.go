@@ -24,14 +24,18 @@ type rawFile struct {
 
 // rawEntry represents a single entry in the resources list.
 // For versioned entries (version > 0), Fields is a VersionConfig object
-// containing {fields, relations}. For unversioned entries, Fields is
-// a []FieldConfig list and Relations is a sibling key.
+// containing {fields, relations, analysis}. For unversioned entries, Fields
+// is a []FieldConfig list and Relations and Analysis are sibling keys.
 type rawEntry struct {
 	Type        string           `yaml:"type"`
 	Version     int              `yaml:"version,omitempty"`
 	ReadVersion int              `yaml:"readVersion,omitempty"`
+	Query       *QueryModeConfig `yaml:"query,omitempty"`
+	Coercion    *CoercionConfig  `yaml:"coercion,omitempty"`
+	Concurrency int              `yaml:"concurrency,omitempty"`
 	Fields      any              `yaml:"fields"`
 	Relations   []RelationConfig `yaml:"relations,omitempty"`
+	Analysis    AnalysisConfig   `yaml:"analysis,omitempty"`
 }
 
 // ParseConfig parses resource config YAML bytes into Configs.
@@ -62,6 +66,18 @@ func ParseConfig(data []byte) (Configs, error) {
 			cfg.ReadVersion = entry.ReadVersion
 		}
 
+		if entry.Query != nil {
+			cfg.Query = *entry.Query
+		}
+
+		if entry.Coercion != nil {
+			cfg.Coercion = *entry.Coercion
+		}
+
+		if entry.Concurrency != 0 {
+			cfg.Concurrency = entry.Concurrency
+		}
+
 		version := entry.Version
 		if version == 0 {
 			version = 1
@@ -98,10 +114,10 @@ func ParseConfig(data []byte) (Configs, error) {
 // object with {fields, relations} sub-keys (i.e. a VersionConfig).
 //
 // For unversioned entries, "fields" is a direct []FieldConfig list and
-// "relations" is a sibling key on the entry.
+// "relations" and "analysis" are sibling keys on the entry.
 func parseEntrySchema(entry rawEntry) (*VersionConfig, error) {
 	if entry.Fields == nil {
-		return &VersionConfig{Relations: entry.Relations}, nil
+		return &VersionConfig{Relations: entry.Relations, Analysis: entry.Analysis}, nil
 	}
 
 	b, err := yaml.Marshal(entry.Fields)
@@ -110,7 +126,7 @@ func parseEntrySchema(entry rawEntry) (*VersionConfig, error) {
 	}
 
 	if entry.Version > 0 {
-		// Versioned: fields is {fields: [...], relations: [...]}
+		// Versioned: fields is {fields: [...], relations: [...], analysis: {...}}
 		var vc VersionConfig
 		if err := yaml.Unmarshal(b, &vc); err != nil {
 			return nil, fmt.Errorf("parse version schema: %w", err)
@@ -126,5 +142,6 @@ func parseEntrySchema(entry rawEntry) (*VersionConfig, error) {
 	return &VersionConfig{
 		Fields:    fields,
 		Relations: entry.Relations,
+		Analysis:  entry.Analysis,
 	}, nil
 }
@@ -0,0 +1,118 @@
+package resource
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// FieldPath is a parsed path into the data of a resource, see
+// ParseFieldPath.
+type FieldPath []pathSegment
+
+type pathSegment struct {
+	key string
+	// index is the list index of an index segment, or -1 for a key segment.
+	index    int
+	wildcard bool
+}
+
+// ParseFieldPath parses a dot or JSONPath-style path such as
+// "address.city", "$.address.city", "tags[0]", "lines[*].sku" or
+// "meta['content-type']". Wildcards select every item of a list.
+func ParseFieldPath(path string) (FieldPath, error) {
+	s := strings.TrimPrefix(path, "$")
+	if len(s) < len(path) {
+		s = strings.TrimPrefix(s, ".")
+	}
+	if s == "" {
+		return nil, fmt.Errorf("empty path")
+	}
+
+	var fp FieldPath
+	for i := 0; i < len(s); {
+		switch {
+		case s[i] == '[':
+			end := strings.IndexByte(s[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated [ at %d in %q", i, path)
+			}
+			inner := s[i+1 : i+end]
+			switch {
+			case inner == "*":
+				fp = append(fp, pathSegment{index: -1, wildcard: true})
+			case len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0]:
+				fp = append(fp, pathSegment{key: inner[1 : len(inner)-1], index: -1})
+			default:
+				n, err := strconv.Atoi(inner)
+				if err != nil || n < 0 {
+					return nil, fmt.Errorf("invalid index %q in %q", inner, path)
+				}
+				fp = append(fp, pathSegment{index: n})
+			}
+			i += end + 1
+
+		case s[i] == '.':
+			if i == 0 || i+1 >= len(s) || s[i+1] == '.' || s[i+1] == '[' {
+				return nil, fmt.Errorf("empty segment at %d in %q", i, path)
+			}
+			i++
+
+		default:
+			end := strings.IndexAny(s[i:], ".[")
+			if end < 0 {
+				end = len(s) - i
+			}
+			fp = append(fp, pathSegment{key: s[i : i+end], index: -1})
+			i += end
+		}
+	}
+
+	return fp, nil
+}
+
+// Get returns the value at the path, or false if it does not exist. Wildcard
+// segments yield a list of the values found in each item.
+func (p FieldPath) Get(data map[string]any) (any, bool) {
+	return p.get(data)
+}
+
+func (p FieldPath) get(v any) (any, bool) {
+	if len(p) == 0 {
+		return v, true
+	}
+
+	seg := p[0]
+	switch {
+	case seg.wildcard:
+		list, ok := v.([]any)
+		if !ok {
+			return nil, false
+		}
+		values := make([]any, 0, len(list))
+		for _, item := range list {
+			if iv, ok := p[1:].get(item); ok {
+				values = append(values, iv)
+			}
+		}
+		return values, true
+
+	case seg.index >= 0:
+		list, ok := v.([]any)
+		if !ok || seg.index >= len(list) {
+			return nil, false
+		}
+		return p[1:].get(list[seg.index])
+
+	default:
+		m, ok := v.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		next, ok := m[seg.key]
+		if !ok {
+			return nil, false
+		}
+		return p[1:].get(next)
+	}
+}
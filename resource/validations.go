@@ -2,6 +2,11 @@ package resource
 
 import (
 	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/theleeeo/indexer/expr"
 )
 
 func (c Configs) Validate() error {
@@ -36,32 +41,15 @@ func (c Configs) verifyFieldRelations() error {
 			vc := &rCfg.Versions[i]
 			v := vc.Version
 			for _, currentRel := range vc.Relations {
-				// Verify that the related resource exists
-				relRCfg := c.Get(currentRel.Resource)
-				if relRCfg == nil {
-					return fmt.Errorf("version %d: relation '%s'->'%s' is specified but resource '%s' does not exist", v, rCfg.Resource, currentRel.Resource, currentRel.Resource)
-				}
-
-				// Collect all field names across all versions of the target resource.
-				allTargetFields := make(map[string]bool)
-				for _, tvc := range relRCfg.Versions {
-					for _, f := range tvc.Fields {
-						allTargetFields[f.Name] = true
-					}
-				}
-
-				// Verify that the related resource has the fields defined in the relation
-				for _, f := range currentRel.Fields {
-					if !allTargetFields[f.Name] {
-						return fmt.Errorf("version %d: relation '%s'->'%s' specifies field '%s' which does not exist on '%s'", v, rCfg.Resource, currentRel.Resource, f.Name, currentRel.Resource)
-					}
+				if err := c.verifyRelationTarget(v, rCfg.Resource, currentRel); err != nil {
+					return err
 				}
 
 				// Verify key source: must be the owning resource or a sibling relation in this version
-				if currentRel.Key.Source != rCfg.Resource {
+				if currentRel.Reverse == nil && currentRel.Key.Source != rCfg.Resource {
 					found := false
 					for _, siblingRel := range vc.Relations {
-						if siblingRel.Resource == currentRel.Key.Source {
+						if siblingRel.Name() == currentRel.Key.Source {
 							found = true
 							break
 						}
@@ -70,6 +58,10 @@ func (c Configs) verifyFieldRelations() error {
 						return fmt.Errorf("version %d: relation '%s'->'%s' key source '%s' is not the root resource and not a sibling relation", v, rCfg.Resource, currentRel.Resource, currentRel.Key.Source)
 					}
 				}
+
+				if err := c.verifyChildRelations(v, rCfg.Resource, currentRel); err != nil {
+					return err
+				}
 			}
 
 			// Verify no cycles in relation key dependencies for this version
@@ -82,14 +74,59 @@ func (c Configs) verifyFieldRelations() error {
 	return nil
 }
 
+// verifyRelationTarget verifies that the related resource exists and has the
+// fields defined in the relation.
+func (c Configs) verifyRelationTarget(v int, owner string, rel RelationConfig) error {
+	relRCfg := c.Get(rel.Resource)
+	if relRCfg == nil {
+		return fmt.Errorf("version %d: relation '%s'->'%s' is specified but resource '%s' does not exist", v, owner, rel.Resource, rel.Resource)
+	}
+
+	// Collect all field names across all versions of the target resource.
+	allTargetFields := make(map[string]bool)
+	for _, tvc := range relRCfg.Versions {
+		for _, f := range tvc.Fields {
+			allTargetFields[f.Name] = true
+		}
+	}
+
+	for _, f := range rel.Fields {
+		// Fields with a source are read from the related resource's data as
+		// is and do not need to be defined on it.
+		if f.Source == "" && !allTargetFields[f.Name] {
+			return fmt.Errorf("version %d: relation '%s'->'%s' specifies field '%s' which does not exist on '%s'", v, owner, rel.Resource, f.Name, rel.Resource)
+		}
+	}
+
+	return nil
+}
+
+// verifyChildRelations verifies the relations of a relation, recursively.
+// Their keys must be taken from the items of the parent relation.
+func (c Configs) verifyChildRelations(v int, path string, parent RelationConfig) error {
+	path += "." + parent.Name()
+	for _, child := range parent.Relations {
+		if err := c.verifyRelationTarget(v, path, child); err != nil {
+			return err
+		}
+		if child.Reverse == nil && child.Key.Source != parent.Name() {
+			return fmt.Errorf("version %d: relation '%s'->'%s' key source '%s' is not the parent relation '%s'", v, path, child.Name(), child.Key.Source, parent.Name())
+		}
+		if err := c.verifyChildRelations(v, path, child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // verifyNoCyclesVersion checks that the relation key dependencies within a single
 // version config form a DAG (no cycles).
 func verifyNoCyclesVersion(resourceName string, vc *VersionConfig) error {
 	// Build adjacency: relation resource name -> list of deps (key sources that are sibling relations)
 	deps := make(map[string]string) // relation -> dependency (its key source, if it's a sibling)
 	for _, rel := range vc.Relations {
-		if rel.Key.Source != resourceName {
-			deps[rel.Resource] = rel.Key.Source
+		if rel.Reverse == nil && rel.Key.Source != resourceName {
+			deps[rel.Name()] = rel.Key.Source
 		}
 	}
 
@@ -119,7 +156,7 @@ func verifyNoCyclesVersion(resourceName string, vc *VersionConfig) error {
 	}
 
 	for _, rel := range vc.Relations {
-		if err := visit(rel.Resource); err != nil {
+		if err := visit(rel.Name()); err != nil {
 			return err
 		}
 	}
@@ -147,9 +184,78 @@ func (c Config) Validate() error {
 		}
 	}
 
+	if err := c.Query.Validate(); err != nil {
+		return fmt.Errorf("query: %w", err)
+	}
+
+	if err := c.Coercion.Validate(); err != nil {
+		return fmt.Errorf("coercion: %w", err)
+	}
+
+	if c.Concurrency < 0 {
+		return fmt.Errorf("concurrency must not be negative")
+	}
+
+	return nil
+}
+
+func (c CoercionConfig) Validate() error {
+	switch c.Policy {
+	case "", CoercionFail, CoercionDrop, CoercionNull:
+	default:
+		return fmt.Errorf("policy must be %q, %q or %q", CoercionFail, CoercionDrop, CoercionNull)
+	}
 	return nil
 }
 
+func (q QueryModeConfig) Validate() error {
+	switch q.Type {
+	case "", MultiMatchBestFields, MultiMatchMostFields, MultiMatchCrossFields, MultiMatchPhrasePrefix:
+	default:
+		return fmt.Errorf("type must be one of %q, %q, %q or %q", MultiMatchBestFields, MultiMatchMostFields, MultiMatchCrossFields, MultiMatchPhrasePrefix)
+	}
+
+	if q.Operator != "" && q.Operator != "or" && q.Operator != "and" {
+		return fmt.Errorf("operator must be \"or\" or \"and\"")
+	}
+
+	if q.Fuzziness != "" {
+		if q.Type == MultiMatchCrossFields || q.Type == MultiMatchPhrasePrefix {
+			return fmt.Errorf("fuzziness is not supported with type %q", q.Type)
+		}
+		if !validFuzziness(q.Fuzziness) {
+			return fmt.Errorf("fuzziness must be \"AUTO\", \"AUTO:low,high\" or an edit distance of 0, 1 or 2")
+		}
+	}
+
+	return nil
+}
+
+func validFuzziness(f string) bool {
+	switch f {
+	case "AUTO", "0", "1", "2":
+		return true
+	}
+
+	bounds, ok := strings.CutPrefix(f, "AUTO:")
+	if !ok {
+		return false
+	}
+	low, high, ok := strings.Cut(bounds, ",")
+	if !ok {
+		return false
+	}
+	l, err := strconv.Atoi(low)
+	if err != nil {
+		return false
+	}
+	h, err := strconv.Atoi(high)
+	if err != nil {
+		return false
+	}
+	return l >= 0 && h >= l
+}
+
 func (vc VersionConfig) Validate(resourceName string, version int) error {
 	for i, f := range vc.Fields {
 		if err := f.Validate(); err != nil {
@@ -160,13 +266,41 @@ func (vc VersionConfig) Validate(resourceName string, version int) error {
 		}
 	}
 
+	seen := make(map[string]bool, len(vc.Relations))
 	for i, r := range vc.Relations {
 		if err := r.Validate(); err != nil {
-			if r.Resource != "" {
-				return fmt.Errorf("version %d: relation %q: %w", version, r.Resource, err)
+			if r.Name() != "" {
+				return fmt.Errorf("version %d: relation %q: %w", version, r.Name(), err)
 			}
 			return fmt.Errorf("version %d: relation %d: %w", version, i, err)
 		}
+		if seen[r.Name()] {
+			return fmt.Errorf("version %d: relation %q defined more than once, use \"as\" to name it differently", version, r.Name())
+		}
+		seen[r.Name()] = true
+		switch {
+		case r.Name() == "id" || r.Name() == "fields":
+			return fmt.Errorf("version %d: relation name %q is reserved", version, r.Name())
+		case r.As != "" && r.As == resourceName:
+			return fmt.Errorf("version %d: relation %q has the same name as the resource", version, r.As)
+		}
+	}
+
+	for _, e := range vc.EmbeddedRelations() {
+		r := e.Relation
+		nested := e.Path != r.Name()
+		if nested && r.Reverse != nil && r.Reverse.Source == ReverseSourceStore {
+			return fmt.Errorf("version %d: relation %q: reverse source %q is only supported on relations of the root", version, e.Path, ReverseSourceStore)
+		}
+	}
+
+	for _, f := range vc.DocumentFields() {
+		if err := vc.Analysis.verifyField(*f.Field); err != nil {
+			return fmt.Errorf("version %d: field %q: %w", version, f.Path, err)
+		}
+		if f.Field.Suggest && f.NestedPath != "" {
+			return fmt.Errorf("version %d: field %q: suggest is not supported on nested fields and cardinality \"many\" relations", version, f.Path)
+		}
 	}
 
 	return nil
@@ -176,6 +310,163 @@ func (c FieldConfig) Validate() error {
 	if c.Name == "" {
 		return fmt.Errorf("name required")
 	}
+	if c.Query.Boost < 0 {
+		return fmt.Errorf("query: boost cannot be negative")
+	}
+	if c.Source != "" {
+		if c.Computed != "" {
+			return fmt.Errorf("source cannot be combined with computed")
+		}
+		if _, err := ParseFieldPath(c.Source); err != nil {
+			return fmt.Errorf("source: %w", err)
+		}
+	}
+	if c.Computed != "" {
+		e, err := c.ComputedExpr()
+		if err != nil {
+			return fmt.Errorf("computed: %w", err)
+		}
+		if c.Type == "" && e.Type() == "" {
+			return fmt.Errorf("computed: type required, the type of %q cannot be derived", c.Computed)
+		}
+	}
+	if err := verifyAnalysisOptions(c.ESType(), c.Analyzer, c.SearchAnalyzer, c.Normalizer); err != nil {
+		return err
+	}
+
+	if c.IsObject() {
+		if err := c.validateObjectFields(); err != nil {
+			return err
+		}
+	} else if len(c.Fields) > 0 {
+		return fmt.Errorf("fields are only supported on object and nested fields")
+	}
+
+	if len(c.Formats) > 0 && c.ESType() != "date" && c.ESType() != "date_nanos" {
+		return fmt.Errorf("formats are only supported on date fields")
+	}
+	if slices.Contains(c.Formats, "") {
+		return fmt.Errorf("formats cannot contain an empty format")
+	}
+
+	if c.Suggest && c.ESType() != "text" && c.ESType() != "keyword" {
+		return fmt.Errorf("suggest is only supported on text and keyword fields")
+	}
+
+	seen := make(map[string]bool, len(c.SubFields))
+	for i, sf := range c.SubFields {
+		if sf.Name == "" {
+			return fmt.Errorf("sub-field %d: name required", i)
+		}
+		if c.Suggest && sf.Name == SuggestSubField {
+			return fmt.Errorf("sub-field %q is reserved for suggest", sf.Name)
+		}
+		if seen[sf.Name] {
+			return fmt.Errorf("sub-field %q defined more than once", sf.Name)
+		}
+		seen[sf.Name] = true
+
+		if err := verifyAnalysisOptions(sf.ESType(), sf.Analyzer, sf.SearchAnalyzer, sf.Normalizer); err != nil {
+			return fmt.Errorf("sub-field %q: %w", sf.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// validateObjectFields validates the fields of an object or nested field.
+func (c FieldConfig) validateObjectFields() error {
+	switch {
+	case len(c.Fields) == 0:
+		return fmt.Errorf("at least one field required on %s fields", c.Type)
+	case len(c.SubFields) > 0:
+		return fmt.Errorf("sub-fields are not supported on %s fields", c.Type)
+	case c.Computed != "":
+		return fmt.Errorf("computed is not supported on %s fields", c.Type)
+	}
+
+	seen := make(map[string]bool, len(c.Fields))
+	for i, f := range c.Fields {
+		if err := f.Validate(); err != nil {
+			if f.Name != "" {
+				return fmt.Errorf("field %q: %w", f.Name, err)
+			}
+			return fmt.Errorf("field %d: %w", i, err)
+		}
+		if f.Computed != "" {
+			return fmt.Errorf("field %q: computed fields are not supported within %s fields", f.Name, c.Type)
+		}
+		if seen[f.Name] {
+			return fmt.Errorf("field %q defined more than once", f.Name)
+		}
+		seen[f.Name] = true
+	}
+
+	return nil
+}
+
+// verifyAnalysisOptions checks that analyzers are only set on analyzed field
+// types and normalizers only on keyword fields.
+func verifyAnalysisOptions(esType, analyzer, searchAnalyzer, normalizer string) error {
+	analyzed := esType == "text" || esType == "match_only_text" || esType == "search_as_you_type" || esType == "completion"
+
+	if (analyzer != "" || searchAnalyzer != "") && !analyzed {
+		return fmt.Errorf("analyzer is not supported on %s fields", esType)
+	}
+	if searchAnalyzer != "" && analyzer == "" {
+		return fmt.Errorf("searchAnalyzer requires analyzer")
+	}
+	if normalizer != "" && esType != "keyword" {
+		return fmt.Errorf("normalizer is not supported on %s fields", esType)
+	}
+
+	return nil
+}
+
+// builtinAnalyzers are the analyzers ES provides without configuration.
+var builtinAnalyzers = map[string]bool{
+	"standard": true, "simple": true, "whitespace": true, "stop": true, "keyword": true,
+	"pattern": true, "fingerprint": true,
+	"arabic": true, "armenian": true, "basque": true, "bengali": true, "brazilian": true,
+	"bulgarian": true, "catalan": true, "cjk": true, "czech": true, "danish": true,
+	"dutch": true, "english": true, "estonian": true, "finnish": true, "french": true,
+	"galician": true, "german": true, "greek": true, "hindi": true, "hungarian": true,
+	"indonesian": true, "irish": true, "italian": true, "latvian": true, "lithuanian": true,
+	"norwegian": true, "persian": true, "portuguese": true, "romanian": true, "russian": true,
+	"serbian": true, "sorani": true, "spanish": true, "swedish": true, "turkish": true,
+	"thai": true,
+}
+
+// verifyField checks that the analyzers and normalizers used by a field and
+// its sub-fields are either built in or defined in the analysis config.
+func (a AnalysisConfig) verifyField(f FieldConfig) error {
+	if err := a.verifyRefs(f.Analyzer, f.SearchAnalyzer, f.Normalizer); err != nil {
+		return err
+	}
+	for _, sf := range f.SubFields {
+		if err := a.verifyRefs(sf.Analyzer, sf.SearchAnalyzer, sf.Normalizer); err != nil {
+			return fmt.Errorf("sub-field %q: %w", sf.Name, err)
+		}
+	}
+	return nil
+}
+
+func (a AnalysisConfig) verifyRefs(analyzer, searchAnalyzer, normalizer string) error {
+	for _, name := range []string{analyzer, searchAnalyzer} {
+		if name == "" || builtinAnalyzers[name] {
+			continue
+		}
+		if _, ok := a.Analyzers[name]; !ok {
+			return fmt.Errorf("analyzer %q is neither built in nor defined in analysis.analyzers", name)
+		}
+	}
+
+	if normalizer != "" && normalizer != "lowercase" {
+		if _, ok := a.Normalizers[normalizer]; !ok {
+			return fmt.Errorf("normalizer %q is neither built in nor defined in analysis.normalizers", normalizer)
+		}
+	}
+
 	return nil
 }
 
@@ -184,7 +475,14 @@ func (c RelationConfig) Validate() error {
 		return fmt.Errorf("resource required")
 	}
 
-	if err := c.Key.Validate(); err != nil {
+	if c.Reverse != nil {
+		if !c.Key.IsZero() {
+			return fmt.Errorf("key cannot be combined with reverse")
+		}
+		if err := c.Reverse.Validate(); err != nil {
+			return fmt.Errorf("reverse: %w", err)
+		}
+	} else if err := c.Key.Validate(); err != nil {
 		return fmt.Errorf("key: %w", err)
 	}
 
@@ -192,6 +490,20 @@ func (c RelationConfig) Validate() error {
 		return fmt.Errorf("cardinality must be \"one\" or \"many\"")
 	}
 
+	if c.Where != "" {
+		if _, err := expr.Parse(c.Where); err != nil {
+			return fmt.Errorf("where: %w", err)
+		}
+	}
+	for i, o := range c.OrderBy {
+		if err := o.Validate(); err != nil {
+			return fmt.Errorf("orderBy %d: %w", i, err)
+		}
+	}
+	if c.Limit < 0 {
+		return fmt.Errorf("limit cannot be negative")
+	}
+
 	// TODO: Default to "Use all fields" if none specified?
 	if len(c.Fields) == 0 {
 		return fmt.Errorf("at least one field required")
@@ -204,17 +516,90 @@ func (c RelationConfig) Validate() error {
 			}
 			return fmt.Errorf("field %d: %w", i, err)
 		}
+		if f.Computed != "" {
+			return fmt.Errorf("field %q: computed fields are only supported on the root resource", f.Name)
+		}
+		// Fields of "many" relations are nested and cannot be completed by a
+		// query on the root document.
+		if f.Suggest && c.IsMany() {
+			return fmt.Errorf("field %q: suggest is not supported on cardinality \"many\" relations", f.Name)
+		}
+	}
+
+	seen := make(map[string]bool, len(c.Relations))
+	for i, child := range c.Relations {
+		if err := child.Validate(); err != nil {
+			if child.Name() != "" {
+				return fmt.Errorf("relation %q: %w", child.Name(), err)
+			}
+			return fmt.Errorf("relation %d: %w", i, err)
+		}
+		if seen[child.Name()] {
+			return fmt.Errorf("relation %q defined more than once, use \"as\" to name it differently", child.Name())
+		}
+		seen[child.Name()] = true
+		if child.Name() == "id" || slices.ContainsFunc(c.Fields, func(f FieldConfig) bool { return f.Name == child.Name() }) {
+			return fmt.Errorf("relation %q has the same name as a field", child.Name())
+		}
 	}
 
 	return nil
 }
 
+func (o OrderByConfig) Validate() error {
+	if o.Field == "" {
+		return fmt.Errorf("field required")
+	}
+	if _, err := ParseFieldPath(o.Field); err != nil {
+		return fmt.Errorf("field: %w", err)
+	}
+	if o.Order != "" && o.Order != OrderAsc && o.Order != OrderDesc {
+		return fmt.Errorf("order must be %q or %q", OrderAsc, OrderDesc)
+	}
+	return nil
+}
+
+func (r ReverseConfig) Validate() error {
+	if r.Field == "" {
+		return fmt.Errorf("field required")
+	}
+	if r.Source != "" && r.Source != ReverseSourceProvider && r.Source != ReverseSourceStore {
+		return fmt.Errorf("source must be %q or %q", ReverseSourceProvider, ReverseSourceStore)
+	}
+	return nil
+}
+
 func (k KeyConfig) Validate() error {
 	if k.Source == "" {
 		return fmt.Errorf("source required")
 	}
-	if k.Field == "" {
+	if k.Field != "" && len(k.Fields) > 0 {
+		return fmt.Errorf("field cannot be combined with fields")
+	}
+	if k.Field == "" && len(k.Fields) == 0 {
 		return fmt.Errorf("field required")
 	}
+
+	seen := make(map[string]bool, len(k.Fields))
+	for i, f := range k.Fields {
+		if f == "" {
+			return fmt.Errorf("fields: field %d: name required", i)
+		}
+		if seen[f] {
+			return fmt.Errorf("fields: field %q listed more than once", f)
+		}
+		seen[f] = true
+	}
+
+	switch k.Array {
+	case "", KeyArrayEach:
+	case KeyArrayBatch:
+		if len(k.Fields) > 1 {
+			return fmt.Errorf("array %q is not supported with a composite key", KeyArrayBatch)
+		}
+	default:
+		return fmt.Errorf("array must be %q or %q", KeyArrayEach, KeyArrayBatch)
+	}
+
 	return nil
 }
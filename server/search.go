@@ -26,14 +26,52 @@ func NewSearcher(idx *core.Indexer) *SearcherServer {
 func (s *SearcherServer) Search(ctx context.Context, req *search.SearchRequest) (*search.SearchResponse, error) {
 	resp, err := s.idx.Search(ctx, req)
 	if err != nil {
-		if errors.Is(err, core.ErrUnknownResource) {
-			return nil, status.Error(codes.FailedPrecondition, core.ErrUnknownResource.Error())
-		}
-		return nil, err
+		return nil, searchError(err)
 	}
 	return resp, err
 }
 
+func (s *SearcherServer) Export(req *search.ExportRequest, stream search.SearchService_ExportServer) error {
+	err := s.idx.Export(stream.Context(), req, func(hits []*search.SearchHit) error {
+		return stream.Send(&search.ExportResponse{Hits: hits})
+	})
+	if err != nil {
+		return searchError(err)
+	}
+	return nil
+}
+
+func (s *SearcherServer) Suggest(ctx context.Context, req *search.SuggestRequest) (*search.SuggestResponse, error) {
+	resp, err := s.idx.Suggest(ctx, req)
+	if err != nil {
+		return nil, searchError(err)
+	}
+	return resp, nil
+}
+
+func (s *SearcherServer) MultiSearch(ctx context.Context, req *search.MultiSearchRequest) (*search.MultiSearchResponse, error) {
+	resp, err := s.idx.MultiSearch(ctx, req)
+	if err != nil {
+		return nil, searchError(err)
+	}
+	return resp, nil
+}
+
+// searchError maps errors returned by the indexer to gRPC status errors.
+func searchError(err error) error {
+	if errors.Is(err, core.ErrUnknownResource) {
+		return status.Error(codes.FailedPrecondition, core.ErrUnknownResource.Error())
+	}
+	var invalidArgsErr *core.InvalidArgumentError
+	if errors.As(err, &invalidArgsErr) {
+		return status.Error(codes.InvalidArgument, invalidArgsErr.Msg)
+	}
+	if errors.Is(err, context.Canceled) {
+		return status.Error(codes.Canceled, err.Error())
+	}
+	return err
+}
+
 func (s *SearcherServer) GetCapabilities(ctx context.Context, req *search.GetCapabilitiesRequest) (*search.GetCapabilitiesResponse, error) {
 	return s.idx.GetCapabilities(), nil
 }
@@ -3,16 +3,28 @@ package source
 import (
 	"context"
 	"fmt"
+	"sync/atomic"
 
 	pb "github.com/theleeeo/indexer/gen/provider/v1"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
 )
 
 // GRPCProvider implements Provider by calling a remote gRPC ProviderService.
+// It also implements BatchProvider and ResourceBatchProvider, falling back
+// when the plugin does not implement the batch RPCs.
 type GRPCProvider struct {
 	conn   *grpc.ClientConn
 	client pb.ProviderServiceClient
+
+	// noBatch and noResourceBatch are set once the plugin reports
+	// FetchRelatedBatch or FetchResources as unimplemented, so that it is
+	// not asked again.
+	noBatch         atomic.Bool
+	noResourceBatch atomic.Bool
 }
 
 // NewGRPCProvider dials the given address and returns a Provider backed by the
@@ -47,27 +59,140 @@ func (p *GRPCProvider) FetchResource(ctx context.Context, params FetchResourcePa
 	return FetchResourceResult{Data: resp.Data.AsMap()}, nil
 }
 
+// FetchResources implements ResourceBatchProvider. It returns ErrBatchUnsupported if
+// the plugin does not implement the RPC.
+func (p *GRPCProvider) FetchResources(ctx context.Context, params FetchResourcesParams) (FetchResourcesResult, error) {
+	if p.noResourceBatch.Load() {
+		return FetchResourcesResult{}, ErrBatchUnsupported
+	}
+
+	resp, err := p.client.FetchResources(ctx, &pb.FetchResourcesRequest{
+		ResourceType: params.ResourceType,
+		ResourceIds:  params.ResourceIDs,
+		Metadata:     params.Metadata,
+	})
+	if status.Code(err) == codes.Unimplemented {
+		p.noResourceBatch.Store(true)
+		return FetchResourcesResult{}, ErrBatchUnsupported
+	}
+	if err != nil {
+		return FetchResourcesResult{}, err
+	}
+
+	data := make(map[string]map[string]any, len(resp.Resources))
+	for _, r := range resp.Resources {
+		if r.Data != nil {
+			data[r.ResourceId] = r.Data.AsMap()
+		}
+	}
+	return FetchResourcesResult{Data: data}, nil
+}
+
 func (p *GRPCProvider) FetchRelated(ctx context.Context, params FetchRelatedParams) (FetchRelatedResult, error) {
+	key, err := resourceKeyToPB(params.Key)
+	if err != nil {
+		return FetchRelatedResult{}, err
+	}
+
 	resp, err := p.client.FetchRelated(ctx, &pb.FetchRelatedRequest{
 		ResourceType: params.ResourceType,
-		Key: &pb.ResourceKey{
-			Field: params.Key.Field,
-			Value: params.Key.Value,
-		},
+		Key:          key,
 		RootResource: &pb.RootResource{
 			Type: params.RootResource.Type,
 			Id:   params.RootResource.Id,
 		},
 		Metadata: params.Metadata,
+		Hints:    relationHintsToPB(params.Hints),
 	})
 	if err != nil {
 		return FetchRelatedResult{}, err
 	}
-	result := make([]map[string]any, len(resp.Data))
-	for i, s := range resp.Data {
+	return FetchRelatedResult{Related: structsToMaps(resp.Data)}, nil
+}
+
+// FetchRelatedBatch implements BatchProvider. It returns ErrBatchUnsupported
+// if the plugin does not implement the RPC.
+func (p *GRPCProvider) FetchRelatedBatch(ctx context.Context, params FetchRelatedBatchParams) (FetchRelatedBatchResult, error) {
+	if p.noBatch.Load() {
+		return FetchRelatedBatchResult{}, ErrBatchUnsupported
+	}
+
+	lookups := make([]*pb.RelatedLookup, len(params.Lookups))
+	for i, l := range params.Lookups {
+		key, err := resourceKeyToPB(l.Key)
+		if err != nil {
+			return FetchRelatedBatchResult{}, fmt.Errorf("lookup %d: %w", i, err)
+		}
+		lookups[i] = &pb.RelatedLookup{
+			Key: key,
+			RootResource: &pb.RootResource{
+				Type: l.RootResource.Type,
+				Id:   l.RootResource.Id,
+			},
+		}
+	}
+
+	resp, err := p.client.FetchRelatedBatch(ctx, &pb.FetchRelatedBatchRequest{
+		ResourceType: params.ResourceType,
+		Lookups:      lookups,
+		Metadata:     params.Metadata,
+		Hints:        relationHintsToPB(params.Hints),
+	})
+	if status.Code(err) == codes.Unimplemented {
+		p.noBatch.Store(true)
+		return FetchRelatedBatchResult{}, ErrBatchUnsupported
+	}
+	if err != nil {
+		return FetchRelatedBatchResult{}, err
+	}
+	if len(resp.Results) != len(lookups) {
+		return FetchRelatedBatchResult{}, fmt.Errorf("got %d results for %d lookups", len(resp.Results), len(lookups))
+	}
+
+	related := make([][]map[string]any, len(resp.Results))
+	for i, r := range resp.Results {
+		related[i] = structsToMaps(r.GetData())
+	}
+	return FetchRelatedBatchResult{Related: related}, nil
+}
+
+func resourceKeyToPB(key ResourceKey) (*pb.ResourceKey, error) {
+	parts := make([]*pb.KeyPart, len(key.Parts))
+	for i, part := range key.Parts {
+		v, err := structpb.NewValue(part.Value)
+		if err != nil {
+			return nil, fmt.Errorf("key part %q: %w", part.Field, err)
+		}
+		parts[i] = &pb.KeyPart{Field: part.Field, Value: v}
+	}
+	return &pb.ResourceKey{
+		Field: key.Field,
+		Value: key.Value,
+		Parts: parts,
+	}, nil
+}
+
+func structsToMaps(structs []*structpb.Struct) []map[string]any {
+	result := make([]map[string]any, len(structs))
+	for i, s := range structs {
 		result[i] = s.AsMap()
 	}
-	return FetchRelatedResult{Related: result}, nil
+	return result
+}
+
+func relationHintsToPB(h RelationHints) *pb.RelationHints {
+	if h.Where == "" && len(h.OrderBy) == 0 && h.Limit == 0 {
+		return nil
+	}
+	orderBy := make([]*pb.OrderBy, len(h.OrderBy))
+	for i, o := range h.OrderBy {
+		orderBy[i] = &pb.OrderBy{Field: o.Field, Descending: o.Descending}
+	}
+	return &pb.RelationHints{
+		Where:   h.Where,
+		OrderBy: orderBy,
+		Limit:   int32(h.Limit),
+	}
 }
 
 // Close releases the underlying gRPC connection.
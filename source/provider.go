@@ -2,6 +2,8 @@ package source
 
 import (
 	"context"
+	"errors"
+	"strconv"
 )
 
 type Provider interface {
@@ -13,6 +15,28 @@ type Provider interface {
 	ListResources(ctx context.Context, params ListResourcesParams) (ListResourcesResult, error)
 }
 
+// BatchProvider is implemented by providers that can fetch the related
+// resources of many keys in one call. It is optional; without it, related
+// resources are fetched one key at a time with FetchRelated.
+type BatchProvider interface {
+	// FetchRelatedBatch fetches the related resources of every lookup.
+	FetchRelatedBatch(ctx context.Context, params FetchRelatedBatchParams) (FetchRelatedBatchResult, error)
+}
+
+// ResourceBatchProvider is implemented by providers that can fetch many
+// resources in one call. It is optional; without it, resources are fetched
+// one at a time with FetchResource.
+type ResourceBatchProvider interface {
+	// FetchResources fetches the resources of the given IDs. Resources that
+	// do not exist are left out of the result.
+	FetchResources(ctx context.Context, params FetchResourcesParams) (FetchResourcesResult, error)
+}
+
+// ErrBatchUnsupported is returned by BatchProvider and ResourceBatchProvider
+// when the provider does not support them after all, e.g. a remote plugin
+// without the RPC. The caller falls back to one call per resource or key.
+var ErrBatchUnsupported = errors.New("batched lookups not supported")
+
 type FetchResourceParams struct {
 	ResourceType string
 	ResourceID   string
@@ -23,10 +47,70 @@ type FetchResourceResult struct {
 	Data map[string]any
 }
 
-// ResourceKey holds a single extracted field name and its value.
+type FetchResourcesParams struct {
+	ResourceType string
+	ResourceIDs  []string
+	Metadata     map[string]string
+}
+
+type FetchResourcesResult struct {
+	// Data holds the data of each resource that exists, by ID.
+	Data map[string]map[string]any
+}
+
+// ResourceKey identifies related resources by one or more key parts.
+// Use NewResourceKey to create one.
 type ResourceKey struct {
+	// Field and Value hold the first part, with the value formatted as a
+	// string. Value is empty if the first part's value is not a scalar.
 	Field string
 	Value string
+
+	// Parts holds every part of the key with its typed value.
+	Parts []KeyPart
+}
+
+// KeyPart is a single field of a ResourceKey.
+type KeyPart struct {
+	Field string
+
+	// Value is a string, float64 or bool, or a []any of them for batched
+	// lookups of array-valued keys.
+	Value any
+}
+
+// NewResourceKey returns a key of the given parts with Field and Value set
+// from the first part.
+func NewResourceKey(parts ...KeyPart) ResourceKey {
+	key := ResourceKey{Parts: parts}
+	if len(parts) > 0 {
+		key.Field = parts[0].Field
+		key.Value, _ = FormatKeyValue(parts[0].Value)
+	}
+	return key
+}
+
+// FormatKeyValue formats a scalar key value as a string. It reports false
+// for nil, empty strings and values that are not scalars.
+func FormatKeyValue(v any) (string, bool) {
+	switch v := v.(type) {
+	case string:
+		return v, v != ""
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32), true
+	case int:
+		return strconv.Itoa(v), true
+	case int32:
+		return strconv.FormatInt(int64(v), 10), true
+	case int64:
+		return strconv.FormatInt(v, 10), true
+	case bool:
+		return strconv.FormatBool(v), true
+	default:
+		return "", false
+	}
 }
 
 type FetchRelatedParams struct {
@@ -34,11 +118,29 @@ type FetchRelatedParams struct {
 	RootResource RootResource
 	// The resource type to fetch.
 	ResourceType string
-	// Key is the extracted field-value pair used to identify the related resource.
+	// Key holds the extracted values used to identify the related resources.
 	Key ResourceKey
 	// Metadata contains arbitrary caller-provided context propagated from
 	// the indexing API.
 	Metadata map[string]string
+	// Hints describe which of the related resources are embedded. They are
+	// applied by the indexer after fetching and may be ignored.
+	Hints RelationHints
+}
+
+// RelationHints describe the selection of related resources configured on a
+// relation.
+type RelationHints struct {
+	// Where is an expression in the language of package expr.
+	Where   string
+	OrderBy []OrderBy
+	// Limit is the maximum number of resources embedded, 0 for no limit.
+	Limit int
+}
+
+type OrderBy struct {
+	Field      string
+	Descending bool
 }
 
 type RootResource struct {
@@ -51,6 +153,31 @@ type FetchRelatedResult struct {
 	Related []map[string]any
 }
 
+type FetchRelatedBatchParams struct {
+	// The resource type to fetch.
+	ResourceType string
+	// Lookups holds the keys to fetch related resources for.
+	Lookups []RelatedLookup
+	// Metadata contains arbitrary caller-provided context propagated from
+	// the indexing API.
+	Metadata map[string]string
+	// Hints describe which of the related resources are embedded, as in
+	// FetchRelatedParams.
+	Hints RelationHints
+}
+
+// RelatedLookup is a single key of a batched lookup, with the resource it
+// was extracted for.
+type RelatedLookup struct {
+	RootResource RootResource
+	Key          ResourceKey
+}
+
+type FetchRelatedBatchResult struct {
+	// Related holds the related resources of each lookup, by lookup index.
+	Related [][]map[string]any
+}
+
 type ListResourcesParams struct {
 	ResourceType string
 	PageToken    string
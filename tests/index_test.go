@@ -1,8 +1,13 @@
 package tests
 
 import (
+	"errors"
+	"fmt"
+
 	"github.com/theleeeo/indexer/core"
 	"github.com/theleeeo/indexer/gen/search/v1"
+	"github.com/theleeeo/indexer/model"
+	"github.com/theleeeo/indexer/resource"
 )
 
 // resourceTracked reports whether (resourceType, resourceID) is present in the
@@ -218,7 +223,7 @@ func (t *TestSuite) Test_Create_WithRelation() {
 		t.Require().NoError(err)
 		t.worker.Drain(t.T().Context())
 
-		resp, err := t.idx.Search(t.T().Context(), &search.SearchRequest{Resource: "a"})
+		resp, err := t.idx.Search(t.T().Context(), &search.SearchRequest{Resource: "a", IncludeSource: true})
 		t.Require().NoError(err)
 		t.Require().Len(resp.Hits, 1)
 		t.Require().Equal("1", resp.Hits[0].Id)
@@ -259,7 +264,7 @@ func (t *TestSuite) Test_Create_ParentRelation_Already_Exists() {
 	t.worker.Drain(t.T().Context())
 
 	t.Run("verify relation on parent", func() {
-		resp, err := t.idx.Search(t.T().Context(), &search.SearchRequest{Resource: "a"})
+		resp, err := t.idx.Search(t.T().Context(), &search.SearchRequest{Resource: "a", IncludeSource: true})
 		t.Require().NoError(err)
 		t.Require().Len(resp.Hits, 1)
 		t.Require().Equal("1", resp.Hits[0].Id)
@@ -304,7 +309,7 @@ func (t *TestSuite) Test_RelatedRelations_FullGraph() {
 	t.worker.Drain(t.T().Context())
 
 	t.Run("verify c has both a and b relations", func() {
-		resp, err := t.idx.Search(t.T().Context(), &search.SearchRequest{Resource: "c"})
+		resp, err := t.idx.Search(t.T().Context(), &search.SearchRequest{Resource: "c", IncludeSource: true})
 		t.Require().NoError(err)
 		t.Require().Len(resp.Hits, 1)
 		t.Require().Equal("1", resp.Hits[0].Id)
@@ -354,7 +359,7 @@ func (t *TestSuite) Test_ChildUpdate_Rebuilds_Parent() {
 	t.worker.Drain(t.T().Context())
 
 	t.Run("verify c sees updated a data", func() {
-		resp, err := t.idx.Search(t.T().Context(), &search.SearchRequest{Resource: "c"})
+		resp, err := t.idx.Search(t.T().Context(), &search.SearchRequest{Resource: "c", IncludeSource: true})
 		t.Require().NoError(err)
 		t.Require().Len(resp.Hits, 1)
 
@@ -413,6 +418,68 @@ func (t *TestSuite) Test_Rebuild_SpecificIDs() {
 	t.Require().Equal("2", resp.Hits[0].Id)
 }
 
+// Test_Rebuild_ManyIDs rebuilds more IDs than fit in one chunk, including
+// one that no longer exists at the source.
+func (t *TestSuite) Test_Rebuild_ManyIDs() {
+	t.setResourceConfig(DefaultResourceConfig)
+
+	var ids []string
+	for i := range 150 {
+		id := fmt.Sprint(i)
+		ids = append(ids, id)
+		t.fakeProvider.SetResource("a", id, map[string]any{"id": id, "field1": "bulk"})
+	}
+
+	err := t.idx.Rebuild(t.T().Context(), []core.ResourceSelector{
+		{ResourceType: "a", ResourceIDs: ids},
+	})
+	t.Require().NoError(err)
+	t.worker.Drain(t.T().Context())
+
+	resp, err := t.idx.Search(t.T().Context(), &search.SearchRequest{Resource: "a", Query: "bulk"})
+	t.Require().NoError(err)
+	t.Require().Equal(int64(150), resp.Total)
+
+	// A resource deleted at the source is removed from the index.
+	t.fakeProvider.DeleteResource("a", "120")
+	err = t.idx.Rebuild(t.T().Context(), []core.ResourceSelector{
+		{ResourceType: "a", ResourceIDs: ids},
+	})
+	t.Require().NoError(err)
+	t.worker.Drain(t.T().Context())
+
+	resp, err = t.idx.Search(t.T().Context(), &search.SearchRequest{Resource: "a", Query: "bulk"})
+	t.Require().NoError(err)
+	t.Require().Equal(int64(149), resp.Total)
+	t.Require().False(t.resourceTracked("a", "120"))
+}
+
+// Test_Rebuild_RejectedDocument rebuilds resources of which ES rejects one,
+// and checks that the others are still indexed.
+func (t *TestSuite) Test_Rebuild_RejectedDocument() {
+	t.setResourceConfig(DefaultResourceConfig)
+
+	t.fakeProvider.SetResource("a", "1", map[string]any{"id": "1", "field1": "bulk"})
+	// An object in a keyword field fails to parse.
+	t.fakeProvider.SetResource("a", "2", map[string]any{"id": "2", "field1": map[string]any{"nested": "bulk"}})
+	t.fakeProvider.SetResource("a", "3", map[string]any{"id": "3", "field1": "bulk"})
+
+	err := t.idx.Rebuild(t.T().Context(), []core.ResourceSelector{
+		{ResourceType: "a", ResourceIDs: []string{"1", "2", "3"}},
+	})
+	t.Require().NoError(err)
+	t.worker.Drain(t.T().Context())
+
+	resp, err := t.idx.Search(t.T().Context(), &search.SearchRequest{Resource: "a", Query: "bulk"})
+	t.Require().NoError(err)
+	t.Require().Equal(int64(2), resp.Total)
+	var ids []string
+	for _, hit := range resp.Hits {
+		ids = append(ids, hit.Id)
+	}
+	t.Require().ElementsMatch([]string{"1", "3"}, ids)
+}
+
 // Test_Rebuild_All triggers a full rebuild of all resources of a type
 // using the "rebuild all" path (empty resource IDs).
 func (t *TestSuite) Test_Rebuild_All() {
@@ -502,7 +569,7 @@ func (t *TestSuite) Test_Rebuild_WithRelations() {
 	t.worker.Drain(t.T().Context())
 
 	// Verify the relation is populated in the search result.
-	resp, err := t.idx.Search(t.T().Context(), &search.SearchRequest{Resource: "a"})
+	resp, err := t.idx.Search(t.T().Context(), &search.SearchRequest{Resource: "a", IncludeSource: true})
 	t.Require().NoError(err)
 	t.Require().Len(resp.Hits, 1)
 
@@ -532,7 +599,7 @@ func (t *TestSuite) Test_Rebuild_All_WithRelations() {
 	t.worker.Drain(t.T().Context())
 
 	// Verify.
-	resp, err := t.idx.Search(t.T().Context(), &search.SearchRequest{Resource: "a"})
+	resp, err := t.idx.Search(t.T().Context(), &search.SearchRequest{Resource: "a", IncludeSource: true})
 	t.Require().NoError(err)
 	t.Require().Len(resp.Hits, 1)
 
@@ -541,6 +608,54 @@ func (t *TestSuite) Test_Rebuild_All_WithRelations() {
 	t.Require().Equal("b1", bRels[0].GetStructValue().Fields["id"].GetStringValue())
 }
 
+// Test_Rebuild_All_RejectedInLaterVersion rebuilds a resource that passes
+// coercion in one version and fails it in the next, and checks that no
+// relations are recorded for it.
+func (t *TestSuite) Test_Rebuild_All_RejectedInLaterVersion() {
+	relations := []resource.RelationConfig{{
+		Resource: "b",
+		Key:      resource.KeyConfig{Source: "a", Field: "id"},
+		Fields:   []resource.FieldConfig{{Name: "field1"}},
+	}}
+	cfg := resource.Configs{
+		{
+			Resource: "a",
+			Coercion: resource.CoercionConfig{Policy: resource.CoercionFail},
+			Versions: []resource.VersionConfig{
+				{Version: 1, Fields: []resource.FieldConfig{{Name: "field1"}}, Relations: relations},
+				{Version: 2, Fields: []resource.FieldConfig{{Name: "field1", Type: "integer"}}, Relations: relations},
+			},
+		},
+		{
+			Resource: "b",
+			Versions: []resource.VersionConfig{{Version: 1, Fields: []resource.FieldConfig{{Name: "field1"}}}},
+		},
+	}
+	for _, c := range cfg {
+		c.ApplyDefaults()
+	}
+	t.setResourceConfig(cfg)
+
+	t.fakeProvider.SetResource("a", "1", map[string]any{"id": "1", "field1": "not a number"})
+	t.fakeProvider.SetResource("a", "2", map[string]any{"id": "2", "field1": "2"})
+	for _, id := range []string{"1", "2"} {
+		t.fakeProvider.SetRelated("b", []string{id}, []map[string]any{{"id": "b1", "field1": "bval"}})
+	}
+	t.fakeProvider.SetResource("b", "b1", map[string]any{"id": "b1", "field1": "bval"})
+
+	err := t.idx.Rebuild(t.T().Context(), []core.ResourceSelector{{ResourceType: "a"}})
+	t.Require().NoError(err)
+	t.worker.Drain(t.T().Context())
+
+	rejected, err := t.st.GetChildResources(t.T().Context(), model.Resource{Type: "a", Id: "1"})
+	t.Require().NoError(err)
+	t.Require().Empty(rejected)
+
+	indexed, err := t.st.GetChildResources(t.T().Context(), model.Resource{Type: "a", Id: "2"})
+	t.Require().NoError(err)
+	t.Require().Equal([]model.Resource{{Type: "b", Id: "b1"}}, indexed)
+}
+
 // Test_Rebuild_EmptySelectors verifies that passing no selectors returns an error.
 func (t *TestSuite) Test_Rebuild_EmptySelectors() {
 	t.setResourceConfig(DefaultResourceConfig)
@@ -672,3 +787,394 @@ func (t *TestSuite) Test_VersionControl() {
 		t.worker.Drain(t.T().Context())
 	})
 }
+
+func (t *TestSuite) Test_Export() {
+	t.setResourceConfig(DefaultResourceConfig)
+
+	for _, id := range []string{"1", "2", "3"} {
+		t.fakeProvider.SetResource("a", id, map[string]any{
+			"id": id, "field1": "value" + id, "field2": "other",
+		})
+		err := t.idx.RegisterChange(t.T().Context(), core.Notification{
+			ResourceType: "a", ResourceID: id, Kind: core.ChangeCreated,
+		})
+		t.Require().NoError(err)
+	}
+	t.worker.Drain(t.T().Context())
+
+	t.Run("streams all hits in batches", func() {
+		batches := 0
+		var ids []string
+		err := t.idx.Export(t.T().Context(), &search.ExportRequest{
+			Resource:  "a",
+			BatchSize: 2,
+			Fields:    []string{"fields.field1"},
+		}, func(hits []*search.SearchHit) error {
+			batches++
+			for _, h := range hits {
+				ids = append(ids, h.Id)
+				fields := h.Source.Fields["fields"].GetStructValue()
+				t.Require().NotNil(fields)
+				t.Require().Contains(fields.Fields, "field1")
+				t.Require().NotContains(fields.Fields, "field2")
+			}
+			return nil
+		})
+		t.Require().NoError(err)
+		t.Require().Equal(2, batches)
+		t.Require().ElementsMatch([]string{"1", "2", "3"}, ids)
+	})
+
+	t.Run("stops when the consumer fails", func() {
+		stop := errors.New("stop")
+		calls := 0
+		err := t.idx.Export(t.T().Context(), &search.ExportRequest{
+			Resource:  "a",
+			BatchSize: 1,
+		}, func(hits []*search.SearchHit) error {
+			calls++
+			return stop
+		})
+		t.Require().ErrorIs(err, stop)
+		t.Require().Equal(1, calls)
+	})
+}
+
+func (t *TestSuite) Test_Search_Highlight() {
+	t.setResourceConfig(DefaultResourceConfig)
+
+	t.fakeProvider.SetResource("a", "1", map[string]any{
+		"id": "1", "field1": "needle", "field2": "other",
+	})
+	err := t.idx.RegisterChange(t.T().Context(), core.Notification{
+		ResourceType: "a", ResourceID: "1", Kind: core.ChangeCreated,
+	})
+	t.Require().NoError(err)
+	t.worker.Drain(t.T().Context())
+
+	resp, err := t.idx.Search(t.T().Context(), &search.SearchRequest{
+		Resource:  "a",
+		Query:     "needle",
+		Highlight: &search.Highlight{PreTag: "[", PostTag: "]"},
+	})
+	t.Require().NoError(err)
+	t.Require().Len(resp.Hits, 1)
+	t.Require().Contains(resp.Hits[0].Highlights, "fields.field1")
+	t.Require().Equal([]string{"[needle]"}, resp.Hits[0].Highlights["fields.field1"].Fragments)
+	t.Require().NotContains(resp.Hits[0].Highlights, "fields.field2")
+}
+
+func (t *TestSuite) Test_Suggest() {
+	cfg := resource.Configs{
+		{
+			Resource: "a",
+			Versions: []resource.VersionConfig{
+				{
+					Version: 1,
+					Fields: []resource.FieldConfig{
+						{Name: "field1", Type: "text", Suggest: true},
+						{Name: "field2"},
+					},
+				},
+			},
+		},
+	}
+	for _, c := range cfg {
+		c.ApplyDefaults()
+	}
+	t.setResourceConfig(cfg)
+
+	docs := map[string]map[string]any{
+		"1": {"id": "1", "field1": "red running shoe", "field2": "open"},
+		"2": {"id": "2", "field1": "red rain coat", "field2": "closed"},
+		"3": {"id": "3", "field1": "blue shoe", "field2": "open"},
+	}
+	for id, data := range docs {
+		t.fakeProvider.SetResource("a", id, data)
+		err := t.idx.RegisterChange(t.T().Context(), core.Notification{
+			ResourceType: "a", ResourceID: id, Kind: core.ChangeCreated,
+		})
+		t.Require().NoError(err)
+	}
+	t.worker.Drain(t.T().Context())
+
+	t.Run("completes prefix", func() {
+		resp, err := t.idx.Suggest(t.T().Context(), &search.SuggestRequest{
+			Resource: "a", Prefix: "red ru",
+		})
+		t.Require().NoError(err)
+		t.Require().NotEmpty(resp.Suggestions)
+		t.Require().Equal("1", resp.Suggestions[0].Id)
+		t.Require().Equal("red running shoe", resp.Suggestions[0].Text)
+		t.Require().Equal("fields.field1", resp.Suggestions[0].Field)
+	})
+
+	t.Run("restricted by filters", func() {
+		resp, err := t.idx.Suggest(t.T().Context(), &search.SuggestRequest{
+			Resource: "a",
+			Prefix:   "re",
+			Filters:  []*search.Filter{{Field: "fields.field2", Op: search.FilterOp_FILTER_OP_EQ, Value: "closed"}},
+		})
+		t.Require().NoError(err)
+		t.Require().Len(resp.Suggestions, 1)
+		t.Require().Equal("2", resp.Suggestions[0].Id)
+	})
+}
+
+func (t *TestSuite) Test_MultiSearch() {
+	t.setResourceConfig(DefaultResourceConfig)
+
+	t.fakeProvider.SetResource("a", "1", map[string]any{"id": "1", "field1": "needle"})
+	t.fakeProvider.SetResource("a", "2", map[string]any{"id": "2", "field1": "needle"})
+	t.fakeProvider.SetResource("b", "1", map[string]any{"id": "1", "field1": "needle"})
+	t.fakeProvider.SetResource("b", "2", map[string]any{"id": "2", "field1": "hay"})
+	for _, n := range []core.Notification{
+		{ResourceType: "a", ResourceID: "1", Kind: core.ChangeCreated},
+		{ResourceType: "a", ResourceID: "2", Kind: core.ChangeCreated},
+		{ResourceType: "b", ResourceID: "1", Kind: core.ChangeCreated},
+		{ResourceType: "b", ResourceID: "2", Kind: core.ChangeCreated},
+	} {
+		t.Require().NoError(t.idx.RegisterChange(t.T().Context(), n))
+	}
+	t.worker.Drain(t.T().Context())
+
+	t.Run("hits of all resources", func() {
+		resp, err := t.idx.MultiSearch(t.T().Context(), &search.MultiSearchRequest{
+			Query: "needle",
+			Resources: []*search.MultiSearchResource{
+				{Resource: "a"},
+				{Resource: "b", Weight: 10},
+			},
+		})
+		t.Require().NoError(err)
+		t.Require().Len(resp.Hits, 3)
+		t.Require().Equal(map[string]int64{"a": 2, "b": 1}, resp.Totals)
+
+		// The weight ranks the "b" hit first.
+		t.Require().Equal("b", resp.Hits[0].Resource)
+		t.Require().Equal("1", resp.Hits[0].Id)
+		t.Require().Equal("a", resp.Hits[1].Resource)
+		t.Require().Equal("a", resp.Hits[2].Resource)
+	})
+
+	t.Run("quota per resource", func() {
+		resp, err := t.idx.MultiSearch(t.T().Context(), &search.MultiSearchRequest{
+			Query: "needle",
+			Resources: []*search.MultiSearchResource{
+				{Resource: "a", Quota: 1},
+				{Resource: "b"},
+			},
+		})
+		t.Require().NoError(err)
+		t.Require().Len(resp.Hits, 2)
+		t.Require().Equal(int64(2), resp.Totals["a"])
+	})
+}
+
+func (t *TestSuite) Test_ReverseRelation_Store() {
+	cfg := resource.Configs{
+		{
+			Resource: "a",
+			Versions: []resource.VersionConfig{{
+				Version: 1,
+				Fields:  []resource.FieldConfig{{Name: "f1"}},
+				Relations: []resource.RelationConfig{{
+					Resource: "c",
+					Reverse:  &resource.ReverseConfig{Field: "a_id", Source: resource.ReverseSourceStore},
+					Fields:   []resource.FieldConfig{{Name: "f1"}},
+				}},
+			}},
+		},
+		{
+			Resource: "c",
+			Versions: []resource.VersionConfig{{
+				Version: 1,
+				Fields:  []resource.FieldConfig{{Name: "f1"}},
+			}},
+		},
+	}
+	for _, c := range cfg {
+		c.ApplyDefaults()
+	}
+	t.setResourceConfig(cfg)
+
+	t.fakeProvider.SetResource("a", "1", map[string]any{"id": "1", "f1": "a1"})
+	t.fakeProvider.SetResource("a", "2", map[string]any{"id": "2", "f1": "a2"})
+	t.fakeProvider.SetResource("c", "1", map[string]any{"id": "1", "f1": "c1", "a_id": "1"})
+	for _, n := range []core.Notification{
+		{ResourceType: "a", ResourceID: "1", Kind: core.ChangeCreated},
+		{ResourceType: "a", ResourceID: "2", Kind: core.ChangeCreated},
+		{ResourceType: "c", ResourceID: "1", Kind: core.ChangeCreated},
+	} {
+		t.Require().NoError(t.idx.RegisterChange(t.T().Context(), n))
+	}
+	t.worker.Drain(t.T().Context())
+
+	embedded := func(aID string) []string {
+		resp, err := t.idx.Search(t.T().Context(), &search.SearchRequest{
+			Resource:      "a",
+			IncludeSource: true,
+			Filters:       []*search.Filter{{Field: "id", Op: search.FilterOp_FILTER_OP_EQ, Value: aID}},
+		})
+		t.Require().NoError(err)
+		t.Require().Len(resp.Hits, 1)
+
+		var ids []string
+		for _, v := range resp.Hits[0].Source.Fields["c"].GetListValue().GetValues() {
+			ids = append(ids, v.GetStructValue().Fields["id"].GetStringValue())
+		}
+		return ids
+	}
+
+	t.Run("created child is embedded", func() {
+		t.Require().Equal([]string{"1"}, embedded("1"))
+		t.Require().Empty(embedded("2"))
+	})
+
+	t.fakeProvider.SetResource("c", "1", map[string]any{"id": "1", "f1": "c1", "a_id": "2"})
+	t.Require().NoError(t.idx.RegisterChange(t.T().Context(), core.Notification{
+		ResourceType: "c", ResourceID: "1", Kind: core.ChangeUpdated,
+	}))
+	t.worker.Drain(t.T().Context())
+
+	t.Run("moved child follows its reference", func() {
+		t.Require().Empty(embedded("1"))
+		t.Require().Equal([]string{"1"}, embedded("2"))
+	})
+}
+
+func (t *TestSuite) Test_ReverseRelation_Store_ChildUpdate() {
+	cfg := resource.Configs{
+		{
+			Resource: "a",
+			Versions: []resource.VersionConfig{{
+				Version: 1,
+				Fields:  []resource.FieldConfig{{Name: "f1"}},
+				Relations: []resource.RelationConfig{{
+					Resource: "c",
+					Reverse:  &resource.ReverseConfig{Field: "a_id", Source: resource.ReverseSourceStore},
+					Fields:   []resource.FieldConfig{{Name: "f1"}},
+				}},
+			}},
+		},
+		{
+			Resource: "c",
+			Versions: []resource.VersionConfig{{
+				Version: 1,
+				Fields:  []resource.FieldConfig{{Name: "f1"}},
+			}},
+		},
+	}
+	for _, c := range cfg {
+		c.ApplyDefaults()
+	}
+	t.setResourceConfig(cfg)
+
+	t.fakeProvider.SetResource("a", "1", map[string]any{"id": "1", "f1": "a1"})
+	t.fakeProvider.SetResource("c", "1", map[string]any{"id": "1", "f1": "c1", "a_id": "1"})
+	for _, n := range []core.Notification{
+		{ResourceType: "a", ResourceID: "1", Kind: core.ChangeCreated},
+		{ResourceType: "c", ResourceID: "1", Kind: core.ChangeCreated},
+	} {
+		t.Require().NoError(t.idx.RegisterChange(t.T().Context(), n))
+	}
+	t.worker.Drain(t.T().Context())
+
+	embedded := func() []string {
+		resp, err := t.idx.Search(t.T().Context(), &search.SearchRequest{
+			Resource:      "a",
+			IncludeSource: true,
+			Filters:       []*search.Filter{{Field: "id", Op: search.FilterOp_FILTER_OP_EQ, Value: "1"}},
+		})
+		t.Require().NoError(err)
+		t.Require().Len(resp.Hits, 1)
+
+		var values []string
+		for _, v := range resp.Hits[0].Source.Fields["c"].GetListValue().GetValues() {
+			values = append(values, v.GetStructValue().Fields["f1"].GetStringValue())
+		}
+		return values
+	}
+	update := func(f1 string) {
+		t.fakeProvider.SetResource("c", "1", map[string]any{"id": "1", "f1": f1, "a_id": "1"})
+		t.Require().NoError(t.idx.RegisterChange(t.T().Context(), core.Notification{
+			ResourceType: "c", ResourceID: "1", Kind: core.ChangeUpdated,
+		}))
+		t.worker.Drain(t.T().Context())
+	}
+
+	t.Run("updated child is embedded again", func() {
+		update("c1-updated")
+		t.Require().Equal([]string{"c1-updated"}, embedded())
+	})
+
+	t.Run("root is found through the child's own relations", func() {
+		// As if the root was not rebuilt since the child started referencing
+		// it, so it does not record the child yet.
+		t.Require().NoError(t.st.RemoveResource(t.T().Context(), model.Resource{Type: "a", Id: "1"}))
+
+		update("c1-updated-again")
+		t.Require().Equal([]string{"c1-updated-again"}, embedded())
+	})
+}
+
+func (t *TestSuite) Test_RelationsOfRelations_ChildUpdate_Rebuilds_Root() {
+	cfg := resource.Configs{
+		{
+			Resource: "a",
+			Versions: []resource.VersionConfig{{
+				Version: 1,
+				Fields:  []resource.FieldConfig{{Name: "f1"}},
+				Relations: []resource.RelationConfig{{
+					Resource: "b",
+					Key:      resource.KeyConfig{Source: "a", Field: "id"},
+					Fields:   []resource.FieldConfig{{Name: "f1"}},
+					Relations: []resource.RelationConfig{{
+						Resource:    "c",
+						Key:         resource.KeyConfig{Source: "b", Field: "c_id"},
+						Cardinality: "one",
+						Fields:      []resource.FieldConfig{{Name: "f1"}},
+					}},
+				}},
+			}},
+		},
+		{Resource: "b", Versions: []resource.VersionConfig{{Version: 1, Fields: []resource.FieldConfig{{Name: "f1"}}}}},
+		{Resource: "c", Versions: []resource.VersionConfig{{Version: 1, Fields: []resource.FieldConfig{{Name: "f1"}}}}},
+	}
+	for _, c := range cfg {
+		c.ApplyDefaults()
+	}
+	t.setResourceConfig(cfg)
+
+	t.fakeProvider.SetResource("a", "1", map[string]any{"id": "1", "f1": "a1"})
+	t.fakeProvider.SetRelated("b", []string{"1"}, []map[string]any{{"id": "1", "f1": "b1", "c_id": "1"}})
+	t.fakeProvider.SetResource("c", "1", map[string]any{"id": "1", "f1": "c1"})
+	t.fakeProvider.SetRelated("c", []string{"1"}, []map[string]any{{"id": "1", "f1": "c1"}})
+
+	t.Require().NoError(t.idx.RegisterChange(t.T().Context(), core.Notification{
+		ResourceType: "a", ResourceID: "1", Kind: core.ChangeCreated,
+	}))
+	t.worker.Drain(t.T().Context())
+
+	// Update the relation of the relation at the source.
+	t.fakeProvider.SetResource("c", "1", map[string]any{"id": "1", "f1": "c1_updated"})
+	t.fakeProvider.SetRelated("c", []string{"1"}, []map[string]any{{"id": "1", "f1": "c1_updated"}})
+	t.Require().NoError(t.idx.RegisterChange(t.T().Context(), core.Notification{
+		ResourceType: "c", ResourceID: "1", Kind: core.ChangeUpdated,
+	}))
+	t.worker.Drain(t.T().Context())
+
+	resp, err := t.idx.Search(t.T().Context(), &search.SearchRequest{
+		Resource:      "a",
+		IncludeSource: true,
+		Filters:       []*search.Filter{{Field: "b.c.f1", Op: search.FilterOp_FILTER_OP_EQ, Value: "c1_updated"}},
+	})
+	t.Require().NoError(err)
+	t.Require().Len(resp.Hits, 1)
+
+	bRels := resp.Hits[0].Source.Fields["b"].GetListValue().GetValues()
+	t.Require().Len(bRels, 1)
+	cRels := bRels[0].GetStructValue().Fields["c"].GetListValue().GetValues()
+	t.Require().Len(cRels, 1)
+	t.Require().Equal("c1_updated", cRels[0].GetStructValue().Fields["f1"].GetStringValue())
+}
@@ -351,7 +351,7 @@ func (t *TestSuite) SetupSuite() {
 	t.st = store.NewPostgresStore(dbpool)
 	t.fakeProvider = NewFakeProvider()
 
-	plans := dsl.BuildPlansFromConfig(t.fakeProvider, DefaultResourceConfig)
+	plans := dsl.BuildPlansFromConfig(t.fakeProvider, t.st, DefaultResourceConfig)
 
 	t.idx = core.New(core.Config{
 		Plans:     plans,
@@ -411,7 +411,7 @@ func (t *TestSuite) SetupTest() {
 // dynamically changing the resource configuration at runtime.
 // It also creates the versioned ES indices and read aliases.
 func (t *TestSuite) setResourceConfig(resources resource.Configs) {
-	plans := dsl.BuildPlansFromConfig(t.fakeProvider, resources)
+	plans := dsl.BuildPlansFromConfig(t.fakeProvider, t.st, resources)
 	t.idx.SetPlans(plans, resources)
 
 	// Create versioned indexes and aliases for each resource.